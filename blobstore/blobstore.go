@@ -0,0 +1,49 @@
+/*
+Package blobstore is Frameworks & Drivers.
+添付ファイルの中身をローカルのファイルシステムまたはS3互換のストレージに保存する
+*/
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/hiroyaonoe/todoapp-server/config"
+)
+
+// 保存先の種類
+const (
+	KindLocal = "local"
+	KindS3    = "s3"
+)
+
+// Store はキーごとにファイルの中身を保存する
+// 存在しないキーをGetした場合はfs.ErrNotExistをラップしたエラーを返し，Deleteした場合は成功とする
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New は環境変数に従ってkindの保存先にファイルを保存するStoreを返す
+func New(kind string) (Store, error) {
+	switch kind {
+	case KindLocal:
+		return NewLocal(config.BlobDir())
+	case KindS3:
+		if config.S3Endpoint() == "" || config.S3Bucket() == "" {
+			return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for s3 blob store")
+		}
+		endpoint, err := url.Parse(config.S3Endpoint())
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_ENDPOINT: %w", err)
+		}
+		accessKeyID, secretAccessKey := config.S3Credentials()
+		return NewS3(endpoint, config.S3Region(), config.S3Bucket(), accessKeyID, secretAccessKey), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store: %q", kind)
+	}
+}
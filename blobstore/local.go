@@ -0,0 +1,85 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Local はDirの下にキーをパスとしてファイルを保存する
+type Local struct {
+	Dir string
+}
+
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Local{Dir: dir}, nil
+}
+
+// Put は一時ファイルに書き込んでから名前を変えるので，書き込み途中のファイルをGetすることはない
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		return
+	}
+	if n != size {
+		return fmt.Errorf("blob %q: wrote %d bytes, want %d", key, n, size)
+	}
+	if err = tmp.Sync(); err != nil {
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// path はキーをDirの下のパスにする．Dirの外を指すキーはエラーにする
+func (l *Local) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(l.Dir, key), nil
+}
@@ -5,9 +5,16 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// defaultQueryTimeout はQUERY_TIMEOUTが設定されていない場合のタイムアウト
+const defaultQueryTimeout = 5 * time.Second
+
 // Port はRouting用のポートを返す
 func Port() string {
 	return os.Getenv("ROUTING_PORT")
@@ -34,3 +41,254 @@ func DSN(env string) string {
 	)
 	return dsn[env]
 }
+
+// TraceExporter はトレースの送信先(stdout, otlp)を返す．空の場合はトレースを送信しない
+func TraceExporter() string {
+	return os.Getenv("TRACE_EXPORTER")
+}
+
+// LogLevel はログの出力レベル(debug, info, warn, error)を返す
+func LogLevel() string {
+	return os.Getenv("LOG_LEVEL")
+}
+
+// QueryTimeout はデータベースへのクエリのタイムアウト(例: 5s, 500ms)を返す
+func QueryTimeout() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("QUERY_TIMEOUT"))
+	if err != nil {
+		return defaultQueryTimeout
+	}
+	return d
+}
+
+// RateLimit はIPアドレスおよびアカウントごとのリクエスト数の制限(1秒あたりの回数, バースト)を返す
+func RateLimit() (perSecond float64, burst int) {
+	return getFloat("RATE_LIMIT_PER_SECOND", 10), getInt("RATE_LIMIT_BURST", 20)
+}
+
+// LoginRateLimit はIPアドレスごと，emailごとのログイン試行回数の制限(1秒あたりの回数, バースト)を返す
+func LoginRateLimit() (perSecond float64, burst int) {
+	return getFloat("LOGIN_RATE_LIMIT_PER_SECOND", 0.2), getInt("LOGIN_RATE_LIMIT_BURST", 5)
+}
+
+// TrustedProxies はX-Forwarded-Forを信頼するプロキシのIPアドレスまたはCIDRを返す
+// 設定されていない場合はどのプロキシも信頼せず，接続元のIPアドレスを使う
+func TrustedProxies() []string {
+	return getList("TRUSTED_PROXIES", nil)
+}
+
+// CORSAllowedOrigins はクロスオリジンのリクエストを許可するオリジンを返す．*はすべてのオリジンを許可する
+// 設定されていない場合はクロスオリジンのリクエストを許可しない
+func CORSAllowedOrigins() []string {
+	return getList("CORS_ALLOWED_ORIGINS", nil)
+}
+
+// CORSAllowCredentials はクロスオリジンのリクエストでcookieの送信を許可するかを返す
+func CORSAllowCredentials() bool {
+	return getBool("CORS_ALLOW_CREDENTIALS", true)
+}
+
+// CORSAllowedMethods はクロスオリジンのリクエストで許可するメソッドを返す
+func CORSAllowedMethods() []string {
+	return getList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE"})
+}
+
+// CORSMaxAge はプリフライトリクエストの結果をキャッシュしてよい期間を返す
+func CORSMaxAge() time.Duration {
+	return getDuration("CORS_MAX_AGE", 10*time.Minute)
+}
+
+// HSTSMaxAge はStrict-Transport-Securityのmax-ageを返す．0の場合はヘッダーを付与しない
+func HSTSMaxAge() time.Duration {
+	return getDuration("HSTS_MAX_AGE", 365*24*time.Hour)
+}
+
+// FrameAncestors はこのAPIのレスポンスをフレームに埋め込んでよいオリジンを返す(CSPのframe-ancestors)
+func FrameAncestors() []string {
+	return getList("FRAME_ANCESTORS", []string{"'none'"})
+}
+
+// CookieSecure はcookieをHTTPSのみで送信させるかを返す
+func CookieSecure() bool {
+	return getBool("COOKIE_SECURE", false)
+}
+
+// CookieHTTPOnly はcookieをJavaScriptから読めないようにするかを返す
+func CookieHTTPOnly() bool {
+	return getBool("COOKIE_HTTP_ONLY", true)
+}
+
+// CookieSameSite はcookieのSameSite属性(lax, strict, none)を返す．設定されていないか不正な場合はlax
+func CookieSameSite() http.SameSite {
+	switch strings.ToLower(os.Getenv("COOKIE_SAME_SITE")) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// CookieDomain はcookieのDomain属性を返す．空の場合はリクエストしたホストのみに送信される
+func CookieDomain() string {
+	return os.Getenv("COOKIE_DOMAIN")
+}
+
+// ReminderInterval は期限のReminderと担当者の変更を通知するスケジューラーの実行間隔を返す．0の場合はスケジューラーを動かさない
+func ReminderInterval() time.Duration {
+	return getDuration("REMINDER_INTERVAL", time.Minute)
+}
+
+// ReminderBatchSize はスケジューラーが1回に送るReminder(担当者の変更)の件数の上限を返す
+func ReminderBatchSize() int {
+	return getInt("REMINDER_BATCH_SIZE", 100)
+}
+
+// Notifiers は通知の送り先(log, webhook, email)を返す
+func Notifiers() []string {
+	return getList("NOTIFIERS", []string{"log"})
+}
+
+// WebhookURL は通知をPOSTするwebhookのURLを返す
+func WebhookURL() string {
+	return os.Getenv("WEBHOOK_URL")
+}
+
+// WebhookTimeout はwebhookへのリクエストのタイムアウトを返す
+func WebhookTimeout() time.Duration {
+	return getDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+}
+
+// SMTPAddr は通知のメールを送るSMTPサーバーのアドレス(host:port)を返す
+func SMTPAddr() string {
+	return os.Getenv("SMTP_ADDR")
+}
+
+// SMTPFrom は通知のメールの送信元アドレスを返す
+func SMTPFrom() string {
+	return os.Getenv("SMTP_FROM")
+}
+
+// SMTPAuth はSMTPサーバーの認証に使うユーザー名とパスワードを返す．ユーザー名が空の場合は認証しない
+func SMTPAuth() (username, password string) {
+	return os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD")
+}
+
+// BlobStore は添付ファイルの保存先(local, s3)を返す
+func BlobStore() string {
+	return getString("BLOB_STORE", "local")
+}
+
+// BlobDir は保存先がlocalの場合に添付ファイルを保存するディレクトリを返す
+func BlobDir() string {
+	return getString("BLOB_DIR", "data/blobs")
+}
+
+// S3Endpoint は保存先がs3の場合のS3互換のストレージのURL(例: https://s3.ap-northeast-1.amazonaws.com)を返す
+func S3Endpoint() string {
+	return os.Getenv("S3_ENDPOINT")
+}
+
+// S3Region はS3互換のストレージのリージョンを返す
+func S3Region() string {
+	return getString("S3_REGION", "us-east-1")
+}
+
+// S3Bucket は添付ファイルを保存するバケットを返す
+func S3Bucket() string {
+	return os.Getenv("S3_BUCKET")
+}
+
+// S3Credentials はS3互換のストレージの認証に使うアクセスキーを返す
+func S3Credentials() (accessKeyID, secretAccessKey string) {
+	return os.Getenv("S3_ACCESS_KEY_ID"), os.Getenv("S3_SECRET_ACCESS_KEY")
+}
+
+// AttachmentMaxSize は添付ファイル1つの大きさの上限(バイト)を返す
+func AttachmentMaxSize() int64 {
+	return int64(getInt("ATTACHMENT_MAX_SIZE", 10<<20))
+}
+
+// AttachmentQuota はユーザーが添付できるファイルの合計の大きさの上限(バイト)を返す
+func AttachmentQuota() int64 {
+	return int64(getInt("ATTACHMENT_QUOTA", 100<<20))
+}
+
+// BlobCleanupInterval は削除した添付ファイルをストレージから削除するスケジューラーの実行間隔を返す．0の場合はスケジューラーを動かさない
+func BlobCleanupInterval() time.Duration {
+	return getDuration("BLOB_CLEANUP_INTERVAL", time.Minute)
+}
+
+// IdempotencyTTL はIdempotency-Keyを指定したリクエストのレスポンスを保存しておく期間を返す
+func IdempotencyTTL() time.Duration {
+	return getDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+}
+
+// IdempotencyLockTimeout はIdempotency-Keyを指定したリクエストを処理中として確保しておく期間を返す．過ぎると同じキーのリクエストが引き継ぐ
+func IdempotencyLockTimeout() time.Duration {
+	return getDuration("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute)
+}
+
+// IdempotencyCleanupInterval は期限の切れたIdempotency-Keyを削除するスケジューラーの実行間隔を返す．0の場合はスケジューラーを動かさない
+func IdempotencyCleanupInterval() time.Duration {
+	return getDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour)
+}
+
+// getString は環境変数を返す．設定されていない場合はdefを返す
+func getString(key string, def string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// getBool は環境変数を真偽値として返す．設定されていないか不正な場合はdefを返す
+func getBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getDuration は環境変数を時間として返す．設定されていないか不正な場合はdefを返す
+func getDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getList は環境変数をカンマ区切りのリストとして返す．設定されていない場合はdefを返す
+func getList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	list := strings.Split(v, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
+
+// getFloat は環境変数を数値として返す．設定されていないか不正な場合はdefを返す
+func getFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getInt は環境変数を整数として返す．設定されていないか不正な場合はdefを返す
+func getInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository の具体的な実装
+type ActivityRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewActivityRepository(db *DB, logger *slog.Logger) *ActivityRepository {
+	return &ActivityRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *ActivityRepository) Create(ctx context.Context, a *entity.Activity) (err error) {
+	ctx, span := startSpan(ctx, "ActivityRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	a.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "activities", func() error {
+		return db.Omit("Actor").Create(a).Error
+	})
+	return
+}
+
+func (repo *ActivityRepository) FindByEntity(ctx context.Context, entityType entity.ActivityEntity, id string, page entity.Page) (activities []*entity.Activity, total int64, err error) {
+	ctx, span := startSpan(ctx, "ActivityRepository.FindByEntity")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	return repo.find(ctx, page, "entity_type = ? AND entity_id = ?", entityType, id)
+}
+
+func (repo *ActivityRepository) FindByActor(ctx context.Context, uid string, page entity.Page) (activities []*entity.Activity, total int64, err error) {
+	ctx, span := startSpan(ctx, "ActivityRepository.FindByActor")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	return repo.find(ctx, page, "actor_id = ?", uid)
+}
+
+// find はqueryに一致するActivityを新しい順にpageの範囲だけ取得する
+func (repo *ActivityRepository) find(ctx context.Context, page entity.Page, query string, args ...interface{}) (activities []*entity.Activity, total int64, err error) {
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "SELECT", "activities", func() error {
+		return db.Model(&entity.Activity{}).Where(query, args...).Count(&total).Error
+	})
+	if err != nil {
+		return
+	}
+	activities = []*entity.Activity{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "activities", func() error {
+		return db.Preload("Actor").Where(query, args...).
+			Order("created_at DESC").Order("id DESC").Limit(page.Limit).Offset(page.Offset).Find(&activities).Error
+	})
+	return
+}
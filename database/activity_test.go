@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestActivityRepository_FindByEntity(t *testing.T) {
+
+	activity, task := prepareActivityT(t)
+
+	addActivityData(t, activity)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	created := []*entity.Activity{}
+	for _, action := range []entity.ActivityAction{entity.ActionCreate, entity.ActionUpdate, entity.ActionComplete} {
+		a := &entity.Activity{
+			ActorID:    entity.NewNullString(uuidUA),
+			EntityType: entity.ActivityTask,
+			EntityID:   entity.NewNullString(uuidTA1),
+			Action:     action,
+			Changes:    entity.Changes{{Field: "title", Before: nil, After: "taskA1"}},
+		}
+		err := activity.Create(context.Background(), a)
+		errorCompare(t, err, nil)
+		created = append(created, a)
+	}
+
+	// 新しい順にpageの範囲だけ取得し，操作したユーザーも読み込む
+	got, total, err := activity.FindByEntity(context.Background(), entity.ActivityTask, uuidTA1, entity.Page{Limit: 2, Offset: 1})
+	errorCompare(t, err, nil)
+	if total != 3 || len(got) != 2 || got[0].ID != created[1].ID || got[0].Actor == nil || got[0].Actor.ID.String() != uuidUA {
+		t.Errorf("Data got = %v, total = %d", got, total)
+	}
+	if diff := cmp.Diff(created[1].Changes, got[0].Changes); diff != "" {
+		t.Errorf("Changes (-want +got) =\n%s", diff)
+	}
+
+	// Taskを削除してもActivityは残る
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	_, total, err = activity.FindByActor(context.Background(), uuidUA, entity.NewPage(0, 0))
+	errorCompare(t, err, nil)
+	if total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+}
+
+func addActivityData(t *testing.T, repo *ActivityRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	err := repo.db.Exec("TRUNCATE TABLE activities").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareActivityT(t *testing.T) (activity *ActivityRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	activity = NewActivityRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
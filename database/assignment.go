@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AssignmentRepository の具体的な実装
+type AssignmentRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewAssignmentRepository(db *DB, logger *slog.Logger) *AssignmentRepository {
+	return &AssignmentRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *AssignmentRepository) Create(ctx context.Context, a *entity.Assignment) (err error) {
+	ctx, span := startSpan(ctx, "AssignmentRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 担当者の変更と記録を同時に行う
+	// バージョンを上げるので，担当者が変わらない場合もTaskが存在すればRowsAffectedは1になる
+	var result *gorm.DB
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+		result = tx.Model(&entity.Task{}).Where("id = ?", a.TaskID).
+			Updates(map[string]interface{}{"assignee_id": a.AssigneeID, "version": incrementVersion})
+		return result.Error
+	})
+	if err != nil {
+		return
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrRecordNotFound
+	}
+
+	a.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "assignments", func() error {
+		return tx.Create(a).Error
+	})
+	return
+}
+
+// Dispatch はsendで外部に通知するので，タイムアウトはrepo.timeoutではなく呼び出し元のctxに従う
+// 送信中にトランザクションを保持しないように，先に変更を確保してコミットしてから送り，結果は変更ごとにコミットする
+func (repo *AssignmentRepository) Dispatch(ctx context.Context, now time.Time, limit int, send func(ctx context.Context, a *entity.Assignment) error) (sent int, err error) {
+	ctx, span := startSpan(ctx, "AssignmentRepository.Dispatch")
+	defer func() { endSpan(span, err) }()
+
+	assignments, err := repo.claim(ctx, now, limit)
+	if err != nil {
+		return
+	}
+	for _, a := range assignments {
+		if err = ctx.Err(); err != nil {
+			// 送らなかった変更は確保した期限が過ぎてから再び送る
+			return
+		}
+		sendErr := send(ctx, a)
+		if sendErr != nil {
+			repo.logger.WarnContext(ctx, "failed to send assignment",
+				slog.String("assignment_id", a.ID.String()),
+				slog.Int("attempts", a.Attempts+1),
+				slog.Any("error", sendErr),
+			)
+		}
+		err = repo.finish(ctx, a, now, sendErr == nil)
+		if err != nil {
+			return
+		}
+		a.Attempts++
+		if sendErr == nil {
+			a.NotifiedAt = &now
+			sent++
+		}
+	}
+	return
+}
+
+// claim は通知する変更をロックして確保し，すぐにコミットする
+// 確保した変更はClaimedUntilまで他のDispatchでは取得しない
+func (repo *AssignmentRepository) claim(ctx context.Context, now time.Time, limit int) (assignments []*entity.Assignment, err error) {
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のサーバーがロックしている変更は待たずに飛ばす
+	assignments = []*entity.Assignment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "assignments", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("notified_at IS NULL").Where("assignee_id IS NOT NULL").Where("attempts < ?", entity.MaxAssignmentAttempts).
+			Where("claimed_until IS NULL OR claimed_until <= ?", now).
+			Order("created_at").Order("id").Limit(limit).Find(&assignments).Error
+	})
+	if err != nil || len(assignments) == 0 {
+		return
+	}
+
+	ids := make([]string, len(assignments))
+	for i, a := range assignments {
+		ids[i] = a.ID.String()
+	}
+	until := claimUntil(ctx, now)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "assignments", func() error {
+		return tx.Model(&entity.Assignment{}).Where("id IN ?", ids).Update("claimed_until", until).Error
+	})
+	return
+}
+
+// finish はclaimで確保した変更の通知の結果を保存し，確保を解除する
+// 送った後に呼び出し元のctxが期限を過ぎても結果を保存するように，repo.timeoutに従う
+func (repo *AssignmentRepository) finish(ctx context.Context, a *entity.Assignment, now time.Time, sent bool) (err error) {
+	ctx, cancel := withTimeout(context.WithoutCancel(ctx), repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "claimed_until": nil}
+	if sent {
+		updates["notified_at"] = now
+	}
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "assignments", func() error {
+		return db.Model(&entity.Assignment{}).Where("id = ?", a.ID).Updates(updates).Error
+	})
+	return
+}
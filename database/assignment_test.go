@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestAssignmentRepository_Create(t *testing.T) {
+
+	assignment, task := prepareAssignmentT(t)
+
+	addAssignmentData(t, assignment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	// 存在しないTaskには担当者を設定できない
+	a := &entity.Assignment{TaskID: entity.NewNullString(uuidTB1), AssigneeID: entity.NewNullString(uuidUB), AssignerID: entity.NewNullString(uuidUA)}
+	err := assignment.Create(context.Background(), a)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	a = &entity.Assignment{TaskID: entity.NewNullString(uuidTA1), AssigneeID: entity.NewNullString(uuidUB), AssignerID: entity.NewNullString(uuidUA)}
+	err = assignment.Create(context.Background(), a)
+	errorCompare(t, err, nil)
+
+	found, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if found.AssigneeID.String() != uuidUB || found.Assignee == nil || found.Assignee.ID.String() != uuidUB {
+		t.Errorf("Data got = %v", found)
+	}
+
+	// 担当するTaskの一覧に含まれる
+	tasks, err := task.FindByUser(context.Background(), uuidUB, repository.TaskQuery{AssigneeID: uuidUB, SharedTaskIDs: []string{uuidTA1}})
+	errorCompare(t, err, nil)
+	if len(tasks) != 1 || tasks[0].ID.String() != uuidTA1 {
+		t.Errorf("Data got = %v", tasks)
+	}
+
+	// Taskを削除すると変更の記録も削除する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	var count int64
+	err = assignment.db.Model(&entity.Assignment{}).Count(&count).Error
+	errorCompare(t, err, nil)
+	if count != 0 {
+		t.Errorf("count = %d, want 0", count)
+	}
+}
+
+func TestAssignmentRepository_Dispatch(t *testing.T) {
+
+	assignment, task := prepareAssignmentT(t)
+
+	addAssignmentData(t, assignment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	assign := &entity.Assignment{TaskID: entity.NewNullString(uuidTA1), AssigneeID: entity.NewNullString(uuidUB), AssignerID: entity.NewNullString(uuidUA)}
+	unassign := &entity.Assignment{TaskID: entity.NewNullString(uuidTA1), AssignerID: entity.NewNullString(uuidUA)}
+	for _, a := range []*entity.Assignment{assign, unassign} {
+		err := assignment.Create(context.Background(), a)
+		errorCompare(t, err, nil)
+	}
+
+	// 担当者を外した記録は通知しない
+	now := time.Now().Truncate(time.Second)
+	sentIDs := []string{}
+	sent, err := assignment.Dispatch(context.Background(), now, 10, func(ctx context.Context, a *entity.Assignment) error {
+		sentIDs = append(sentIDs, a.ID.String())
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if sent != 1 || len(sentIDs) != 1 || sentIDs[0] != assign.ID.String() {
+		t.Errorf("sent assignments = %v, want [%s]", sentIDs, assign.ID.String())
+	}
+
+	// 通知済みのものは再び送らない
+	sent, err = assignment.Dispatch(context.Background(), now, 10, func(ctx context.Context, a *entity.Assignment) error {
+		t.Errorf("unexpected send: %v", a)
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+}
+
+func TestAssignmentRepository_Dispatch_Claim(t *testing.T) {
+
+	assignment, task := prepareAssignmentT(t)
+
+	addAssignmentData(t, assignment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	assign := &entity.Assignment{TaskID: entity.NewNullString(uuidTA1), AssigneeID: entity.NewNullString(uuidUB), AssignerID: entity.NewNullString(uuidUA)}
+	err := assignment.Create(context.Background(), assign)
+	errorCompare(t, err, nil)
+
+	// 通知中の変更は他のDispatchでは取得せず，送った後にctxがキャンセルされても通知済みにする
+	now := time.Now().Truncate(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sent, err := assignment.Dispatch(ctx, now, 10, func(ctx context.Context, a *entity.Assignment) error {
+		inner, err := assignment.Dispatch(context.Background(), now, 10, func(ctx context.Context, a *entity.Assignment) error {
+			t.Errorf("unexpected send: %v", a)
+			return nil
+		})
+		errorCompare(t, err, nil)
+		if inner != 0 {
+			t.Errorf("inner sent = %d, want 0", inner)
+		}
+		cancel()
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+
+	// 通知済みのものは再び送らない
+	sent, err = assignment.Dispatch(context.Background(), now, 10, func(ctx context.Context, a *entity.Assignment) error {
+		t.Errorf("unexpected send: %v", a)
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+}
+
+func addAssignmentData(t *testing.T, repo *AssignmentRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	err := repo.db.Exec("TRUNCATE TABLE assignments").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareAssignmentT(t *testing.T) (assignment *AssignmentRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	assignment = NewAssignmentRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
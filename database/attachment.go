@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttachmentRepository の具体的な実装
+type AttachmentRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewAttachmentRepository(db *DB, logger *slog.Logger) *AttachmentRepository {
+	return &AttachmentRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *AttachmentRepository) Create(ctx context.Context, a *entity.Attachment, quota int64) (err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 同じユーザーが同時に添付しても容量の上限を超えないように，ユーザーの行をロックしてから合計を数える
+	user := &entity.User{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "users", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", a.UserID).First(user).Error
+	})
+	if err != nil {
+		return
+	}
+	var usage int64
+	err = traceQuery(ctx, repo.logger, "SELECT", "attachments", func() error {
+		return tx.Model(&entity.Attachment{}).Where("user_id = ?", a.UserID).Select("COALESCE(SUM(size), 0)").Scan(&usage).Error
+	})
+	if err != nil {
+		return
+	}
+	if usage+a.Size > quota {
+		return entity.ErrQuotaExceeded
+	}
+
+	a.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "attachments", func() error {
+		return tx.Create(a).Error
+	})
+	return
+}
+
+func (repo *AttachmentRepository) FindByID(ctx context.Context, tid, id string) (attachment *entity.Attachment, err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	attachment = &entity.Attachment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "attachments", func() error {
+		return db.Where("id = ?", id).Where("task_id = ?", tid).First(attachment).Error
+	})
+	return
+}
+
+func (repo *AttachmentRepository) FindByTask(ctx context.Context, tid string) (attachments []*entity.Attachment, err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.FindByTask")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	attachments = []*entity.Attachment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "attachments", func() error {
+		return db.Where("task_id = ?", tid).Order("created_at").Order("id").Find(&attachments).Error
+	})
+	return
+}
+
+func (repo *AttachmentRepository) Usage(ctx context.Context, uid string) (size int64, err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.Usage")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "SELECT", "attachments", func() error {
+		return db.Model(&entity.Attachment{}).Where("user_id = ?", uid).Select("COALESCE(SUM(size), 0)").Scan(&size).Error
+	})
+	return
+}
+
+func (repo *AttachmentRepository) Delete(ctx context.Context, tid, id string) (err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するAttachmentがない場合を弾く
+	attachment := &entity.Attachment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "attachments", func() error {
+		return tx.Where("id = ?", id).Where("task_id = ?", tid).First(attachment).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = discardAttachments(ctx, tx, repo.logger, "id = ?", id)
+	return
+}
+
+// Purge はremoveで外部のBlobStoreを操作するので，タイムアウトはrepo.timeoutではなく呼び出し元のctxに従う
+func (repo *AttachmentRepository) Purge(ctx context.Context, limit int, remove func(ctx context.Context, key string) error) (removed int, err error) {
+	ctx, span := startSpan(ctx, "AttachmentRepository.Purge")
+	defer func() { endSpan(span, err) }()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のサーバーがロックしているファイルは待たずに飛ばす
+	blobs := []*entity.OrphanBlob{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "orphan_blobs", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("attempts < ?", entity.MaxOrphanBlobAttempts).
+			Order("created_at").Order("id").Limit(limit).Find(&blobs).Error
+	})
+	if err != nil {
+		return
+	}
+
+	for _, b := range blobs {
+		removeErr := remove(ctx, b.BlobKey.String())
+		if removeErr == nil {
+			err = traceQuery(ctx, repo.logger, "DELETE", "orphan_blobs", func() error {
+				return tx.Where("id = ?", b.ID).Delete(&entity.OrphanBlob{}).Error
+			})
+			if err != nil {
+				return
+			}
+			removed++
+			continue
+		}
+		repo.logger.WarnContext(ctx, "failed to remove blob",
+			slog.String("blob_key", b.BlobKey.String()),
+			slog.Int("attempts", b.Attempts+1),
+			slog.Any("error", removeErr),
+		)
+		err = traceQuery(ctx, repo.logger, "UPDATE", "orphan_blobs", func() error {
+			return tx.Model(&entity.OrphanBlob{}).Where("id = ?", b.ID).Update("attempts", gorm.Expr("attempts + 1")).Error
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// discardAttachments はqueryに一致するAttachmentを削除し，そのファイルをOrphanBlobとして記録する
+// Attachmentを削除するトランザクションの中で呼び出し，ファイルはスケジューラーがBlobStoreから削除する
+func discardAttachments(ctx context.Context, tx *gorm.DB, logger *slog.Logger, query string, args ...interface{}) (err error) {
+	keys := []string{}
+	err = traceQuery(ctx, logger, "SELECT", "attachments", func() error {
+		return tx.Model(&entity.Attachment{}).Where(query, args...).Pluck("blob_key", &keys).Error
+	})
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	blobs := make([]*entity.OrphanBlob, 0, len(keys))
+	for _, key := range keys {
+		blobs = append(blobs, (&entity.OrphanBlob{BlobKey: entity.NewNullString(key)}).NewID())
+	}
+	err = traceQuery(ctx, logger, "INSERT", "orphan_blobs", func() error {
+		return tx.Create(&blobs).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, logger, "DELETE", "attachments", func() error {
+		return tx.Where(query, args...).Delete(&entity.Attachment{}).Error
+	})
+	return
+}
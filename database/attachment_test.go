@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestAttachmentRepository_Create(t *testing.T) {
+
+	attachment, task := prepareAttachmentT(t)
+
+	addAttachmentData(t, attachment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	// 添付したファイルの合計が容量の上限を超えるものは記録しない
+	err := attachment.Create(context.Background(), newAttachmentData("k1", 60), 100)
+	errorCompare(t, err, nil)
+	err = attachment.Create(context.Background(), newAttachmentData("k2", 50), 100)
+	errorCompare(t, err, entity.ErrQuotaExceeded)
+	err = attachment.Create(context.Background(), newAttachmentData("k3", 40), 100)
+	errorCompare(t, err, nil)
+
+	usage, err := attachment.Usage(context.Background(), uuidUA)
+	errorCompare(t, err, nil)
+	if usage != 100 {
+		t.Errorf("usage = %d, want 100", usage)
+	}
+	got, err := attachment.FindByTask(context.Background(), uuidTA1)
+	errorCompare(t, err, nil)
+	if len(got) != 2 || got[0].BlobKey.String() != "k1" || got[1].BlobKey.String() != "k3" {
+		t.Errorf("Data got = %v", got)
+	}
+}
+
+func TestAttachmentRepository_Purge(t *testing.T) {
+
+	attachment, task := prepareAttachmentT(t)
+
+	addAttachmentData(t, attachment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	a := newAttachmentData("k1", 10)
+	err := attachment.Create(context.Background(), a, 100)
+	errorCompare(t, err, nil)
+
+	// Taskを削除するとAttachmentも削除し，ファイルを削除する対象として記録する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	_, err = attachment.FindByID(context.Background(), uuidTA1, a.ID.String())
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	// 削除に失敗したファイルは次の実行で再び削除する
+	removed, err := attachment.Purge(context.Background(), 10, func(ctx context.Context, key string) error {
+		return errors.New("unavailable")
+	})
+	errorCompare(t, err, nil)
+	if removed != 0 {
+		t.Errorf("removed = %d, want 0", removed)
+	}
+	keys := []string{}
+	removed, err = attachment.Purge(context.Background(), 10, func(ctx context.Context, key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if removed != 1 || len(keys) != 1 || keys[0] != "k1" {
+		t.Errorf("removed blobs = %v, want [k1]", keys)
+	}
+
+	// 削除したファイルは再び削除しない
+	removed, err = attachment.Purge(context.Background(), 10, func(ctx context.Context, key string) error {
+		t.Errorf("unexpected remove: %s", key)
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if removed != 0 {
+		t.Errorf("removed = %d, want 0", removed)
+	}
+}
+
+func TestAttachmentRepository_PurgeWithProject(t *testing.T) {
+
+	attachment, task := prepareAttachmentT(t)
+	project := NewProjectRepository(NewTestDB(), logging.Discard())
+
+	addAttachmentData(t, attachment)
+	addProjectData(t, project, []entity.Project{projectA1})
+	addTaskData(t, task, []entity.Task{taskA1, taskA2})
+	moveTask(t, project, uuidTA1, uuidPA1, false)
+	// Projectの外にあるサブタスクも親と一緒に削除される
+	err := project.db.Model(&entity.Task{}).Where("id = ?", uuidTA2).Update("parent_id", uuidTA1).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := newAttachmentData("k1", 10)
+	a.TaskID = entity.NewNullString(uuidTA2)
+	err = attachment.Create(context.Background(), a, 100)
+	errorCompare(t, err, nil)
+
+	// サブタスクに添付したファイルも削除する対象として記録する
+	_, err = project.Delete(context.Background(), uuidPA1, uuidUA, true)
+	errorCompare(t, err, nil)
+	keys := []string{}
+	removed, err := attachment.Purge(context.Background(), 10, func(ctx context.Context, key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if removed != 1 || len(keys) != 1 || keys[0] != "k1" {
+		t.Errorf("removed blobs = %v, want [k1]", keys)
+	}
+}
+
+// newAttachmentData はuuidUAがuuidTA1のTaskに添付したファイルを作る
+func newAttachmentData(key string, size int64) *entity.Attachment {
+	return &entity.Attachment{
+		TaskID:      entity.NewNullString(uuidTA1),
+		UserID:      entity.NewNullString(uuidUA),
+		Name:        entity.NewNullString("photo.png"),
+		ContentType: entity.NewNullString("image/png"),
+		Size:        size,
+		BlobKey:     entity.NewNullString(key),
+	}
+}
+
+func addAttachmentData(t *testing.T, repo *AttachmentRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	for _, table := range []string{"attachments", "orphan_blobs"} {
+		err := repo.db.Exec("TRUNCATE TABLE " + table).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func prepareAttachmentT(t *testing.T) (attachment *AttachmentRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	attachment = NewAttachmentRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
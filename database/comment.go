@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+)
+
+// CommentRepository の具体的な実装
+type CommentRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewCommentRepository(db *DB, logger *slog.Logger) *CommentRepository {
+	return &CommentRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *CommentRepository) Create(ctx context.Context, c *entity.Comment) (err error) {
+	ctx, span := startSpan(ctx, "CommentRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	c.NewID()
+	c.Edited = false
+	err = traceQuery(ctx, repo.logger, "INSERT", "comments", func() error {
+		return tx.Omit("Author").Create(c).Error
+	})
+	return
+}
+
+func (repo *CommentRepository) FindByID(ctx context.Context, tid, id string) (comment *entity.Comment, err error) {
+	ctx, span := startSpan(ctx, "CommentRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	comment = &entity.Comment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "comments", func() error {
+		return db.Preload("Author").Where("id = ?", id).Where("task_id = ?", tid).First(comment).Error
+	})
+	return
+}
+
+func (repo *CommentRepository) FindByTask(ctx context.Context, tid string, page entity.Page) (comments []*entity.Comment, total int64, err error) {
+	ctx, span := startSpan(ctx, "CommentRepository.FindByTask")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "SELECT", "comments", func() error {
+		return db.Model(&entity.Comment{}).Where("task_id = ?", tid).Count(&total).Error
+	})
+	if err != nil {
+		return
+	}
+	comments = []*entity.Comment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "comments", func() error {
+		return db.Preload("Author").Where("task_id = ?", tid).
+			Order("created_at").Order("id").Limit(page.Limit).Offset(page.Offset).Find(&comments).Error
+	})
+	return
+}
+
+func (repo *CommentRepository) Update(ctx context.Context, c *entity.Comment) (err error) {
+	ctx, span := startSpan(ctx, "CommentRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するCommentがない場合を弾く
+	comment := &entity.Comment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "comments", func() error {
+		return tx.Where("id = ?", c.ID).Where("task_id = ?", c.TaskID).First(comment).Error
+	})
+	if err != nil {
+		return
+	}
+
+	c.Edited = true
+	err = traceQuery(ctx, repo.logger, "UPDATE", "comments", func() error {
+		return tx.Model(c).Select("body", "edited", "updated_at").Updates(c).Error
+	})
+	return
+}
+
+func (repo *CommentRepository) Delete(ctx context.Context, tid, id string) (err error) {
+	ctx, span := startSpan(ctx, "CommentRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するCommentがない場合を弾く
+	comment := &entity.Comment{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "comments", func() error {
+		return tx.Where("id = ?", id).Where("task_id = ?", tid).First(comment).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "comments", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.Comment{}).Error
+	})
+	return
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestCommentRepository_FindByTask(t *testing.T) {
+
+	comment, task := prepareCommentT(t)
+
+	addCommentData(t, comment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	created := []*entity.Comment{}
+	for _, uid := range []string{uuidUA, uuidUB, uuidUA} {
+		c := &entity.Comment{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uid), Body: entity.NewNullString("body")}
+		err := comment.Create(context.Background(), c)
+		errorCompare(t, err, nil)
+		created = append(created, c)
+	}
+
+	// 書いた順にpageの範囲だけ取得し，書いたユーザーも読み込む
+	got, total, err := comment.FindByTask(context.Background(), uuidTA1, entity.Page{Limit: 2, Offset: 1})
+	errorCompare(t, err, nil)
+	if total != 3 || len(got) != 2 || got[0].ID != created[1].ID || got[0].Author == nil || got[0].Author.ID.String() != uuidUB {
+		t.Errorf("Data got = %v, total = %d", got, total)
+	}
+
+	// Taskを削除するとCommentも削除する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	_, total, err = comment.FindByTask(context.Background(), uuidTA1, entity.NewPage(0, 0))
+	errorCompare(t, err, nil)
+	if total != 0 {
+		t.Errorf("total = %d, want 0", total)
+	}
+}
+
+func TestCommentRepository_Update(t *testing.T) {
+
+	comment, task := prepareCommentT(t)
+
+	addCommentData(t, comment)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	c := &entity.Comment{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uuidUA), Body: entity.NewNullString("body")}
+	err := comment.Create(context.Background(), c)
+	errorCompare(t, err, nil)
+
+	// 他のTaskのCommentとしては更新できない
+	err = comment.Update(context.Background(), &entity.Comment{ID: c.ID, TaskID: entity.NewNullString(uuidTB1), Body: entity.NewNullString("edited")})
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	c.Body = entity.NewNullString("edited")
+	err = comment.Update(context.Background(), c)
+	errorCompare(t, err, nil)
+	got, err := comment.FindByID(context.Background(), uuidTA1, c.ID.String())
+	errorCompare(t, err, nil)
+	if got.Body.String() != "edited" || !got.Edited {
+		t.Errorf("Data got = %v", got)
+	}
+
+	err = comment.Delete(context.Background(), uuidTA1, c.ID.String())
+	errorCompare(t, err, nil)
+	err = comment.Delete(context.Background(), uuidTA1, c.ID.String())
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func addCommentData(t *testing.T, repo *CommentRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	err := repo.db.Exec("TRUNCATE TABLE comments").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareCommentT(t *testing.T) (comment *CommentRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	comment = NewCommentRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// withTimeout はクエリのタイムアウトを設定したcontextを返す
+// timeoutが0以下の場合は呼び出し元のcontextの期限のみに従う
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// defaultClaimLease は呼び出し元のctxに期限がない場合にDispatchで確保した行を他のDispatchに渡さない期間である
+const defaultClaimLease = 5 * time.Minute
+
+// claimUntil はDispatchで確保した行を他のDispatchに渡さない期限を返す
+// 期限を過ぎても送信済みにならなかった行は再び送る
+func claimUntil(ctx context.Context, now time.Time) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return now.Add(time.Until(deadline))
+	}
+	return now.Add(defaultClaimLease)
+}
+
+// txKey はTransactor.Transactionで開始したトランザクションをcontextに保持するキーである
+type txKey struct{}
+
+// nestedTx はTransactor.Transactionの中で開始したトランザクションである
+// コミットとロールバックは外側のトランザクションでまとめて行う
+type nestedTx struct {
+	gorm.ConnPool
+}
+
+func (*nestedTx) Commit() error   { return nil }
+func (*nestedTx) Rollback() error { return nil }
+
+// session はctxに紐づけたdbを返す．ctxがTransactor.Transactionの中であればそのトランザクションを使う
+func session(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if outer, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		tx := outer.WithContext(ctx)
+		tx.Statement.ConnPool = &nestedTx{ConnPool: tx.Statement.ConnPool}
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// beginTx はctxに紐づいたトランザクションを開始する
+// ctxがキャンセルされるとトランザクション中のクエリも中断され，ロールバックされる
+// ctxがTransactor.Transactionの中であれば新しく開始せずにそのトランザクションを使う
+func beginTx(ctx context.Context, db *gorm.DB) (tx *gorm.DB, err error) {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return session(ctx, db), nil
+	}
+	tx = db.WithContext(ctx).Begin(&sql.TxOptions{})
+	return tx, tx.Error
+}
+
+// endTx はerrがnilならコミット，そうでなければロールバックする
+func endTx(tx *gorm.DB, err error) error {
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// contextError はctxがキャンセルまたはタイムアウトしていた場合にドライバーのエラーをctx.Err()に置き換える
+// (go-sql-driver/mysqlは接続を切断するのでErrInvalidConnなどが返ってくる)
+func contextError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
@@ -6,31 +6,45 @@ domainにのみ依存
 package database
 
 import (
-	_ "github.com/go-sql-driver/mysql"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
 	"github.com/hiroyaonoe/todoapp-server/config"
-	"github.com/jinzhu/gorm"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 // DB はデータベースの情報を示す
 type DB struct {
-	dsn        string
-	connection *gorm.DB
+	dsn          string
+	connection   *gorm.DB
+	queryTimeout time.Duration
 }
 
 func NewDB() *DB {
 	return newDB(&DB{
-		dsn: config.DSN("Production"),
+		dsn:          config.DSN("Production"),
+		queryTimeout: config.QueryTimeout(),
 	})
 }
 
 func NewTestDB() *DB {
 	return newDB(&DB{
-		dsn: config.DSN("Test"),
+		dsn:          config.DSN("Test"),
+		queryTimeout: config.QueryTimeout(),
 	})
 }
 
 func newDB(d *DB) *DB {
-	db, err := gorm.Open("mysql", d.dsn)
+	db, err := gorm.Open(mysql.Open(d.dsn), &gorm.Config{
+		// 同じクエリのプリペアドステートメントを使い回す
+		PrepareStmt: true,
+		// SQLの値を出力しないようにSetLoggerが呼ばれるまでは何も出力しない
+		Logger: logger.Discard,
+	})
 	if err != nil {
 		panic(err.Error())
 	}
@@ -42,6 +56,58 @@ func (db *DB) Connect() *gorm.DB {
 	return db.connection
 }
 
+// QueryTimeout はRepositoryのメソッドひとつあたりのタイムアウトを返す
+func (db *DB) QueryTimeout() time.Duration {
+	return db.queryTimeout
+}
+
+// LogMode は値を含むすべてのSQLを出力する(デバッグ用)
+// パスワードのハッシュなども出力されるので本番では使わない
 func (db *DB) LogMode(b bool) {
-	db.Connect().LogMode(b)
+	if b {
+		db.connection.Logger = logger.Default.LogMode(logger.Info)
+	} else {
+		db.connection.Logger = logger.Discard
+	}
+}
+
+// SetLogger はgormが出力するエラーログをslogに流す
+func (db *DB) SetLogger(l *slog.Logger) {
+	db.connection.Logger = &gormLogger{logger: l}
+}
+
+// gormLogger はgormのlogger.Interfaceをslogに変換する
+// SQLの値にはパスワードのハッシュなどが含まれるので，SQL文は出力しない
+// (クエリごとの所要時間はtraceQueryで出力する)
+type gormLogger struct {
+	logger *slog.Logger
+}
+
+func (l *gormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.DebugContext(ctx, "gorm", slog.String("message", msg), slog.Any("args", args))
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.WarnContext(ctx, "gorm", slog.String("message", msg), slog.Any("args", args))
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger.ErrorContext(ctx, "gorm", slog.String("message", msg), slog.Any("args", args))
+}
+
+// Trace はクエリの実行後に呼ばれる．エラーの場合のみ出力する
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+	_, rows := fc()
+	l.logger.ErrorContext(ctx, "gorm error",
+		slog.Any("error", err),
+		slog.Duration("duration", time.Since(begin)),
+		slog.Int64("rows", rows),
+	)
 }
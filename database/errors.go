@@ -0,0 +1,17 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// mysqlError はドライバーのエラーをdomainのエラーに変換する
+func mysqlError(err error) error {
+	var nerr *mysql.MySQLError
+	if errors.As(err, &nerr) {
+		return (*entity.ErrMySQL)(nerr)
+	}
+	return err
+}
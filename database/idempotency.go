@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository の具体的な実装
+// 複数のサーバーで同じキーのリクエストを一度だけ処理するためにデータベースに保存する
+type IdempotencyRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewIdempotencyRepository(db *DB, logger *slog.Logger) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *IdempotencyRepository) Reserve(ctx context.Context, k *entity.IdempotencyKey, now time.Time) (existing *entity.IdempotencyKey, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyRepository.Reserve")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 期限の切れた記録は削除して同じキーで新しく保存できるようにする
+	// 処理中のままサーバーが停止した記録もロックの期限が切れたら削除して引き継ぐ
+	err = traceQuery(ctx, repo.logger, "DELETE", "idempotency_keys", func() error {
+		return tx.Where("user_id = ? AND idempotency_key = ?", k.UserID, k.Key).
+			Where("expires_at <= ? OR (status_code = 0 AND locked_until <= ?)", now, now).Delete(&entity.IdempotencyKey{}).Error
+	})
+	if err != nil {
+		return
+	}
+	// 同じキーのリクエストが同時に届いた場合は主キーの制約によりひとつだけが保存される
+	var inserted int64
+	err = traceQuery(ctx, repo.logger, "INSERT", "idempotency_keys", func() error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(k)
+		inserted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil || inserted > 0 {
+		return
+	}
+	existing = &entity.IdempotencyKey{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "idempotency_keys", func() error {
+		return tx.Where("user_id = ? AND idempotency_key = ?", k.UserID, k.Key).First(existing).Error
+	})
+	return
+}
+
+func (repo *IdempotencyRepository) Complete(ctx context.Context, k *entity.IdempotencyKey) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyRepository.Complete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "idempotency_keys", func() error {
+		// 主キーを明示して条件にする
+		// 他のリクエストが引き継いだ記録はlocked_untilが異なるので上書きしない
+		result := db.Model(&entity.IdempotencyKey{}).Where("user_id = ? AND idempotency_key = ? AND status_code = 0 AND locked_until = ?", k.UserID, k.Key, k.LockedUntil).Updates(map[string]interface{}{
+			"status_code":  k.StatusCode,
+			"content_type": k.ContentType,
+			"etag":         k.ETag,
+			"body":         k.Body,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrRecordNotFound
+		}
+		return nil
+	})
+	return
+}
+
+func (repo *IdempotencyRepository) Release(ctx context.Context, k *entity.IdempotencyKey) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyRepository.Release")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	// 保存済みのレスポンスと他のリクエストが引き継いだ記録は削除しない
+	err = traceQuery(ctx, repo.logger, "DELETE", "idempotency_keys", func() error {
+		return db.Where("user_id = ? AND idempotency_key = ? AND status_code = 0 AND locked_until = ?", k.UserID, k.Key, k.LockedUntil).Delete(&entity.IdempotencyKey{}).Error
+	})
+	return
+}
+
+func (repo *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (deleted int64, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyRepository.DeleteExpired")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "DELETE", "idempotency_keys", func() error {
+		result := db.Where("expires_at <= ?", now).Delete(&entity.IdempotencyKey{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return
+}
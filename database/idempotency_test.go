@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+
+	idempotency := prepareIdempotencyT(t)
+
+	now := time.Date(2020, 12, 6, 0, 0, 0, 0, time.Local)
+	anonymous := entity.AnonymousIdempotencyScope + "192.0.2.1"
+
+	tests := []struct {
+		name         string
+		key          *entity.IdempotencyKey
+		wantExisting *entity.IdempotencyKey
+		wantErr      error
+		prepareKeys  []entity.IdempotencyKey
+	}{
+		{
+			name:         "存在しないキーなら処理中として保存できる",
+			key:          entity.NewIdempotencyKey("userA", "keyA", "fpA", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: nil,
+			wantErr:      nil,
+		},
+		{
+			name:         "ログインしていなくても保存できる",
+			key:          entity.NewIdempotencyKey(anonymous, "keyA", "fpA", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: nil,
+			wantErr:      nil,
+			prepareKeys: []entity.IdempotencyKey{
+				{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: now, ExpiresAt: now.Add(time.Hour)},
+			},
+		},
+		{
+			name:         "存在するキーなら保存済みの記録を返す",
+			key:          entity.NewIdempotencyKey("userA", "keyA", "fpB", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: &entity.IdempotencyKey{UserID: "userA", Key: "keyA", Fingerprint: "fpA", StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`), LockedUntil: now, ExpiresAt: now.Add(time.Minute)},
+			wantErr:      nil,
+			prepareKeys: []entity.IdempotencyKey{
+				{UserID: "userA", Key: "keyA", Fingerprint: "fpA", StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`), LockedUntil: now, ExpiresAt: now.Add(time.Minute)},
+			},
+		},
+		{
+			name:         "処理中のキーなら処理中の記録を返す",
+			key:          entity.NewIdempotencyKey("userA", "keyA", "fpA", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: &entity.IdempotencyKey{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)},
+			wantErr:      nil,
+			prepareKeys: []entity.IdempotencyKey{
+				{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: now.Add(time.Second), ExpiresAt: now.Add(time.Hour)},
+			},
+		},
+		{
+			name:         "処理中のままロックの期限が切れたキーなら引き継いで保存し直す",
+			key:          entity.NewIdempotencyKey("userA", "keyA", "fpA", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: nil,
+			wantErr:      nil,
+			prepareKeys: []entity.IdempotencyKey{
+				{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: now, ExpiresAt: now.Add(time.Hour)},
+			},
+		},
+		{
+			name:         "期限の切れたキーなら保存し直す",
+			key:          entity.NewIdempotencyKey("userA", "keyA", "fpB", now.Add(time.Minute), now.Add(time.Hour)),
+			wantExisting: nil,
+			wantErr:      nil,
+			prepareKeys: []entity.IdempotencyKey{
+				{UserID: "userA", Key: "keyA", Fingerprint: "fpA", StatusCode: 201, LockedUntil: now, ExpiresAt: now},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addIdempotencyData(t, idempotency, tt.prepareKeys)
+
+			gotExisting, err := idempotency.Reserve(context.Background(), tt.key, now)
+
+			errorCompare(t, err, tt.wantErr)
+			cmpopt := cmpopts.IgnoreFields(entity.IdempotencyKey{}, "CreatedAt", "UpdatedAt")
+			if diff := cmp.Diff(tt.wantExisting, gotExisting, cmpopt); diff != "" {
+				t.Errorf("Existing (-want +got) =\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestIdempotencyRepository_CompleteAndRelease(t *testing.T) {
+
+	idempotency := prepareIdempotencyT(t)
+
+	now := time.Date(2020, 12, 6, 0, 0, 0, 0, time.Local)
+	anonymous := entity.AnonymousIdempotencyScope + "192.0.2.1"
+	lockedUntil := now.Add(time.Minute)
+	addIdempotencyData(t, idempotency, []entity.IdempotencyKey{
+		{UserID: anonymous, Key: "keyA", Fingerprint: "fpA", LockedUntil: lockedUntil, ExpiresAt: now.Add(time.Hour)},
+		{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: lockedUntil, ExpiresAt: now.Add(time.Hour)},
+	})
+
+	// 他のリクエストが引き継いだ記録は上書きも削除もしない
+	taken := &entity.IdempotencyKey{UserID: "userA", Key: "keyA", StatusCode: 201, LockedUntil: now}
+	err := idempotency.Complete(context.Background(), taken)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+	err = idempotency.Release(context.Background(), taken)
+	errorCompare(t, err, nil)
+
+	completed := &entity.IdempotencyKey{UserID: "userA", Key: "keyA", StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`), LockedUntil: lockedUntil}
+	err = idempotency.Complete(context.Background(), completed)
+	errorCompare(t, err, nil)
+	// 保存済みのレスポンスは上書きも削除もしない
+	err = idempotency.Complete(context.Background(), completed)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+	err = idempotency.Release(context.Background(), completed)
+	errorCompare(t, err, nil)
+
+	// 処理中のキーは削除され，同じキーで保存し直せる
+	err = idempotency.Release(context.Background(), &entity.IdempotencyKey{UserID: anonymous, Key: "keyA", LockedUntil: lockedUntil})
+	errorCompare(t, err, nil)
+	existing, err := idempotency.Reserve(context.Background(), entity.NewIdempotencyKey(anonymous, "keyA", "fpB", now.Add(time.Minute), now.Add(time.Hour)), now)
+	errorCompare(t, err, nil)
+	if existing != nil {
+		t.Errorf("Reserve() after Release = %+v, want nil", existing)
+	}
+
+	existing, err = idempotency.Reserve(context.Background(), entity.NewIdempotencyKey("userA", "keyA", "fpA", now.Add(time.Minute), now.Add(time.Hour)), now)
+	errorCompare(t, err, nil)
+	if existing == nil || existing.StatusCode != 201 || string(existing.Body) != `{}` {
+		t.Errorf("Reserve() after Complete = %+v, want completed record", existing)
+	}
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+
+	idempotency := prepareIdempotencyT(t)
+
+	now := time.Date(2020, 12, 6, 0, 0, 0, 0, time.Local)
+	addIdempotencyData(t, idempotency, []entity.IdempotencyKey{
+		{UserID: "userA", Key: "keyA", Fingerprint: "fpA", LockedUntil: now, ExpiresAt: now.Add(-time.Minute)},
+		{UserID: "userA", Key: "keyB", Fingerprint: "fpB", LockedUntil: now, ExpiresAt: now},
+		{UserID: "userA", Key: "keyC", Fingerprint: "fpC", LockedUntil: now, ExpiresAt: now.Add(time.Minute)},
+	})
+
+	deleted, err := idempotency.DeleteExpired(context.Background(), now)
+	errorCompare(t, err, nil)
+	if deleted != 2 {
+		t.Errorf("DeleteExpired() = %d, want 2", deleted)
+	}
+}
+
+// addIdempotencyData はテスト用のIdempotency-Keyの記録をデータベースに追加する
+func addIdempotencyData(t *testing.T, repo *IdempotencyRepository, keys []entity.IdempotencyKey) {
+	t.Helper()
+
+	// databaseを初期化する
+	db := repo.db
+	err := db.Exec("TRUNCATE TABLE idempotency_keys").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range keys {
+		err = db.Create(&key).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+func prepareIdempotencyT(t *testing.T) (idempotency *IdempotencyRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	idempotency = NewIdempotencyRepository(db, logging.Discard())
+
+	return
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository の具体的な実装
+// 複数のサーバーで同じ失敗回数を共有するためにデータベースに保存する
+type LoginAttemptRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewLoginAttemptRepository(db *DB, logger *slog.Logger) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *LoginAttemptRepository) FindByAccount(ctx context.Context, account string) (attempt *entity.LoginAttempt, err error) {
+	ctx, span := startSpan(ctx, "LoginAttemptRepository.FindByAccount")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	attempt = &entity.LoginAttempt{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "login_attempts", func() error {
+		return db.Where("account = ?", account).First(attempt).Error
+	})
+	return
+}
+
+func (repo *LoginAttemptRepository) Fail(ctx context.Context, account string, now time.Time, lock func(failures int) time.Duration) (attempt *entity.LoginAttempt, err error) {
+	ctx, span := startSpan(ctx, "LoginAttemptRepository.Fail")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// accountが存在しなければINSERT，存在すればfailuresをデータベース上で1増やす
+	// 更新した行はトランザクションが終わるまでロックされるので，同時に失敗しても回数を数え漏らさない
+	err = traceQuery(ctx, repo.logger, "UPSERT", "login_attempts", func() error {
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "account"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":   gorm.Expr("failures + 1"),
+				"updated_at": now,
+			}),
+		}).Create(&entity.LoginAttempt{Account: account, Failures: 1}).Error
+	})
+	if err != nil {
+		return
+	}
+
+	attempt = &entity.LoginAttempt{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "login_attempts", func() error {
+		return tx.Where("account = ?", account).First(attempt).Error
+	})
+	if err != nil {
+		return
+	}
+	d := lock(attempt.Failures)
+	if d <= 0 {
+		return
+	}
+	attempt.Lock(now, d)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "login_attempts", func() error {
+		return tx.Model(&entity.LoginAttempt{}).Where("account = ?", account).Update("locked_until", attempt.LockedUntil).Error
+	})
+	return
+}
+
+func (repo *LoginAttemptRepository) Delete(ctx context.Context, account string) (err error) {
+	ctx, span := startSpan(ctx, "LoginAttemptRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "DELETE", "login_attempts", func() error {
+		return db.Where("account = ?", account).Delete(&entity.LoginAttempt{}).Error
+	})
+	return
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestLoginAttemptRepository_Fail(t *testing.T) {
+
+	attempt := prepareLoginAttemptT(t)
+
+	now := time.Date(2020, 12, 6, 0, 0, 0, 0, time.Local)
+	lockedUntil := now.Add(time.Minute)
+	// 5回目以降の失敗で1分ロックする
+	lock := func(failures int) time.Duration {
+		if failures < 5 {
+			return 0
+		}
+		return time.Minute
+	}
+
+	tests := []struct {
+		name            string
+		account         string
+		wantAttempt     *entity.LoginAttempt
+		wantErr         error
+		prepareAttempts []entity.LoginAttempt
+	}{
+		{
+			name:        "存在しないaccountなら新規に作成できる",
+			account:     "exampleA@example.com",
+			wantAttempt: &entity.LoginAttempt{Account: "exampleA@example.com", Failures: 1},
+			wantErr:     nil,
+		},
+		{
+			name:        "存在するaccountなら失敗回数を増やしてロックできる",
+			account:     "exampleA@example.com",
+			wantAttempt: &entity.LoginAttempt{Account: "exampleA@example.com", Failures: 5, LockedUntil: &lockedUntil},
+			wantErr:     nil,
+			prepareAttempts: []entity.LoginAttempt{
+				{Account: "exampleA@example.com", Failures: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addLoginAttemptData(t, attempt, tt.prepareAttempts)
+
+			got, err := attempt.Fail(context.Background(), tt.account, now, lock)
+
+			errorCompare(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				gotAttempt, err := attempt.FindByAccount(context.Background(), tt.account)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cmpopt := cmpopts.IgnoreFields(entity.LoginAttempt{}, "UpdatedAt")
+				if diff := cmp.Diff(tt.wantAttempt, gotAttempt, cmpopt); diff != "" {
+					t.Errorf("Data (-want +got) =\n%s\n", diff)
+				}
+				if diff := cmp.Diff(tt.wantAttempt, got, cmpopt); diff != "" {
+					t.Errorf("Return (-want +got) =\n%s\n", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginAttemptRepository_Fail_Concurrent(t *testing.T) {
+
+	attempt := prepareLoginAttemptT(t)
+
+	addLoginAttemptData(t, attempt, nil)
+
+	// 同時に失敗しても回数を数え漏らさない
+	noLock := func(int) time.Duration { return 0 }
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := attempt.Fail(context.Background(), "exampleA@example.com", time.Now(), noLock)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := attempt.FindByAccount(context.Background(), "exampleA@example.com")
+	errorCompare(t, err, nil)
+	if got.Failures != 10 {
+		t.Errorf("Failures = %d, want 10", got.Failures)
+	}
+}
+
+func TestLoginAttemptRepository_Delete(t *testing.T) {
+
+	attempt := prepareLoginAttemptT(t)
+
+	addLoginAttemptData(t, attempt, []entity.LoginAttempt{
+		{Account: "exampleA@example.com", Failures: 3},
+	})
+
+	err := attempt.Delete(context.Background(), "exampleA@example.com")
+	errorCompare(t, err, nil)
+
+	_, err = attempt.FindByAccount(context.Background(), "exampleA@example.com")
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+// addLoginAttemptData はテスト用のログイン失敗の記録をデータベースに追加する
+func addLoginAttemptData(t *testing.T, repo *LoginAttemptRepository, attempts []entity.LoginAttempt) {
+	t.Helper()
+
+	// databaseを初期化する
+	db := repo.db
+	err := db.Exec("TRUNCATE TABLE login_attempts").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, attempt := range attempts {
+		err = db.Create(&attempt).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+func prepareLoginAttemptT(t *testing.T) (attempt *LoginAttemptRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	attempt = NewLoginAttemptRepository(db, logging.Discard())
+
+	return
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+)
+
+// MembershipRepository の具体的な実装
+type MembershipRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewMembershipRepository(db *DB, logger *slog.Logger) *MembershipRepository {
+	return &MembershipRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *MembershipRepository) Create(ctx context.Context, m *entity.Membership) (err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	m.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "memberships", func() error {
+		return tx.Create(m).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) FindByID(ctx context.Context, wid, id string) (membership *entity.Membership, err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	membership = &entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return db.Where("id = ?", id).Where("workspace_id = ?", wid).First(membership).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) Find(ctx context.Context, wid, uid string) (membership *entity.Membership, err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.Find")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	membership = &entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return db.Where("workspace_id = ?", wid).Where("user_id = ?", uid).First(membership).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) FindByWorkspace(ctx context.Context, wid string) (memberships []*entity.Membership, err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.FindByWorkspace")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	memberships = []*entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return db.Where("workspace_id = ?", wid).Order("created_at").Order("id").Find(&memberships).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) FindByUser(ctx context.Context, uid string) (memberships []*entity.Membership, err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.FindByUser")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	memberships = []*entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return db.Where("user_id = ?", uid).Order("created_at").Order("id").Find(&memberships).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) Update(ctx context.Context, m *entity.Membership) (err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するMembershipがない場合を弾く
+	membership := &entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return tx.Where("id = ?", m.ID).Where("workspace_id = ?", m.WorkspaceID).First(membership).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "UPDATE", "memberships", func() error {
+		return tx.Model(membership).Update("role", m.Role).Error
+	})
+	return
+}
+
+func (repo *MembershipRepository) Delete(ctx context.Context, wid, id string) (err error) {
+	ctx, span := startSpan(ctx, "MembershipRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するMembershipがない場合を弾く
+	membership := &entity.Membership{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "memberships", func() error {
+		return tx.Where("id = ?", id).Where("workspace_id = ?", wid).First(membership).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "memberships", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.Membership{}).Error
+	})
+	return
+}
@@ -0,0 +1,9 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS login_attempts (
+    account VARCHAR(128) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    locked_until DATETIME,
+    updated_at DATETIME
+);
+-- +migrate Down
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,12 @@
+
+-- +migrate Up
+ALTER TABLE tasks
+    ADD COLUMN priority TINYINT NOT NULL DEFAULT 0,
+    -- 辞書順で比較するのでバイナリで照合する
+    ADD COLUMN position VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL DEFAULT '';
+CREATE INDEX index_tasks_on_user_id_and_deadline_and_position ON tasks (user_id, deadline, position);
+-- +migrate Down
+DROP INDEX index_tasks_on_user_id_and_deadline_and_position ON tasks;
+ALTER TABLE tasks
+    DROP COLUMN position,
+    DROP COLUMN priority;
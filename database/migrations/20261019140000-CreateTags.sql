@@ -0,0 +1,23 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tags (
+    id VARCHAR(128) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    color VARCHAR(32),
+    user_id VARCHAR(128) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    UNIQUE KEY (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id VARCHAR(128) NOT NULL,
+    tag_id VARCHAR(128) NOT NULL,
+    PRIMARY KEY (task_id, tag_id),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+CREATE INDEX index_task_tags_on_tag_id ON task_tags (tag_id);
+-- +migrate Down
+DROP TABLE IF EXISTS task_tags;
+DROP TABLE IF EXISTS tags;
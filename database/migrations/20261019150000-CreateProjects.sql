@@ -0,0 +1,21 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS projects (
+    id VARCHAR(128) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX index_projects_on_user_id ON projects (user_id);
+ALTER TABLE tasks
+    ADD COLUMN project_id VARCHAR(128) NULL,
+    ADD FOREIGN KEY fk_tasks_project_id (project_id) REFERENCES projects (id) ON DELETE SET NULL;
+CREATE INDEX index_tasks_on_user_id_and_project_id ON tasks (user_id, project_id);
+-- +migrate Down
+DROP INDEX index_tasks_on_user_id_and_project_id ON tasks;
+ALTER TABLE tasks
+    DROP FOREIGN KEY fk_tasks_project_id,
+    DROP COLUMN project_id;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,11 @@
+
+-- +migrate Up
+ALTER TABLE tasks
+    ADD COLUMN parent_id VARCHAR(128) NULL,
+    ADD FOREIGN KEY fk_tasks_parent_id (parent_id) REFERENCES tasks (id) ON DELETE CASCADE;
+CREATE INDEX index_tasks_on_user_id_and_parent_id ON tasks (user_id, parent_id);
+-- +migrate Down
+DROP INDEX index_tasks_on_user_id_and_parent_id ON tasks;
+ALTER TABLE tasks
+    DROP FOREIGN KEY fk_tasks_parent_id,
+    DROP COLUMN parent_id;
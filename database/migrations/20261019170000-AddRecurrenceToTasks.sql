@@ -0,0 +1,5 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN recurrence VARCHAR(255) NULL;
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN recurrence;
@@ -0,0 +1,7 @@
+
+-- +migrate Up
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64) NULL;
+ALTER TABLE tasks ADD COLUMN due_time CHAR(5) NULL;
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN due_time;
+ALTER TABLE users DROP COLUMN time_zone;
@@ -0,0 +1,19 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS reminders (
+    id VARCHAR(128) PRIMARY KEY,
+    task_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    remind_at DATETIME NOT NULL,
+    remind_before VARCHAR(32) NULL,
+    sent_at DATETIME NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX index_reminders_on_task_id ON reminders (task_id);
+CREATE INDEX index_reminders_on_sent_at_and_remind_at ON reminders (sent_at, remind_at);
+-- +migrate Down
+DROP TABLE IF EXISTS reminders;
@@ -0,0 +1,6 @@
+
+-- +migrate Up
+-- 日本語の語も検索できるようにngramパーサーを使う
+CREATE FULLTEXT INDEX index_tasks_on_title_and_content ON tasks (title, content) WITH PARSER ngram;
+-- +migrate Down
+DROP INDEX index_tasks_on_title_and_content ON tasks;
@@ -0,0 +1,20 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS shares (
+    id VARCHAR(128) PRIMARY KEY,
+    resource_type VARCHAR(16) NOT NULL,
+    resource_id VARCHAR(128) NOT NULL,
+    owner_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    accepted_at DATETIME NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    UNIQUE KEY index_shares_on_resource_type_and_resource_id_and_user_id (resource_type, resource_id, user_id)
+);
+CREATE INDEX index_shares_on_owner_id ON shares (owner_id);
+CREATE INDEX index_shares_on_user_id ON shares (user_id);
+-- +migrate Down
+DROP TABLE IF EXISTS shares;
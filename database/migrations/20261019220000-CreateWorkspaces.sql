@@ -0,0 +1,31 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS workspaces (
+    id VARCHAR(128) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+CREATE TABLE IF NOT EXISTS memberships (
+    id VARCHAR(128) PRIMARY KEY,
+    workspace_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    UNIQUE KEY index_memberships_on_workspace_id_and_user_id (workspace_id, user_id)
+);
+CREATE INDEX index_memberships_on_user_id ON memberships (user_id);
+ALTER TABLE tasks
+    ADD COLUMN workspace_id VARCHAR(128) NULL,
+    ADD FOREIGN KEY fk_tasks_workspace_id (workspace_id) REFERENCES workspaces (id) ON DELETE SET NULL;
+CREATE INDEX index_tasks_on_workspace_id ON tasks (workspace_id);
+-- +migrate Down
+DROP INDEX index_tasks_on_workspace_id ON tasks;
+ALTER TABLE tasks
+    DROP FOREIGN KEY fk_tasks_workspace_id,
+    DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,26 @@
+
+-- +migrate Up
+ALTER TABLE tasks
+    ADD COLUMN assignee_id VARCHAR(128) NULL,
+    ADD FOREIGN KEY fk_tasks_assignee_id (assignee_id) REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX index_tasks_on_assignee_id ON tasks (assignee_id);
+CREATE TABLE IF NOT EXISTS assignments (
+    id VARCHAR(128) PRIMARY KEY,
+    task_id VARCHAR(128) NOT NULL,
+    assignee_id VARCHAR(128) NULL,
+    assigner_id VARCHAR(128) NOT NULL,
+    notified_at DATETIME NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (assigner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX index_assignments_on_task_id ON assignments (task_id);
+CREATE INDEX index_assignments_on_notified_at_and_created_at ON assignments (notified_at, created_at);
+-- +migrate Down
+DROP TABLE IF EXISTS assignments;
+DROP INDEX index_tasks_on_assignee_id ON tasks;
+ALTER TABLE tasks
+    DROP FOREIGN KEY fk_tasks_assignee_id,
+    DROP COLUMN assignee_id;
@@ -0,0 +1,17 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS comments (
+    id VARCHAR(128) PRIMARY KEY,
+    task_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    body TEXT NOT NULL,
+    edited BOOLEAN NOT NULL DEFAULT false,
+    -- 同じ秒に書いたCommentも書いた順に並べる
+    created_at DATETIME(6),
+    updated_at DATETIME,
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX index_comments_on_task_id_and_created_at ON comments (task_id, created_at);
+-- +migrate Down
+DROP TABLE IF EXISTS comments;
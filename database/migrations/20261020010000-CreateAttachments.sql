@@ -0,0 +1,26 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS attachments (
+    id VARCHAR(128) PRIMARY KEY,
+    task_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key VARCHAR(255) NOT NULL,
+    created_at DATETIME(6),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX index_attachments_on_task_id_and_created_at ON attachments (task_id, created_at);
+CREATE INDEX index_attachments_on_user_id ON attachments (user_id);
+CREATE TABLE IF NOT EXISTS orphan_blobs (
+    id VARCHAR(128) PRIMARY KEY,
+    blob_key VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at DATETIME
+);
+CREATE INDEX index_orphan_blobs_on_created_at ON orphan_blobs (created_at);
+-- +migrate Down
+DROP TABLE IF EXISTS orphan_blobs;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,17 @@
+
+-- +migrate Up
+-- 操作の記録は対象のTaskやUserを削除した後も残すので外部キーを付けない
+CREATE TABLE IF NOT EXISTS activities (
+    id VARCHAR(128) PRIMARY KEY,
+    actor_id VARCHAR(128) NOT NULL,
+    entity_type VARCHAR(16) NOT NULL,
+    entity_id VARCHAR(128) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    changes JSON NOT NULL,
+    -- 同じ秒に行った操作も行った順に並べる
+    created_at DATETIME(6)
+);
+CREATE INDEX index_activities_on_entity_and_created_at ON activities (entity_type, entity_id, created_at);
+CREATE INDEX index_activities_on_actor_id_and_created_at ON activities (actor_id, created_at);
+-- +migrate Down
+DROP TABLE IF EXISTS activities;
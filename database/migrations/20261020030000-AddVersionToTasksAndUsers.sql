@@ -0,0 +1,8 @@
+
+-- +migrate Up
+-- 既存の行はバージョン1とする
+ALTER TABLE tasks ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+-- +migrate Down
+ALTER TABLE users DROP COLUMN version;
+ALTER TABLE tasks DROP COLUMN version;
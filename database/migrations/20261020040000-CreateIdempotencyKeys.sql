@@ -0,0 +1,20 @@
+
+-- +migrate Up
+-- ログインしていないリクエストのuser_idは空文字列とするので外部キーを付けない
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id VARCHAR(128) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    -- 処理中の場合は0
+    status_code INT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    etag VARCHAR(64) NOT NULL DEFAULT '',
+    body MEDIUMBLOB,
+    expires_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6),
+    updated_at DATETIME(6),
+    PRIMARY KEY (user_id, idempotency_key)
+);
+CREATE INDEX index_idempotency_keys_on_expires_at ON idempotency_keys (expires_at);
+-- +migrate Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,5 @@
+-- +migrate Up
+-- 送信中のReminderを他のサーバーが送らないように確保した期限を保存する
+ALTER TABLE reminders ADD COLUMN claimed_until DATETIME NULL;
+-- +migrate Down
+ALTER TABLE reminders DROP COLUMN claimed_until;
@@ -0,0 +1,5 @@
+-- +migrate Up
+-- 通知中の担当者の変更を他のサーバーが通知しないように確保した期限を保存する
+ALTER TABLE assignments ADD COLUMN claimed_until DATETIME NULL;
+-- +migrate Down
+ALTER TABLE assignments DROP COLUMN claimed_until;
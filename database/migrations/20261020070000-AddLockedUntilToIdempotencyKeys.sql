@@ -0,0 +1,5 @@
+-- +migrate Up
+-- 処理中のままサーバーが停止したキーを他のリクエストが引き継げるように確保した期限を保存する
+ALTER TABLE idempotency_keys ADD COLUMN locked_until DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+-- +migrate Down
+ALTER TABLE idempotency_keys DROP COLUMN locked_until;
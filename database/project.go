@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProjectRepository の具体的な実装
+type ProjectRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewProjectRepository(db *DB, logger *slog.Logger) *ProjectRepository {
+	return &ProjectRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+// projectCount はProjectごとのTaskの件数の集計結果である
+type projectCount struct {
+	ProjectID      string
+	TaskCount      int
+	CompletedCount int
+}
+
+func (repo *ProjectRepository) Create(ctx context.Context, p *entity.Project) (err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	p.NewID()
+
+	err = traceQuery(ctx, repo.logger, "INSERT", "projects", func() error {
+		return tx.Create(p).Error
+	})
+	return
+}
+
+func (repo *ProjectRepository) FindByID(ctx context.Context, id, uid string) (project *entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	project = &entity.Project{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return db.Where("id = ?", id).Where("user_id = ?", uid).First(project).Error
+	})
+	if err != nil {
+		return
+	}
+	err = repo.count(ctx, db, []*entity.Project{project})
+	return
+}
+
+func (repo *ProjectRepository) Find(ctx context.Context, id string) (project *entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.Find")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	project = &entity.Project{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return db.Where("id = ?", id).First(project).Error
+	})
+	if err != nil {
+		return
+	}
+	err = repo.count(ctx, db, []*entity.Project{project})
+	return
+}
+
+func (repo *ProjectRepository) FindByIDs(ctx context.Context, ids []string) (projects []*entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.FindByIDs")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	projects = []*entity.Project{}
+	if len(ids) == 0 {
+		return
+	}
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return db.Where("id IN ?", ids).Order("created_at").Order("id").Find(&projects).Error
+	})
+	if err != nil {
+		return
+	}
+	err = repo.count(ctx, db, projects)
+	return
+}
+
+func (repo *ProjectRepository) FindByUser(ctx context.Context, uid string) (projects []*entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.FindByUser")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	projects = []*entity.Project{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return db.Where("user_id = ?", uid).Order("created_at").Order("id").Find(&projects).Error
+	})
+	if err != nil {
+		return
+	}
+	err = repo.count(ctx, db, projects)
+	return
+}
+
+// count はprojectsのTaskの件数と完了したTaskの件数を集計する
+// ProjectのTaskはProjectの所有者のものなので，共有されたProjectも所有者に関わらず集計する
+func (repo *ProjectRepository) count(ctx context.Context, db *gorm.DB, projects []*entity.Project) error {
+	if len(projects) == 0 {
+		return nil
+	}
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		ids[i] = p.ID.String()
+	}
+
+	counts := []projectCount{}
+	err := traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Model(&entity.Task{}).
+			Select("project_id, COUNT(*) AS task_count, SUM(CASE WHEN is_completed THEN 1 ELSE 0 END) AS completed_count").
+			Where("project_id IN ?", ids).
+			Group("project_id").Scan(&counts).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	byID := map[string]projectCount{}
+	for _, c := range counts {
+		byID[c.ProjectID] = c
+	}
+	for _, p := range projects {
+		c := byID[p.ID.String()]
+		p.TaskCount, p.CompletedCount = c.TaskCount, c.CompletedCount
+	}
+	return nil
+}
+
+func (repo *ProjectRepository) Update(ctx context.Context, p *entity.Project) (err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するProjectがない場合を弾く
+	project := &entity.Project{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return tx.Where("id = ?", p.ID).Where("user_id = ?", p.UserID).First(project).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "UPDATE", "projects", func() error {
+		return tx.Omit("created_at").Save(p).Error
+	})
+	if err != nil {
+		return
+	}
+	err = repo.count(ctx, tx, []*entity.Project{p})
+	return
+}
+
+func (repo *ProjectRepository) Delete(ctx context.Context, id, uid string, deleteTasks bool) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "ProjectRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するProjectがない場合を弾く
+	project := &entity.Project{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "projects", func() error {
+		return tx.Where("id = ?", id).Where("user_id = ?", uid).First(project).Error
+	})
+	if err != nil {
+		return
+	}
+
+	if deleteTasks {
+		// parent_idの外部キーで一緒に削除されるサブタスクはProjectの外にあっても対象にする
+		ids := []string{}
+		err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+			return tx.Model(&entity.Task{}).Where("project_id = ?", id).Pluck("id", &ids).Error
+		})
+		if err != nil {
+			return
+		}
+		ids, err = withSubtasks(ctx, tx, repo.logger, ids)
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+			return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Assignee").Where("id IN ?", ids).Find(&tasks).Error
+		})
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "DELETE", "task_tags", func() error {
+			return tx.Where("task_id IN ?", ids).Delete(&taskTag{}).Error
+		})
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "DELETE", "reminders", func() error {
+			return tx.Where("task_id IN ?", ids).Delete(&entity.Reminder{}).Error
+		})
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "DELETE", "shares", func() error {
+			return tx.Where("resource_type = ?", entity.ShareTask).Where("resource_id IN ?", ids).Delete(&entity.Share{}).Error
+		})
+		if err != nil {
+			return
+		}
+		err = discardAttachments(ctx, tx, repo.logger, "task_id IN ?", ids)
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "DELETE", "tasks", func() error {
+			return tx.Where("id IN ?", ids).Delete(&entity.Task{}).Error
+		})
+	} else {
+		// インボックスに移動する
+		err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+			return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Assignee").Where("project_id = ?", id).Find(&tasks).Error
+		})
+		if err != nil {
+			return
+		}
+		err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+			return tx.Model(&entity.Task{}).Where("project_id = ?", id).
+				Updates(map[string]interface{}{"project_id": nil, "version": incrementVersion}).Error
+		})
+	}
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "shares", func() error {
+		return tx.Where("resource_type = ?", entity.ShareProject).Where("resource_id = ?", id).Delete(&entity.Share{}).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "projects", func() error {
+		return tx.Where("id = ?", id).Where("user_id = ?", uid).Delete(&entity.Project{}).Error
+	})
+	return
+}
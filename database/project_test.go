@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidPA1 = "3e0f7c4b-5a6d-4e8f-9a2b-3c4d5e6f7081"
+	uuidPB1 = "4f1a8d5c-6b7e-4f9a-8b3c-4d5e6f708192"
+)
+
+var (
+	projectA1 = *entity.NewProject(uuidPA1, "work", uuidUA)
+	projectB1 = *entity.NewProject(uuidPB1, "work", uuidUB)
+)
+
+func TestProjectRepository_FindByUser(t *testing.T) {
+
+	project, task := prepareProjectT(t)
+
+	addProjectData(t, project, []entity.Project{projectA1, projectB1})
+	addTaskData(t, task, []entity.Task{taskA1, taskA2, taskB1})
+	moveTask(t, project, uuidTA1, uuidPA1, true)
+	moveTask(t, project, uuidTA2, uuidPA1, false)
+
+	gotProjects, err := project.FindByUser(context.Background(), uuidUA)
+	errorCompare(t, err, nil)
+
+	want := projectA1
+	want.TaskCount = 2
+	want.CompletedCount = 1
+	cmpopt := cmpopts.IgnoreFields(entity.Project{}, "CreatedAt", "UpdatedAt")
+	if diff := cmp.Diff([]*entity.Project{&want}, gotProjects, cmpopt); diff != "" {
+		t.Errorf("Data (-want +got) =\n%s\n", diff)
+	}
+
+	// 他のユーザーのProjectは取得できない
+	_, err = project.FindByID(context.Background(), uuidPB1, uuidUA)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func TestProjectRepository_Delete(t *testing.T) {
+
+	project, task := prepareProjectT(t)
+
+	tests := []struct {
+		name        string
+		deleteTasks bool
+		wantTasks   int
+		wantChanged []string
+	}{
+		{
+			name:        "Taskをインボックスに移動して削除できる",
+			deleteTasks: false,
+			wantTasks:   1,
+			wantChanged: []string{uuidTA1},
+		},
+		{
+			name:        "Taskとともに削除できる",
+			deleteTasks: true,
+			wantTasks:   0,
+			wantChanged: []string{uuidTA2, uuidTA1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addProjectData(t, project, []entity.Project{projectA1})
+			addTaskData(t, task, []entity.Task{taskA1, taskA2})
+			moveTask(t, project, uuidTA1, uuidPA1, false)
+			// Projectの外にあるサブタスクも親と一緒に削除される
+			err := project.db.Model(&entity.Task{}).Where("id = ?", uuidTA2).Update("parent_id", uuidTA1).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 他のユーザーのProjectは削除できない
+			_, err = project.Delete(context.Background(), uuidPA1, uuidUB, tt.deleteTasks)
+			errorCompare(t, err, entity.ErrRecordNotFound)
+
+			changed, err := project.Delete(context.Background(), uuidPA1, uuidUA, tt.deleteTasks)
+			errorCompare(t, err, nil)
+			gotChanged := []string{}
+			for _, c := range changed {
+				if c.ProjectID.IsNull() && c.ParentID.IsNull() {
+					t.Errorf("Data got = %v", c)
+				}
+				gotChanged = append(gotChanged, c.ID.String())
+			}
+			sort.Strings(gotChanged)
+			if diff := cmp.Diff(tt.wantChanged, gotChanged); diff != "" {
+				t.Errorf("Data (-want +got) =\n%s\n", diff)
+			}
+
+			gotTasks, err := task.FindByUser(context.Background(), uuidUA, repository.TaskQuery{Inbox: true})
+			errorCompare(t, err, nil)
+			if len(gotTasks) != tt.wantTasks {
+				t.Errorf("Data got = %v", gotTasks)
+			}
+		})
+	}
+}
+
+// moveTask はTaskをProjectに移動する
+func moveTask(t *testing.T, repo *ProjectRepository, tid, pid string, comp bool) {
+	t.Helper()
+
+	err := repo.db.Model(&entity.Task{}).Where("id = ?", tid).
+		Updates(map[string]interface{}{"project_id": pid, "is_completed": comp}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addProjectData(t *testing.T, repo *ProjectRepository, projects []entity.Project) {
+	t.Helper()
+
+	// databaseを初期化する
+	db := repo.db
+	err := db.Exec("SET FOREIGN_KEY_CHECKS = 0").Error
+	err = db.Exec("TRUNCATE TABLE projects").Error
+	err = db.Exec("SET FOREIGN_KEY_CHECKS = 1").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, project := range projects {
+		err = db.Create(&project).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+func prepareProjectT(t *testing.T) (project *ProjectRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	project = NewProjectRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReminderRepository の具体的な実装
+type ReminderRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewReminderRepository(db *DB, logger *slog.Logger) *ReminderRepository {
+	return &ReminderRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *ReminderRepository) Create(ctx context.Context, r *entity.Reminder) (err error) {
+	ctx, span := startSpan(ctx, "ReminderRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のユーザーのTaskにReminderを付けられないようにする
+	task := &entity.Task{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return tx.Where("id = ?", r.TaskID).Where("user_id = ?", r.UserID).First(task).Error
+	})
+	if err != nil {
+		return
+	}
+
+	r.NewID()
+	r.SentAt, r.Attempts, r.ClaimedUntil = nil, 0, nil
+	err = traceQuery(ctx, repo.logger, "INSERT", "reminders", func() error {
+		return tx.Create(r).Error
+	})
+	return
+}
+
+func (repo *ReminderRepository) FindByTask(ctx context.Context, tid, uid string) (reminders []*entity.Reminder, err error) {
+	ctx, span := startSpan(ctx, "ReminderRepository.FindByTask")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	reminders = []*entity.Reminder{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "reminders", func() error {
+		return db.Where("task_id = ?", tid).Where("user_id = ?", uid).Order("remind_at").Order("id").Find(&reminders).Error
+	})
+	return
+}
+
+func (repo *ReminderRepository) Reschedule(ctx context.Context, uid string, reminders []*entity.Reminder) (err error) {
+	ctx, span := startSpan(ctx, "ReminderRepository.Reschedule")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	for _, r := range reminders {
+		err = traceQuery(ctx, repo.logger, "UPDATE", "reminders", func() error {
+			return tx.Model(&entity.Reminder{}).
+				Where("id = ?", r.ID).Where("user_id = ?", uid).Where("sent_at IS NULL").
+				Update("remind_at", r.RemindAt).Error
+		})
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (repo *ReminderRepository) Delete(ctx context.Context, id, tid, uid string) (err error) {
+	ctx, span := startSpan(ctx, "ReminderRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するReminderがない場合を弾く
+	reminder := &entity.Reminder{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "reminders", func() error {
+		return tx.Where("id = ?", id).Where("task_id = ?", tid).Where("user_id = ?", uid).First(reminder).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "reminders", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.Reminder{}).Error
+	})
+	return
+}
+
+// Dispatch はsendで外部に通知するので，タイムアウトはrepo.timeoutではなく呼び出し元のctxに従う
+// 送信中にトランザクションを保持しないように，先にReminderを確保してコミットしてから送り，結果はReminderごとにコミットする
+func (repo *ReminderRepository) Dispatch(ctx context.Context, now time.Time, limit int, send func(ctx context.Context, r *entity.Reminder) error) (sent int, err error) {
+	ctx, span := startSpan(ctx, "ReminderRepository.Dispatch")
+	defer func() { endSpan(span, err) }()
+
+	reminders, err := repo.claim(ctx, now, limit)
+	if err != nil {
+		return
+	}
+	for _, r := range reminders {
+		if err = ctx.Err(); err != nil {
+			// 送らなかったReminderは確保した期限が過ぎてから再び送る
+			return
+		}
+		sendErr := send(ctx, r)
+		if sendErr != nil {
+			repo.logger.WarnContext(ctx, "failed to send reminder",
+				slog.String("reminder_id", r.ID.String()),
+				slog.Int("attempts", r.Attempts+1),
+				slog.Any("error", sendErr),
+			)
+		}
+		err = repo.finish(ctx, r, now, sendErr == nil)
+		if err != nil {
+			return
+		}
+		r.Attempts++
+		if sendErr == nil {
+			r.SentAt = &now
+			sent++
+		}
+	}
+	return
+}
+
+// claim は送信するReminderをロックして確保し，すぐにコミットする
+// 確保したReminderはClaimedUntilまで他のDispatchでは取得しない
+func (repo *ReminderRepository) claim(ctx context.Context, now time.Time, limit int) (reminders []*entity.Reminder, err error) {
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のサーバーがロックしているReminderは待たずに飛ばす
+	reminders = []*entity.Reminder{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "reminders", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").Where("remind_at <= ?", now).Where("attempts < ?", entity.MaxReminderAttempts).
+			Where("claimed_until IS NULL OR claimed_until <= ?", now).
+			Order("remind_at").Order("id").Limit(limit).Find(&reminders).Error
+	})
+	if err != nil || len(reminders) == 0 {
+		return
+	}
+
+	ids := make([]string, len(reminders))
+	for i, r := range reminders {
+		ids[i] = r.ID.String()
+	}
+	until := claimUntil(ctx, now)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "reminders", func() error {
+		return tx.Model(&entity.Reminder{}).Where("id IN ?", ids).Update("claimed_until", until).Error
+	})
+	return
+}
+
+// finish はclaimで確保したReminderの送信の結果を保存し，確保を解除する
+// 送った後に呼び出し元のctxが期限を過ぎても結果を保存するように，repo.timeoutに従う
+func (repo *ReminderRepository) finish(ctx context.Context, r *entity.Reminder, now time.Time, sent bool) (err error) {
+	ctx, cancel := withTimeout(context.WithoutCancel(ctx), repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "claimed_until": nil}
+	if sent {
+		updates["sent_at"] = now
+	}
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "UPDATE", "reminders", func() error {
+		return db.Model(&entity.Reminder{}).Where("id = ?", r.ID).Updates(updates).Error
+	})
+	return
+}
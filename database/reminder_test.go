@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestReminderRepository_Create(t *testing.T) {
+
+	reminder, task := prepareReminderT(t)
+
+	addReminderData(t, reminder)
+	addTaskData(t, task, []entity.Task{taskA1, taskB1})
+
+	// 他のユーザーのTaskにはReminderを付けられない
+	r := &entity.Reminder{TaskID: entity.NewNullString(uuidTB1), UserID: entity.NewNullString(uuidUA), RemindAt: time.Now()}
+	err := reminder.Create(context.Background(), r)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	r = &entity.Reminder{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uuidUA), RemindAt: time.Now()}
+	err = reminder.Create(context.Background(), r)
+	errorCompare(t, err, nil)
+
+	got, err := reminder.FindByTask(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 1 || got[0].ID != r.ID {
+		t.Errorf("Data got = %v", got)
+	}
+
+	// Taskを削除するとReminderも削除する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	got, err = reminder.FindByTask(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Data got = %v", got)
+	}
+}
+
+func TestReminderRepository_Dispatch(t *testing.T) {
+
+	reminder, task := prepareReminderT(t)
+
+	addReminderData(t, reminder)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	now := time.Now().Truncate(time.Second)
+	due := &entity.Reminder{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uuidUA), RemindAt: now.Add(-time.Minute)}
+	future := &entity.Reminder{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uuidUA), RemindAt: now.Add(time.Hour)}
+	for _, r := range []*entity.Reminder{due, future} {
+		err := reminder.Create(context.Background(), r)
+		errorCompare(t, err, nil)
+	}
+
+	// 送信に失敗した場合は送信済みにしない
+	errSend := errors.New("send failed")
+	sent, err := reminder.Dispatch(context.Background(), now, 10, func(ctx context.Context, r *entity.Reminder) error {
+		return errSend
+	})
+	errorCompare(t, err, nil)
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+
+	// 複数のサーバーで同時に実行しても一度だけ送る
+	var mu sync.Mutex
+	sentIDs := []string{}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := reminder.Dispatch(context.Background(), now, 10, func(ctx context.Context, r *entity.Reminder) error {
+				mu.Lock()
+				defer mu.Unlock()
+				sentIDs = append(sentIDs, r.ID.String())
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(sentIDs) != 1 || sentIDs[0] != due.ID.String() {
+		t.Errorf("sent reminders = %v, want [%s]", sentIDs, due.ID.String())
+	}
+
+	got, err := reminder.FindByTask(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 2 || got[0].SentAt == nil || got[0].Attempts != 2 || got[1].SentAt != nil {
+		t.Errorf("Data got = %v", got)
+	}
+}
+
+func TestReminderRepository_Dispatch_Claim(t *testing.T) {
+
+	reminder, task := prepareReminderT(t)
+
+	addReminderData(t, reminder)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	now := time.Now().Truncate(time.Second)
+	due := &entity.Reminder{TaskID: entity.NewNullString(uuidTA1), UserID: entity.NewNullString(uuidUA), RemindAt: now.Add(-time.Minute)}
+	err := reminder.Create(context.Background(), due)
+	errorCompare(t, err, nil)
+
+	// 送信中のReminderは他のDispatchでは取得せず，送った後にctxがキャンセルされても送信済みにする
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sent, err := reminder.Dispatch(ctx, now, 10, func(ctx context.Context, r *entity.Reminder) error {
+		inner, err := reminder.Dispatch(context.Background(), now, 10, func(ctx context.Context, r *entity.Reminder) error {
+			t.Errorf("unexpected send: %v", r)
+			return nil
+		})
+		errorCompare(t, err, nil)
+		if inner != 0 {
+			t.Errorf("inner sent = %d, want 0", inner)
+		}
+		cancel()
+		return nil
+	})
+	errorCompare(t, err, nil)
+	if sent != 1 {
+		t.Errorf("sent = %d, want 1", sent)
+	}
+
+	got, err := reminder.FindByTask(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 1 || got[0].SentAt == nil || got[0].ClaimedUntil != nil {
+		t.Errorf("Data got = %v", got)
+	}
+}
+
+func addReminderData(t *testing.T, repo *ReminderRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	err := repo.db.Exec("TRUNCATE TABLE reminders").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareReminderT(t *testing.T) (reminder *ReminderRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	reminder = NewReminderRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
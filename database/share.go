@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+)
+
+// ShareRepository の具体的な実装
+type ShareRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewShareRepository(db *DB, logger *slog.Logger) *ShareRepository {
+	return &ShareRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *ShareRepository) Create(ctx context.Context, s *entity.Share) (err error) {
+	ctx, span := startSpan(ctx, "ShareRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	s.NewID()
+	s.AcceptedAt = nil
+	err = traceQuery(ctx, repo.logger, "INSERT", "shares", func() error {
+		return tx.Create(s).Error
+	})
+	return
+}
+
+func (repo *ShareRepository) FindByID(ctx context.Context, id string) (share *entity.Share, err error) {
+	ctx, span := startSpan(ctx, "ShareRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	share = &entity.Share{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "shares", func() error {
+		return db.Where("id = ?", id).First(share).Error
+	})
+	return
+}
+
+func (repo *ShareRepository) FindByUser(ctx context.Context, uid string) (shares []*entity.Share, err error) {
+	ctx, span := startSpan(ctx, "ShareRepository.FindByUser")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	shares = []*entity.Share{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "shares", func() error {
+		return db.Where("owner_id = ? OR user_id = ?", uid, uid).Order("created_at").Order("id").Find(&shares).Error
+	})
+	return
+}
+
+func (repo *ShareRepository) Accept(ctx context.Context, id, uid string, at time.Time) (err error) {
+	ctx, span := startSpan(ctx, "ShareRepository.Accept")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当する未承諾の招待がない場合を弾く
+	share := &entity.Share{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "shares", func() error {
+		return tx.Where("id = ?", id).Where("user_id = ?", uid).Where("accepted_at IS NULL").First(share).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "UPDATE", "shares", func() error {
+		return tx.Model(share).Update("accepted_at", at).Error
+	})
+	return
+}
+
+func (repo *ShareRepository) Delete(ctx context.Context, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "ShareRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するShareがない場合を弾く
+	share := &entity.Share{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "shares", func() error {
+		return tx.Where("id = ?", id).Where("owner_id = ? OR user_id = ?", uid, uid).First(share).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "shares", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.Share{}).Error
+	})
+	return
+}
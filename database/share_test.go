@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestShareRepository_Accept(t *testing.T) {
+
+	share, task := prepareShareT(t)
+
+	addShareData(t, share)
+	addTaskData(t, task, []entity.Task{taskA1, taskA2, taskB1})
+
+	s := &entity.Share{
+		ResourceType: entity.ShareTask,
+		ResourceID:   entity.NewNullString(uuidTA1),
+		OwnerID:      entity.NewNullString(uuidUA),
+		UserID:       entity.NewNullString(uuidUB),
+		Role:         entity.RoleViewer,
+	}
+	err := share.Create(context.Background(), s)
+	errorCompare(t, err, nil)
+
+	// 同じものを同じユーザーに二度は招待できない
+	err = share.Create(context.Background(), &entity.Share{
+		ResourceType: entity.ShareTask,
+		ResourceID:   entity.NewNullString(uuidTA1),
+		OwnerID:      entity.NewNullString(uuidUA),
+		UserID:       entity.NewNullString(uuidUB),
+		Role:         entity.RoleEditor,
+	})
+	if err == nil {
+		t.Errorf("Error got = nil, want duplicate entry")
+	}
+
+	// 招待は所有者と共有されたユーザーの両方から見える
+	for _, uid := range []string{uuidUA, uuidUB} {
+		got, err := share.FindByUser(context.Background(), uid)
+		errorCompare(t, err, nil)
+		if len(got) != 1 || got[0].ID != s.ID || got[0].IsAccepted() {
+			t.Errorf("Data got = %v", got)
+		}
+	}
+
+	// 所有者は承諾できない
+	at := time.Now().Truncate(time.Second)
+	err = share.Accept(context.Background(), s.ID.String(), uuidUA, at)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	err = share.Accept(context.Background(), s.ID.String(), uuidUB, at)
+	errorCompare(t, err, nil)
+	got, err := share.FindByID(context.Background(), s.ID.String())
+	errorCompare(t, err, nil)
+	if !got.IsAccepted() || !got.AcceptedAt.Equal(at) {
+		t.Errorf("Data got = %v", got)
+	}
+
+	// 承諾済みの招待は再度承諾できない
+	err = share.Accept(context.Background(), s.ID.String(), uuidUB, at)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	// 共有されたTaskは共有されたユーザーの一覧に含まれる
+	tasks, err := task.FindByUser(context.Background(), uuidUB, repository.TaskQuery{SharedTaskIDs: []string{uuidTA1}})
+	errorCompare(t, err, nil)
+	if len(tasks) != 2 {
+		t.Errorf("Data got = %v", tasks)
+	}
+
+	// Taskを削除すると共有も削除する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	_, err = share.FindByID(context.Background(), s.ID.String())
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func TestShareRepository_Delete(t *testing.T) {
+
+	share, task := prepareShareT(t)
+
+	addShareData(t, share)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	s := &entity.Share{
+		ResourceType: entity.ShareTask,
+		ResourceID:   entity.NewNullString(uuidTA1),
+		OwnerID:      entity.NewNullString(uuidUA),
+		UserID:       entity.NewNullString(uuidUB),
+		Role:         entity.RoleEditor,
+	}
+	err := share.Create(context.Background(), s)
+	errorCompare(t, err, nil)
+
+	// 所有者と共有されたユーザー以外は削除できない
+	err = share.Delete(context.Background(), s.ID.String(), uuidUZ)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	// 共有されたユーザーは共有から抜けられる
+	err = share.Delete(context.Background(), s.ID.String(), uuidUB)
+	errorCompare(t, err, nil)
+	got, err := share.FindByUser(context.Background(), uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Data got = %v", got)
+	}
+}
+
+func addShareData(t *testing.T, repo *ShareRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	err := repo.db.Exec("TRUNCATE TABLE shares").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareShareT(t *testing.T) (share *ShareRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	share = NewShareRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
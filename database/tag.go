@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepository の具体的な実装
+type TagRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewTagRepository(db *DB, logger *slog.Logger) *TagRepository {
+	return &TagRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+// taskTag はtask_tagsテーブルの行である
+type taskTag struct {
+	TaskID string
+	TagID  string
+}
+
+func (taskTag) TableName() string {
+	return "task_tags"
+}
+
+func (repo *TagRepository) Create(ctx context.Context, t *entity.Tag) (err error) {
+	ctx, span := startSpan(ctx, "TagRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	t.NewID()
+
+	err = traceQuery(ctx, repo.logger, "INSERT", "tags", func() error {
+		return tx.Create(t).Error
+	})
+	return
+}
+
+func (repo *TagRepository) FindByID(ctx context.Context, id, uid string) (tag *entity.Tag, err error) {
+	ctx, span := startSpan(ctx, "TagRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	tag = &entity.Tag{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tags", func() error {
+		return db.Where("id = ?", id).Where("user_id = ?", uid).First(tag).Error
+	})
+	return
+}
+
+func (repo *TagRepository) FindByUser(ctx context.Context, uid string) (tags []*entity.Tag, err error) {
+	ctx, span := startSpan(ctx, "TagRepository.FindByUser")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	tags = []*entity.Tag{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tags", func() error {
+		return db.Where("user_id = ?", uid).Order("name").Find(&tags).Error
+	})
+	return
+}
+
+func (repo *TagRepository) Update(ctx context.Context, t *entity.Tag) (err error) {
+	ctx, span := startSpan(ctx, "TagRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するタグがない場合を弾く
+	err = repo.exists(ctx, tx, "tags", t.ID.String(), t.UserID.String())
+	if err != nil {
+		return
+	}
+	// Tagの名前や色はTaskのレスポンスに含まれるので，付いているTaskのバージョンを上げる
+	err = touchTasks(ctx, tx, repo.logger, "id IN (?)", tx.Model(&taskTag{}).Select("task_id").Where("tag_id = ?", t.ID))
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tags", func() error {
+		return tx.Omit("created_at").Save(t).Error
+	})
+	return
+}
+
+func (repo *TagRepository) Delete(ctx context.Context, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するタグがない場合を弾く
+	err = repo.exists(ctx, tx, "tags", id, uid)
+	if err != nil {
+		return
+	}
+	// 外す前に，付いていたTaskのバージョンを上げる
+	err = touchTasks(ctx, tx, repo.logger, "id IN (?)", tx.Model(&taskTag{}).Select("task_id").Where("tag_id = ?", id))
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "task_tags", func() error {
+		return tx.Where("tag_id = ?", id).Delete(&taskTag{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "tags", func() error {
+		return tx.Where("id = ?", id).Where("user_id = ?", uid).Delete(&entity.Tag{}).Error
+	})
+	return
+}
+
+func (repo *TagRepository) Attach(ctx context.Context, tid, tagID, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagRepository.Attach")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のユーザーのTaskやTagを弾く
+	err = repo.exists(ctx, tx, "tasks", tid, uid)
+	if err != nil {
+		return
+	}
+	err = repo.exists(ctx, tx, "tags", tagID, uid)
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "INSERT", "task_tags", func() error {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&taskTag{TaskID: tid, TagID: tagID}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = touchTasks(ctx, tx, repo.logger, "id = ?", tid)
+	return
+}
+
+func (repo *TagRepository) Detach(ctx context.Context, tid, tagID, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagRepository.Detach")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 他のユーザーのTaskやTagを弾く
+	err = repo.exists(ctx, tx, "tasks", tid, uid)
+	if err != nil {
+		return
+	}
+	err = repo.exists(ctx, tx, "tags", tagID, uid)
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "DELETE", "task_tags", func() error {
+		return tx.Where("task_id = ?", tid).Where("tag_id = ?", tagID).Delete(&taskTag{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = touchTasks(ctx, tx, repo.logger, "id = ?", tid)
+	return
+}
+
+// exists はtableにuidが所有するidの行がなければErrRecordNotFoundを返す
+func (repo *TagRepository) exists(ctx context.Context, tx *gorm.DB, table, id, uid string) error {
+	var count int64
+	err := traceQuery(ctx, repo.logger, "SELECT", table, func() error {
+		return tx.Table(table).Where("id = ?", id).Where("user_id = ?", uid).Count(&count).Error
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return entity.ErrRecordNotFound
+	}
+	return nil
+}
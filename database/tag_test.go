@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidGA1 = "0b7c4f1e-2d3a-4b5c-8d9e-0f1a2b3c4d5e"
+	uuidGA2 = "1c8d5a2f-3e4b-4c6d-9e0f-1a2b3c4d5e6f"
+	uuidGB1 = "2d9e6b3a-4f5c-4d7e-8f1a-2b3c4d5e6f70"
+)
+
+var (
+	tagA1 = *entity.NewTag(uuidGA1, "work", "red", uuidUA)
+	tagA2 = *entity.NewTag(uuidGA2, "home", "", uuidUA)
+	tagB1 = *entity.NewTag(uuidGB1, "work", "", uuidUB)
+)
+
+func TestTagRepository_Create(t *testing.T) {
+
+	tag, _ := prepareTagT(t)
+
+	tests := []struct {
+		name        string
+		tag         *entity.Tag
+		wantTag     *entity.Tag
+		wantErr     error
+		prepareTags []entity.Tag
+	}{
+		{
+			name:        "正しくタグを作成できる",
+			tag:         entity.NewTag("", "work", "red", uuidUA),
+			wantTag:     entity.NewTag("any id", "work", "red", uuidUA),
+			wantErr:     nil,
+			prepareTags: []entity.Tag{tagB1},
+		},
+		{
+			name:        "同じユーザーの同じ名前のタグはErrMySQL",
+			tag:         entity.NewTag("", "work", "", uuidUA),
+			wantTag:     nil,
+			wantErr:     entity.NewErrMySQL(0x426, "Duplicate entry '98457fea-708f-bb8e-3e5e-fe1b43f1acad-work' for key 'tags.user_id'"),
+			prepareTags: []entity.Tag{tagA1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addTagData(t, tag, tt.prepareTags)
+
+			err := tag.Create(context.Background(), tt.tag)
+
+			if errorCompare(t, err, tt.wantErr) {
+				t.Errorf("Data got = %s", tt.tag)
+			}
+			if tt.wantErr == nil {
+				cmpopt := cmpopts.IgnoreFields(entity.Tag{}, "ID", "CreatedAt", "UpdatedAt")
+				if diff := cmp.Diff(tt.wantTag, tt.tag, cmpopt); diff != "" {
+					t.Errorf("Data (-want +got) =\n%s\n", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTagRepository_FindByUser(t *testing.T) {
+
+	tag, _ := prepareTagT(t)
+
+	addTagData(t, tag, []entity.Tag{tagA1, tagA2, tagB1})
+
+	gotTags, err := tag.FindByUser(context.Background(), uuidUA)
+	errorCompare(t, err, nil)
+
+	// 名前順に並ぶ
+	cmpopt := cmpopts.IgnoreFields(entity.Tag{}, "CreatedAt", "UpdatedAt")
+	if diff := cmp.Diff([]*entity.Tag{&tagA2, &tagA1}, gotTags, cmpopt); diff != "" {
+		t.Errorf("Data (-want +got) =\n%s\n", diff)
+	}
+}
+
+func TestTagRepository_Attach(t *testing.T) {
+
+	tag, task := prepareTagT(t)
+
+	tests := []struct {
+		name     string
+		tid      string
+		tagID    string
+		uid      string
+		wantErr  error
+		wantTags []string
+	}{
+		{
+			name:     "自分のタスクに自分のタグを付けられる",
+			tid:      uuidTA1,
+			tagID:    uuidGA1,
+			uid:      uuidUA,
+			wantErr:  nil,
+			wantTags: []string{uuidGA1},
+		},
+		{
+			name:     "他のユーザーのタグは付けられない",
+			tid:      uuidTA1,
+			tagID:    uuidGB1,
+			uid:      uuidUA,
+			wantErr:  entity.ErrRecordNotFound,
+			wantTags: []string{},
+		},
+		{
+			name:     "他のユーザーのタスクには付けられない",
+			tid:      uuidTB1,
+			tagID:    uuidGA1,
+			uid:      uuidUA,
+			wantErr:  entity.ErrRecordNotFound,
+			wantTags: []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addTaskData(t, task, []entity.Task{taskA1, taskB1})
+			addTagData(t, tag, []entity.Tag{tagA1, tagB1})
+
+			err := tag.Attach(context.Background(), tt.tid, tt.tagID, tt.uid)
+			errorCompare(t, err, tt.wantErr)
+
+			// 同じタグを2回付けてもエラーにならない
+			if tt.wantErr == nil {
+				err = tag.Attach(context.Background(), tt.tid, tt.tagID, tt.uid)
+				errorCompare(t, err, nil)
+			}
+
+			gotTask, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+			errorCompare(t, err, nil)
+			gotTags := []string{}
+			for _, tag := range gotTask.Tags {
+				gotTags = append(gotTags, tag.ID.String())
+			}
+			if diff := cmp.Diff(tt.wantTags, gotTags); diff != "" {
+				t.Errorf("Tags (-want +got) =\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTagRepository_Delete(t *testing.T) {
+
+	tag, task := prepareTagT(t)
+
+	addTaskData(t, task, []entity.Task{taskA1})
+	addTagData(t, tag, []entity.Tag{tagA1})
+	err := tag.Attach(context.Background(), uuidTA1, uuidGA1, uuidUA)
+	errorCompare(t, err, nil)
+
+	attached, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+
+	// タグの名前を変えると付いているタスクのバージョンが上がる
+	renamed := tagA1
+	renamed.Name = entity.NewNullString("office")
+	err = tag.Update(context.Background(), &renamed)
+	errorCompare(t, err, nil)
+	gotTask, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if gotTask.Version != attached.Version+1 {
+		t.Errorf("Version got = %d, want %d", gotTask.Version, attached.Version+1)
+	}
+
+	// 他のユーザーのタグは削除できない
+	err = tag.Delete(context.Background(), uuidGA1, uuidUB)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	// 削除したタグはタスクから外れ，タスクのバージョンが上がる
+	err = tag.Delete(context.Background(), uuidGA1, uuidUA)
+	errorCompare(t, err, nil)
+	gotTasks, err := task.FindByUser(context.Background(), uuidUA, repository.TaskQuery{TagIDs: []string{uuidGA1}})
+	errorCompare(t, err, nil)
+	if len(gotTasks) != 0 {
+		t.Errorf("Data got = %v", gotTasks)
+	}
+	gotTask, err = task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if gotTask.Version != attached.Version+2 {
+		t.Errorf("Version got = %d, want %d", gotTask.Version, attached.Version+2)
+	}
+}
+
+func addTagData(t *testing.T, repo *TagRepository, tags []entity.Tag) {
+	t.Helper()
+
+	// databaseを初期化する
+	db := repo.db
+	err := db.Exec("TRUNCATE TABLE task_tags").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec("SET FOREIGN_KEY_CHECKS = 0").Error
+	err = db.Exec("TRUNCATE TABLE tags").Error
+	err = db.Exec("SET FOREIGN_KEY_CHECKS = 1").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range tags {
+		err = db.Create(&tag).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return
+}
+
+func prepareTagT(t *testing.T) (tag *TagRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	tag = NewTagRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
@@ -1,111 +1,575 @@
 package database
 
 import (
-	"github.com/go-sql-driver/mysql"
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
-	"github.com/jinzhu/gorm"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// incrementVersion はTaskやUserを変更する際にバージョンを1増やす式である
+var incrementVersion = gorm.Expr("version + 1")
+
 // TaskRepository の具体的な実装
 type TaskRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
 }
 
-func NewTaskRepository(db *DB) *TaskRepository {
-	return &TaskRepository{db: db.Connect()}
+func NewTaskRepository(db *DB, logger *slog.Logger) *TaskRepository {
+	return &TaskRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
 }
 
-func (repo *TaskRepository) Create(t *entity.Task) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr)
-		}
-	}()
+func (repo *TaskRepository) Create(ctx context.Context, t *entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
 
-	tx := repo.db.Begin()
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	t.NewID()
+	t.Version = 1
 
-	err = tx.Create(t).Error
+	// 同じ日のTask(サブタスクの場合は同じ親のTask)の末尾に並べる
+	if t.Position == "" {
+		t.Position, err = repo.lastPosition(ctx, tx, t)
+		if err != nil {
+			return
+		}
+	}
+
+	err = traceQuery(ctx, repo.logger, "INSERT", "tasks", func() error {
+		// Tagの付け外しはTagRepositoryで行う
+		return tx.Omit(clause.Associations).Create(t).Error
+	})
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (repo *TaskRepository) FindByID(tid, uid string) (task *entity.Task, err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr) //TODO:testなし
+// touchTasks はTagの付け外しなどTaskの行以外の変更をTaskのバージョンに反映する
+func touchTasks(ctx context.Context, tx *gorm.DB, logger *slog.Logger, query string, args ...interface{}) error {
+	return traceQuery(ctx, logger, "UPDATE", "tasks", func() error {
+		return tx.Model(&entity.Task{}).Where(query, args...).Update("version", incrementVersion).Error
+	})
+}
+
+// withSubtasks はidsのTaskとその子孫のTaskのidを返す．子孫はparent_idの外部キーで一緒に削除される
+func withSubtasks(ctx context.Context, tx *gorm.DB, logger *slog.Logger, ids []string) ([]string, error) {
+	for parents := ids; len(parents) > 0; ids = append(ids, parents...) {
+		children := []string{}
+		err := traceQuery(ctx, logger, "SELECT", "tasks", func() error {
+			return tx.Model(&entity.Task{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error
+		})
+		if err != nil {
+			return nil, err
 		}
-		return
-	}()
+		parents = children
+	}
+	return ids, nil
+}
+
+// lastPosition はtの並ぶTaskの末尾のPositionを返す
+func (repo *TaskRepository) lastPosition(ctx context.Context, tx *gorm.DB, t *entity.Task) (string, error) {
+	var last sql.NullString
+	err := traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		db := tx.Model(&entity.Task{}).Where("user_id = ?", t.UserID)
+		if t.ParentID.IsNull() {
+			db = db.Where("deadline = ?", t.Deadline).Where("parent_id IS NULL")
+		} else {
+			db = db.Where("parent_id = ?", t.ParentID)
+		}
+		return db.Select("MAX(position)").Scan(&last).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return entity.RankBetween(last.String, "")
+}
+
+func (repo *TaskRepository) FindByID(ctx context.Context, tid, uid string) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }() //TODO:testなし
 
+	db := session(ctx, repo.db)
 	task = &entity.Task{}
-	err = repo.db.Where("id = ?", tid).Where("user_id = ?", uid).First(task).Error
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Preload("Tags").Preload("Assignee").Where("id = ?", tid).Where("user_id = ?", uid).First(task).Error
+	})
 	return
 }
 
-func (repo *TaskRepository) Update(t *entity.Task) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr) //TODO:testなし
+func (repo *TaskRepository) Find(ctx context.Context, tid string) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Find")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	task = &entity.Task{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Preload("Tags").Preload("Assignee").Where("id = ?", tid).First(task).Error
+	})
+	return
+}
+
+func (repo *TaskRepository) FindByUser(ctx context.Context, uid string, q repository.TaskQuery) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.FindByUser")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	// 共有されたTaskとProjectのTask，参加しているWorkspaceのTaskも取得する
+	owners, args := []string{"user_id = ?"}, []interface{}{uid}
+	if len(q.SharedTaskIDs) > 0 {
+		owners, args = append(owners, "id IN ?"), append(args, q.SharedTaskIDs)
+	}
+	if len(q.SharedProjectIDs) > 0 {
+		owners, args = append(owners, "project_id IN ?"), append(args, q.SharedProjectIDs)
+	}
+	if len(q.WorkspaceIDs) > 0 {
+		owners, args = append(owners, "workspace_id IN ?"), append(args, q.WorkspaceIDs)
+	}
+	db := session(ctx, repo.db).Where("("+strings.Join(owners, " OR ")+")", args...)
+	if !q.Date.IsNull() {
+		db = db.Where("deadline = ?", q.Date)
+	}
+	if !q.From.IsNull() && !q.To.IsNull() {
+		// 期間より前に始まる繰り返しのTaskも期間内に回がある可能性がある
+		db = db.Where("((deadline >= ? AND deadline <= ?) OR (recurrence IS NOT NULL AND is_completed = ? AND deadline < ?))",
+			q.From, q.To, false, q.From)
+	}
+	if !q.Before.IsNull() {
+		db = db.Where("deadline < ?", q.Before)
+	}
+	if q.Incomplete {
+		db = db.Where("is_completed = ?", false)
+	}
+	if q.ProjectID != "" {
+		db = db.Where("project_id = ?", q.ProjectID)
+	}
+	if q.Inbox {
+		db = db.Where("project_id IS NULL")
+	}
+	if q.WorkspaceID != "" {
+		db = db.Where("workspace_id = ?", q.WorkspaceID)
+	}
+	if q.AssigneeID != "" {
+		db = db.Where("assignee_id = ?", q.AssigneeID)
+	}
+	if q.ParentID != "" {
+		db = db.Where("parent_id = ?", q.ParentID)
+	} else if q.AssigneeID == "" {
+		db = db.Where("parent_id IS NULL")
+	}
+	if len(q.TagIDs) > 0 {
+		// 指定したすべてのTagが付いているTaskに絞り込む
+		db = db.Where("id IN (?)", repo.db.Model(&taskTag{}).
+			Select("task_id").Where("tag_id IN ?", q.TagIDs).
+			Group("task_id").Having("COUNT(DISTINCT tag_id) = ?", len(q.TagIDs)))
+	}
+	if q.Sort == repository.SortByPriority {
+		db = db.Order("priority DESC")
+	}
+	db = db.Order("deadline").Order("position").Order("created_at")
+
+	tasks = []*entity.Task{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Preload("Tags").Preload("Assignee").Find(&tasks).Error
+	})
+	return
+}
+
+// searchHit はTaskの検索で一致したTaskのIDと一致度である
+type searchHit struct {
+	ID    string
+	Score float64
+}
+
+// Search はMySQLではtitleとcontentのFULLTEXTインデックスをBOOLEAN MODEで，それ以外ではLIKEで検索する
+func (repo *TaskRepository) Search(ctx context.Context, uid string, terms []entity.SearchTerm, limit int) (results []*entity.SearchResult, err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Search")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	results = []*entity.SearchResult{}
+	db := session(ctx, repo.db)
+	query := db.Model(&entity.Task{}).Where("user_id = ?", uid)
+	if db.Dialector.Name() == "mysql" {
+		against := booleanQuery(terms)
+		if against == "" {
+			return
 		}
-	}()
+		query = query.Select("id, MATCH(title, content) AGAINST (? IN BOOLEAN MODE) AS score", against).
+			Where("MATCH(title, content) AGAINST (? IN BOOLEAN MODE)", against)
+	} else {
+		// titleに一致した語はcontentに一致した語より一致度を高くする
+		scores := make([]string, len(terms))
+		args := make([]interface{}, 0, len(terms)*2)
+		for i, t := range terms {
+			pattern := likePattern(t.Text)
+			scores[i] = "(CASE WHEN LOWER(title) LIKE ? ESCAPE '!' THEN 2 ELSE 0 END + " +
+				"CASE WHEN LOWER(content) LIKE ? ESCAPE '!' THEN 1 ELSE 0 END)"
+			args = append(args, pattern, pattern)
+			query = query.Where("(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(content) LIKE ? ESCAPE '!')", pattern, pattern)
+		}
+		query = query.Select("id, "+strings.Join(scores, " + ")+" AS score", args...)
+	}
 
-	tx := repo.db.Begin()
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
+	hits := []searchHit{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return query.Order("score DESC").Order("updated_at DESC").Order("id").Limit(limit).Scan(&hits).Error
+	})
+	if err != nil || len(hits) == 0 {
+		return
+	}
+
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	tasks := []*entity.Task{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Preload("Tags").Preload("Assignee").Where("id IN ?", ids).Where("user_id = ?", uid).Find(&tasks).Error
+	})
+	if err != nil {
+		return
+	}
+	byID := map[string]*entity.Task{}
+	for _, t := range tasks {
+		byID[t.ID.String()] = t
+	}
+	// 一致度の順に並べる
+	for _, h := range hits {
+		if t, ok := byID[h.ID]; ok {
+			results = append(results, &entity.SearchResult{Task: t, Score: h.Score})
+		}
+	}
+	return
+}
+
+// booleanQuery はtermsをすべて含むTaskを検索するBOOLEAN MODEの検索文字列を返す
+// 演算子として解釈される文字は空白として扱い，複数の語に分かれた場合は句とする
+func booleanQuery(terms []entity.SearchTerm) string {
+	parts := []string{}
+	for _, t := range terms {
+		words := strings.FieldsFunc(t.Text, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune(`"+-<>()~*@`, r)
+		})
+		switch {
+		case len(words) == 0:
+			continue
+		case t.Phrase || len(words) > 1:
+			parts = append(parts, `+"`+strings.Join(words, " ")+`"`)
+		case t.Prefix:
+			parts = append(parts, "+"+words[0]+"*")
+		default:
+			parts = append(parts, "+"+words[0])
 		}
-	}()
+	}
+	return strings.Join(parts, " ")
+}
+
+// likePattern はsを含む文字列に一致するLIKEのパターンを返す．エスケープ文字は!とする
+func likePattern(s string) string {
+	s = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(strings.ToLower(s))
+	return "%" + s + "%"
+}
+
+func (repo *TaskRepository) Update(ctx context.Context, t *entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }() //TODO:testなし
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	task := &entity.Task{}
 	// idに該当するユーザーがいない場合を弾く
-	err = tx.Where("id = ?", t.ID).Where("user_id = ?", t.UserID).First(task).Error
+	// 同時に更新された場合にバージョンを比べられるよう，行をロックする
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", t.ID).Where("user_id = ?", t.UserID).First(task).Error
+	})
 	if err != nil {
 		return
 	}
+	// バージョンが指定されている場合は，読み込んだ後に他で変更されていないことを確かめる
+	if t.Version != 0 && t.Version != task.Version {
+		return entity.ErrVersionConflict
+	}
+	t.Version = task.Version + 1
 
-	err = tx.Save(t).Error
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+		// Positionの変更はUpdatePositionsで，親の変更はUpdateParentで行う
+		return tx.Omit("created_at", "position", "parent_id", "assignee_id", clause.Associations).Save(t).Error
+	})
 	if err != nil {
 		return //TODO:testなし
 	}
+	t.Position, t.ParentID = task.Position, task.ParentID
+	// レスポンスで返すために付いているTagを取得し直す
+	t.Tags = []*entity.Tag{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tags", func() error {
+		return tx.Model(t).Association("Tags").Find(&t.Tags)
+	})
+	return
+}
+
+func (repo *TaskRepository) CheckVersion(ctx context.Context, tid, uid string, version int) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.CheckVersion")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	task := &entity.Task{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("version").Where("id = ?", tid).Where("user_id = ?", uid).First(task).Error
+	})
+	if err != nil {
+		return
+	}
+	if task.Version != version {
+		return entity.ErrVersionConflict
+	}
 	return
 }
 
-func (repo *TaskRepository) Delete(tid, uid string) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr) //TODO:testなし
+func (repo *TaskRepository) UpdatePositions(ctx context.Context, uid string, tasks []*entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.UpdatePositions")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	for _, t := range tasks {
+		err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+			return tx.Model(&entity.Task{}).
+				Where("id = ?", t.ID).Where("user_id = ?", uid).
+				Updates(map[string]interface{}{"position": t.Position, "version": incrementVersion}).Error
+		})
+		if err != nil {
+			return
 		}
-	}()
+		t.Version++
+	}
+	return
+}
+
+func (repo *TaskRepository) UpdateCompletions(ctx context.Context, uid string, tasks []*entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.UpdateCompletions")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
 
-	tx := repo.db.Begin()
-	defer func() {
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	for _, t := range tasks {
+		err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+			return tx.Model(&entity.Task{}).
+				Where("id = ?", t.ID).Where("user_id = ?", uid).
+				Updates(map[string]interface{}{"is_completed": t.IsCompleted, "version": incrementVersion}).Error
+		})
 		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
+			return
 		}
-	}()
+		t.Version++
+	}
+	return
+}
+
+func (repo *TaskRepository) UpdateParent(ctx context.Context, t *entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.UpdateParent")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 移動先のTaskの末尾に並べる
+	t.Position, err = repo.lastPosition(ctx, tx, t)
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+		return tx.Model(&entity.Task{}).
+			Where("id = ?", t.ID).Where("user_id = ?", t.UserID).
+			Updates(map[string]interface{}{"parent_id": t.ParentID, "position": t.Position, "version": incrementVersion}).Error
+	})
+	if err != nil {
+		return
+	}
+	t.Version++
+	return
+}
+
+func (repo *TaskRepository) Recur(ctx context.Context, done *entity.Task, next *entity.Task) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Recur")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// 既に次の回を作成した場合を弾くため，繰り返しが外れていないことを条件にする
+	var result *gorm.DB
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+		result = tx.Model(&entity.Task{}).
+			Where("id = ?", done.ID).Where("user_id = ?", done.UserID).Where("recurrence IS NOT NULL").
+			Updates(map[string]interface{}{"is_completed": true, "recurrence": nil, "version": incrementVersion})
+		return result.Error
+	})
+	if err != nil {
+		return
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrRecordNotFound
+	}
+	done.IsCompleted, done.RRule = true, entity.NullString{}
+	done.Version++
+	if next == nil {
+		return
+	}
+
+	next.NewID()
+	next.Version = 1
+	next.Position, err = repo.lastPosition(ctx, tx, next)
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "INSERT", "tasks", func() error {
+		return tx.Omit(clause.Associations).Create(next).Error
+	})
+	return
+}
+
+func (repo *TaskRepository) Delete(ctx context.Context, tid, uid string, version int) (err error) {
+	ctx, span := startSpan(ctx, "TaskRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }() //TODO:testなし
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	task := &entity.Task{}
 	// idに該当するユーザーがいない場合を弾く
-	err = tx.Where("id = ?", tid).Where("user_id = ?", uid).First(task).Error
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", tid).Where("user_id = ?", uid).First(task).Error
+	})
+	if err != nil {
+		return
+	}
+	if version != 0 && version != task.Version {
+		return entity.ErrVersionConflict
+	}
+	// サブタスクもまとめて削除する
+	ids, err := withSubtasks(ctx, tx, repo.logger, []string{tid})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "task_tags", func() error {
+		return tx.Where("task_id IN ?", ids).Delete(&taskTag{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "reminders", func() error {
+		return tx.Where("task_id IN ?", ids).Delete(&entity.Reminder{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "assignments", func() error {
+		return tx.Where("task_id IN ?", ids).Delete(&entity.Assignment{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "comments", func() error {
+		return tx.Where("task_id IN ?", ids).Delete(&entity.Comment{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = discardAttachments(ctx, tx, repo.logger, "task_id IN ?", ids)
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "shares", func() error {
+		return tx.Where("resource_type = ?", entity.ShareTask).Where("resource_id IN ?", ids).Delete(&entity.Share{}).Error
+	})
 	if err != nil {
 		return
 	}
-	err = repo.db.Where("id = ?", tid).Where("user_id = ?", uid).Delete(&entity.Task{}).Error
+	err = traceQuery(ctx, repo.logger, "DELETE", "tasks", func() error {
+		return tx.Where("id IN ?", ids).Where("user_id = ?", uid).Delete(&entity.Task{}).Error
+	})
 	if err != nil {
 		return //TODO:testなし
 	}
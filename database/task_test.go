@@ -1,16 +1,20 @@
 package database
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
 )
 
 const (
 	uuidTA1 = "cb8aa4fc-1964-a965-00ea-8b158c0ffcc7"
 	uuidTA2 = "38397cad-8865-081f-3482-2a035f875d5c"
+	uuidTA3 = "7d3e5b1a-4c2f-4e8a-9b6d-0f1e2a3b4c5d"
 	uuidTB1 = "2265150f-a3c9-d21e-ee75-a8c42a07807e"
 	uuidTB2 = "b67a753e-90c0-4435-ede7-fdcda1504ca5"
 )
@@ -82,7 +86,7 @@ func TestTaskRepository_Create(t *testing.T) {
 
 			addTaskData(t, task, tt.prepareTasks)
 
-			err := task.Create(tt.task)
+			err := task.Create(context.Background(), tt.task)
 			gotTask := tt.task
 
 			if errorCompare(t, err, tt.wantErr) {
@@ -90,10 +94,13 @@ func TestTaskRepository_Create(t *testing.T) {
 			}
 			if tt.wantErr == nil {
 				// IDは一致する必要なし
+				// PositionはTestTaskRepository_Create_Positionで確認する
 				cmpopt := cmpopts.IgnoreFields(entity.Task{},
 					"ID",
+					"Position",
 					"CreatedAt",
-					"UpdatedAt")
+					"UpdatedAt",
+					"Version")
 				if diff := cmp.Diff(tt.wantTask, gotTask, cmpopt); diff != "" {
 					t.Errorf("Data (-want +got) =\n%s\n", diff)
 				}
@@ -102,6 +109,334 @@ func TestTaskRepository_Create(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_Create_Position(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	first := taskA1
+	first.Position = "V"
+	addTaskData(t, task, []entity.Task{first, taskA2})
+
+	// 同じ日のタスクの末尾に並べる
+	newTask := entity.NewTask("", "taskA3", "", uuidUA, "2020-12-08")
+	err := task.Create(context.Background(), newTask)
+	errorCompare(t, err, nil)
+	if newTask.Position <= first.Position {
+		t.Errorf("Position = %q, want after %q", newTask.Position, first.Position)
+	}
+}
+
+func TestTaskRepository_FindByUser(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	taskA3 := *entity.NewTask(uuidTA3, "taskA3", "", uuidUA, "2020-12-08")
+	taskA1 := taskA1
+	taskA1.Position, taskA3.Position = "V", "k"
+	taskA3.Priority = entity.PriorityHigh
+
+	tests := []struct {
+		name    string
+		uid     string
+		query   repository.TaskQuery
+		wantIDs []string
+	}{
+		{
+			name:    "日付を指定した場合はその日のタスクをPosition順に取得する",
+			uid:     uuidUA,
+			query:   repository.TaskQuery{Date: entity.NewNullDate("2020-12-08")},
+			wantIDs: []string{uuidTA1, uuidTA3},
+		},
+		{
+			name:    "日付を指定しない場合は日付順に取得する",
+			uid:     uuidUA,
+			query:   repository.TaskQuery{},
+			wantIDs: []string{uuidTA2, uuidTA1, uuidTA3},
+		},
+		{
+			name:    "優先度の高い順に取得する",
+			uid:     uuidUA,
+			query:   repository.TaskQuery{Sort: repository.SortByPriority},
+			wantIDs: []string{uuidTA3, uuidTA2, uuidTA1},
+		},
+		{
+			name:    "日付より前の未完了のタスクを取得する",
+			uid:     uuidUA,
+			query:   repository.TaskQuery{Before: entity.NewNullDate("2020-12-08"), Incomplete: true},
+			wantIDs: []string{uuidTA2},
+		},
+		{
+			name:    "タスクがなければ空",
+			uid:     uuidUZ,
+			query:   repository.TaskQuery{},
+			wantIDs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addTaskData(t, task, []entity.Task{taskA1, taskA2, taskA3, taskB1})
+
+			gotTasks, err := task.FindByUser(context.Background(), tt.uid, tt.query)
+			errorCompare(t, err, nil)
+
+			gotIDs := []string{}
+			for _, got := range gotTasks {
+				gotIDs = append(gotIDs, got.ID.String())
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("IDs (-want +got) =\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTaskRepository_Search(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	taskA3 := *entity.NewTask(uuidTA3, "会議の準備", "議事録のテンプレートを用意する", uuidUA, "2020-12-08")
+
+	tests := []struct {
+		name    string
+		uid     string
+		terms   []entity.SearchTerm
+		wantIDs []string
+	}{
+		{
+			name:    "contentに一致するタスクを取得する",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "ContentA1"}},
+			wantIDs: []string{uuidTA1},
+		},
+		{
+			name:    "句に一致するタスクを取得する",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "am ContentA2", Phrase: true}},
+			wantIDs: []string{uuidTA2},
+		},
+		{
+			name:    "前方一致で他のユーザーのタスクは取得しない",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "task", Prefix: true}},
+			wantIDs: []string{uuidTA1, uuidTA2},
+		},
+		{
+			name:    "日本語の語で検索できる",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "議事録"}},
+			wantIDs: []string{uuidTA3},
+		},
+		{
+			name:    "すべての語に一致するタスクのみを取得する",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "会議"}, {Text: "ContentA1"}},
+			wantIDs: []string{},
+		},
+		{
+			name:    "演算子のみの語なら空",
+			uid:     uuidUA,
+			terms:   []entity.SearchTerm{{Text: "+-"}},
+			wantIDs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addTaskData(t, task, []entity.Task{taskA1, taskA2, taskA3, taskB1})
+
+			results, err := task.Search(context.Background(), tt.uid, tt.terms, 10)
+			errorCompare(t, err, nil)
+
+			gotIDs := []string{}
+			for _, got := range results {
+				gotIDs = append(gotIDs, got.Task.ID.String())
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("IDs (-want +got) =\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTaskRepository_UpdatePositions(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	addTaskData(t, task, []entity.Task{taskA1, taskB1})
+
+	moved := taskA1
+	moved.Position = "k"
+	err := task.UpdatePositions(context.Background(), uuidUA, []*entity.Task{&moved})
+	errorCompare(t, err, nil)
+
+	gotTask, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if gotTask.Position != "k" {
+		t.Errorf("Position (-want +got) =\n- %s\n+ %s", "k", gotTask.Position)
+	}
+
+	// 他のユーザーのタスクは更新しない
+	other := taskB1
+	other.Position = "k"
+	err = task.UpdatePositions(context.Background(), uuidUA, []*entity.Task{&other})
+	errorCompare(t, err, nil)
+	gotTask, err = task.FindByID(context.Background(), uuidTB1, uuidUB)
+	errorCompare(t, err, nil)
+	if gotTask.Position != "" {
+		t.Errorf("Position of other user's task = %q", gotTask.Position)
+	}
+}
+
+func TestTaskRepository_Subtasks(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	addTaskData(t, task, []entity.Task{taskA1, taskA2})
+
+	// サブタスクは親のTaskの中で末尾に並ぶ
+	subs := []*entity.Task{}
+	for _, title := range []string{"sub1", "sub2"} {
+		sub := entity.NewTask("", title, "", uuidUA, "2020-12-27")
+		sub.ParentID = entity.NewNullString(uuidTA1)
+		err := task.Create(context.Background(), sub)
+		errorCompare(t, err, nil)
+		subs = append(subs, sub)
+	}
+	if subs[0].Position >= subs[1].Position {
+		t.Errorf("Position got %q, %q", subs[0].Position, subs[1].Position)
+	}
+
+	// 親のないTaskの一覧にはサブタスクを含めない
+	gotTasks, err := task.FindByUser(context.Background(), uuidUA, repository.TaskQuery{})
+	errorCompare(t, err, nil)
+	if len(gotTasks) != 2 {
+		t.Errorf("Data got = %v", gotTasks)
+	}
+	gotTasks, err = task.FindByUser(context.Background(), uuidUA, repository.TaskQuery{ParentID: uuidTA1})
+	errorCompare(t, err, nil)
+	if len(gotTasks) != 2 || !gotTasks[0].ID.Equal(subs[0].ID) {
+		t.Errorf("Data got = %v", gotTasks)
+	}
+
+	// 他のTaskのサブタスクに移動する
+	subs[1].ParentID = entity.NewNullString(uuidTA2)
+	err = task.UpdateParent(context.Background(), subs[1])
+	errorCompare(t, err, nil)
+	gotTasks, err = task.FindByUser(context.Background(), uuidUA, repository.TaskQuery{ParentID: uuidTA2})
+	errorCompare(t, err, nil)
+	if len(gotTasks) != 1 || !gotTasks[0].ID.Equal(subs[1].ID) {
+		t.Errorf("Data got = %v", gotTasks)
+	}
+
+	// 完了状態のみを更新する
+	subs[0].SetComp(true)
+	err = task.UpdateCompletions(context.Background(), uuidUA, []*entity.Task{subs[0]})
+	errorCompare(t, err, nil)
+	gotTask, err := task.FindByID(context.Background(), subs[0].ID.String(), uuidUA)
+	errorCompare(t, err, nil)
+	if !gotTask.IsCompleted || !gotTask.ParentID.Equal(subs[0].ParentID) {
+		t.Errorf("Data got = %s", gotTask)
+	}
+
+	// 親を削除するとサブタスクも削除する
+	err = task.Delete(context.Background(), uuidTA1, uuidUA, 0)
+	errorCompare(t, err, nil)
+	_, err = task.FindByID(context.Background(), subs[0].ID.String(), uuidUA)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func TestTaskRepository_Recur(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	weekly := taskA1
+	weekly.Deadline = entity.NewNullDate("2020-01-01")
+	weekly.RRule = entity.NewNullString("FREQ=WEEKLY")
+	addTaskData(t, task, []entity.Task{weekly, taskA2})
+
+	// 期間より前に始まる繰り返しのTaskも取得する
+	q := repository.TaskQuery{From: entity.NewNullDate("2020-01-03"), To: entity.NewNullDate("2020-01-10")}
+	gotTasks, err := task.FindByUser(context.Background(), uuidUA, q)
+	errorCompare(t, err, nil)
+	if len(gotTasks) != 2 {
+		t.Errorf("Data got = %v", gotTasks)
+	}
+
+	next := entity.NewTask("", weekly.Title.String(), "", uuidUA, "2020-01-08")
+	next.RRule = weekly.RRule
+	err = task.Recur(context.Background(), &weekly, next)
+	errorCompare(t, err, nil)
+	gotTask, err := task.FindByID(context.Background(), uuidTA1, uuidUA)
+	errorCompare(t, err, nil)
+	if !gotTask.IsCompleted || !gotTask.RRule.IsNull() {
+		t.Errorf("Data got = %s", gotTask)
+	}
+	gotTask, err = task.FindByID(context.Background(), next.ID.String(), uuidUA)
+	errorCompare(t, err, nil)
+	if !gotTask.RRule.Equal(next.RRule) || gotTask.IsCompleted {
+		t.Errorf("Data got = %s", gotTask)
+	}
+
+	// 同じ回から次の回を2回作成することはできない
+	err = task.Recur(context.Background(), &weekly, nil)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func TestTaskRepository_Version(t *testing.T) {
+
+	task := prepareTaskT(t)
+
+	addTaskData(t, task, []entity.Task{})
+
+	// 作成したTaskのバージョンは1から始まる
+	created := entity.NewTask("", "taskA1", "", uuidUA, "2020-12-08")
+	err := task.Create(context.Background(), created)
+	errorCompare(t, err, nil)
+	if created.Version != 1 {
+		t.Errorf("Version got = %d", created.Version)
+	}
+
+	// バージョンを指定して更新するとバージョンが上がる
+	updated := *created
+	updated.Title = entity.NewNullString("taskA2")
+	err = task.Update(context.Background(), &updated)
+	errorCompare(t, err, nil)
+	if updated.Version != 2 {
+		t.Errorf("Version got = %d", updated.Version)
+	}
+
+	// 古いバージョンを指定した更新と削除はErrVersionConflict
+	stale := *created
+	err = task.Update(context.Background(), &stale)
+	errorCompare(t, err, entity.ErrVersionConflict)
+	err = task.Delete(context.Background(), created.ID.String(), uuidUA, 1)
+	errorCompare(t, err, entity.ErrVersionConflict)
+
+	// 完了状態だけを変えてもバージョンが上がる
+	updated.IsCompleted = true
+	err = task.UpdateCompletions(context.Background(), uuidUA, []*entity.Task{&updated})
+	errorCompare(t, err, nil)
+	gotTask, err := task.FindByID(context.Background(), created.ID.String(), uuidUA)
+	errorCompare(t, err, nil)
+	if gotTask.Version != 3 || updated.Version != 3 {
+		t.Errorf("Version got = %d, %d", gotTask.Version, updated.Version)
+	}
+
+	// 行をロックして確かめる場合も古いバージョンはErrVersionConflict
+	err = task.CheckVersion(context.Background(), created.ID.String(), uuidUA, 2)
+	errorCompare(t, err, entity.ErrVersionConflict)
+	err = task.CheckVersion(context.Background(), created.ID.String(), uuidUA, 3)
+	errorCompare(t, err, nil)
+	err = task.CheckVersion(context.Background(), created.ID.String(), uuidUB, 3)
+	errorCompare(t, err, entity.ErrRecordNotFound)
+
+	err = task.Delete(context.Background(), created.ID.String(), uuidUA, 3)
+	errorCompare(t, err, nil)
+}
+
 func TestTaskRepository_FindByID(t *testing.T) {
 
 	task := prepareTaskT(t)
@@ -151,7 +486,7 @@ func TestTaskRepository_FindByID(t *testing.T) {
 
 			addTaskData(t, task, tt.prepareTasks)
 
-			gotTask, err := task.FindByID(tt.tid, tt.uid)
+			gotTask, err := task.FindByID(context.Background(), tt.tid, tt.uid)
 
 			if errorCompare(t, err, tt.wantErr) {
 				t.Errorf("Data got = %s", gotTask)
@@ -168,6 +503,19 @@ func TestTaskRepository_FindByID(t *testing.T) {
 	}
 }
 
+func TestTaskRepository_FindByID_Canceled(t *testing.T) {
+
+	task := prepareTaskT(t)
+	addTaskData(t, task, []entity.Task{taskA1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := task.FindByID(ctx, uuidTA1, uuidUA)
+
+	errorCompare(t, err, context.Canceled)
+}
+
 func TestTaskRepository_Update(t *testing.T) {
 
 	task := prepareTaskT(t)
@@ -249,7 +597,7 @@ func TestTaskRepository_Update(t *testing.T) {
 
 			addTaskData(t, task, tt.prepareTasks)
 
-			err := task.Update(tt.task)
+			err := task.Update(context.Background(), tt.task)
 			gotTask := tt.task
 
 			if errorCompare(t, err, tt.wantErr) {
@@ -258,7 +606,8 @@ func TestTaskRepository_Update(t *testing.T) {
 			if tt.wantErr == nil {
 				cmpopt := cmpopts.IgnoreFields(entity.Task{},
 					"CreatedAt",
-					"UpdatedAt")
+					"UpdatedAt",
+					"Version")
 				if diff := cmp.Diff(tt.wantTask, gotTask, cmpopt); diff != "" {
 					t.Errorf("Data (-want +got) =\n%s\n", diff)
 				}
@@ -310,7 +659,7 @@ func TestTaskRepository_Delete(t *testing.T) {
 
 			addTaskData(t, task, tt.prepareTasks)
 
-			err := task.Delete(tt.taskid, tt.userid)
+			err := task.Delete(context.Background(), tt.taskid, tt.userid, 0)
 
 			errorCompare(t, err, tt.wantErr)
 		})
@@ -344,11 +693,11 @@ func prepareTaskT(t *testing.T) (task *TaskRepository) {
 
 	// dbに接続
 	db := NewTestDB()
-	task = NewTaskRepository(db)
+	task = NewTaskRepository(db, logging.Discard())
 	// db.LogMode(true)
 
 	// Userデータの準備
-	user := NewUserRepository(db)
+	user := NewUserRepository(db, logging.Discard())
 	users := []entity.User{userA, userB}
 	addUserData(t, user, users)
 
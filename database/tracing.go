@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/hiroyaonoe/todoapp-server/database"
+
+// startSpan はRepositoryのメソッドに対応する子スパンを開始する
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithAttributes(semconv.DBSystemMySQL),
+	)
+}
+
+// endSpan はエラーをスパンに記録してから終了する
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceQuery はひとつのクエリをスパンで囲んで実行し，所要時間をdebugログに出力する
+// (例: UpdateのうちFirstとSaveのどちらに時間がかかったかを区別できる)
+// クエリの値(パスワードのハッシュなど)はログに含めない
+func traceQuery(ctx context.Context, logger *slog.Logger, operation, table string, query func() error) (err error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, operation+" "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+		),
+	)
+	defer func() { endSpan(span, err) }()
+
+	start := time.Now()
+	err = query()
+	logger.DebugContext(ctx, "query",
+		slog.String("operation", operation),
+		slog.String("table", table),
+		slog.Duration("duration", time.Since(start)),
+		slog.Any("error", err),
+	)
+	return
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor の具体的な実装
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *DB) *Transactor {
+	return &Transactor{db: db.Connect()}
+}
+
+// Transaction はfnの中で行うリポジトリの操作をひとつのトランザクションで実行する
+// トランザクションはfnに渡すctxに保持し，各リポジトリのbeginTxとsessionはそれを使う
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := startSpan(ctx, "Transactor.Transaction")
+	defer func() { endSpan(span, err) }()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	// すでにトランザクションの中であればそれに含める
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	tx, err := beginTx(ctx, t.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	return fn(context.WithValue(ctx, txKey{}, tx))
+}
@@ -5,117 +5,173 @@ SQLへのクエリはここで行う
 package database
 
 import (
-	"github.com/go-sql-driver/mysql"
+	"context"
+	"log/slog"
+	"time"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
-	"github.com/jinzhu/gorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository の具体的な実装
 type UserRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
 }
 
-func NewUserRepository(db *DB) *UserRepository {
-	return &UserRepository{db: db.Connect()}
+func NewUserRepository(db *DB, logger *slog.Logger) *UserRepository {
+	return &UserRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
 }
 
-func (repo *UserRepository) FindByID(id string) (user *entity.User, err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr) //TODO:testなし
-		}
-		return
-	}()
+func (repo *UserRepository) FindByID(ctx context.Context, id string) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }() //TODO:testなし
+
+	db := session(ctx, repo.db)
 	user = &entity.User{}
-	err = repo.db.Where("id = ?", id).First(user).Error
+	err = traceQuery(ctx, repo.logger, "SELECT", "users", func() error {
+		return db.Where("id = ?", id).First(user).Error
+	})
 	return
 }
 
-func (repo *UserRepository) Create(u *entity.User) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr)
-		}
-		return
-	}()
+func (repo *UserRepository) FindByEmail(ctx context.Context, email string) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserRepository.FindByEmail")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	user = &entity.User{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "users", func() error {
+		return db.Where("email = ?", email).First(user).Error
+	})
+	return
+}
 
-	tx := repo.db.Begin()
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+func (repo *UserRepository) Create(ctx context.Context, u *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	u.NewID()
+	u.Version = 1
 	// u.EncryptPassword()
 
-	err = tx.Create(u).Error
+	err = traceQuery(ctx, repo.logger, "INSERT", "users", func() error {
+		return tx.Create(u).Error
+	})
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (repo *UserRepository) Update(u *entity.User) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr)
-		}
-		return
-	}()
+func (repo *UserRepository) Update(ctx context.Context, u *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
 
-	tx := repo.db.Begin()
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	// u.EncryptPassword()
 
 	// idに該当するユーザーがいない場合を弾く
+	// 同時に更新された場合にバージョンを比べられるよう，行をロックする
 	user := &entity.User{}
-	err = tx.Where("id = ?", u.ID).First(user).Error
+	err = traceQuery(ctx, repo.logger, "SELECT", "users", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", u.ID).First(user).Error
+	})
 	if err != nil {
 		return
 	}
+	// バージョンが指定されている場合は，読み込んだ後に他で変更されていないことを確かめる
+	if u.Version != 0 && u.Version != user.Version {
+		return entity.ErrVersionConflict
+	}
+	u.Version = user.Version + 1
 
-	err = tx.Save(u).Error
+	err = traceQuery(ctx, repo.logger, "UPDATE", "users", func() error {
+		return tx.Omit("created_at").Save(u).Error
+	})
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (repo *UserRepository) Delete(id string) (err error) {
-	defer func() {
-		if nerr, ok := err.(*mysql.MySQLError); ok {
-			err = (*entity.ErrMySQL)(nerr) //TODO:testなし
-		}
+func (repo *UserRepository) Delete(ctx context.Context, id string, version int) (err error) {
+	ctx, span := startSpan(ctx, "UserRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }() //TODO:testなし
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
 		return
-	}()
-	tx := repo.db.Begin()
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		} else {
-			tx.Commit()
-		}
-	}()
+	}
+	defer func() { err = endTx(tx, err) }()
 
 	// idに該当するユーザーがいない場合を弾く
 	user := &entity.User{}
-	err = tx.Where("id = ?", id).First(user).Error
+	err = traceQuery(ctx, repo.logger, "SELECT", "users", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(user).Error
+	})
+	if err != nil {
+		return
+	}
+	if version != 0 && version != user.Version {
+		return entity.ErrVersionConflict
+	}
+
+	// ユーザーが添付したファイルとユーザーのTaskに添付されたファイルはBlobStoreからも削除する
+	// 外部キーで一緒に削除されるサブタスクは他のユーザーが作成したものも対象にする
+	ids := []string{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return tx.Model(&entity.Task{}).Where("user_id = ?", id).Pluck("id", &ids).Error
+	})
+	if err != nil {
+		return
+	}
+	ids, err = withSubtasks(ctx, tx, repo.logger, ids)
+	if err != nil {
+		return
+	}
+	err = discardAttachments(ctx, tx, repo.logger, "user_id = ? OR task_id IN ?", id, ids)
 	if err != nil {
 		return
 	}
 
 	// err = tx.Delete(&entity.User{}, id).Error
-	err = tx.Where("id = ?", id).Delete(&entity.User{}).Error
+	err = traceQuery(ctx, repo.logger, "DELETE", "users", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.User{}).Error
+	})
 	if err != nil {
 		return //TODO:testなし
 	}
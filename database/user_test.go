@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
 )
 
 const (
@@ -56,7 +58,7 @@ func TestUserRepository_FindByID(t *testing.T) {
 
 			addUserData(t, user, tt.prepareUsers)
 
-			gotUser, err := user.FindByID(tt.userid)
+			gotUser, err := user.FindByID(context.Background(), tt.userid)
 
 			if errorCompare(t, err, tt.wantErr) {
 				t.Errorf("Data got = %s", gotUser)
@@ -81,6 +83,62 @@ func TestUserRepository_FindByID(t *testing.T) {
 	}
 }
 
+func TestUserRepository_FindByEmail(t *testing.T) {
+
+	user := prepareUserT(t)
+
+	tests := []struct {
+		name         string
+		email        string
+		wantUser     *entity.User
+		wantErr      error
+		prepareUsers []entity.User
+	}{
+		{
+			name:     "正しくユーザが取得できる",
+			email:    "exampleB@example.com",
+			wantUser: entity.NewUser(uuidUB, "userB", "passwordB", "exampleB@example.com"),
+			wantErr:  nil,
+			prepareUsers: []entity.User{
+				userA,
+				userB,
+			},
+		},
+		{
+			name:     "存在しないemailの場合はErrRecordNotFound",
+			email:    "exampleZ@example.com",
+			wantUser: nil,
+			wantErr:  entity.ErrRecordNotFound,
+			prepareUsers: []entity.User{
+				userA,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+
+			addUserData(t, user, tt.prepareUsers)
+
+			gotUser, err := user.FindByEmail(context.Background(), tt.email)
+
+			if errorCompare(t, err, tt.wantErr) {
+				t.Errorf("Data got = %s", gotUser)
+			}
+			if tt.wantErr == nil {
+				cmpopt := cmpopts.IgnoreFields(entity.User{},
+					"Password",
+					"CreatedAt",
+					"UpdatedAt")
+				diff := cmp.Diff(tt.wantUser, gotUser, cmpopt)
+				if diff != "" {
+					t.Errorf("Data (-want +got) =\n%s\n", diff)
+				}
+			}
+		})
+	}
+}
+
 func TestUserRepository_Create(t *testing.T) {
 
 	user := prepareUserT(t)
@@ -138,7 +196,7 @@ func TestUserRepository_Create(t *testing.T) {
 
 			addUserData(t, user, tt.prepareUsers)
 
-			err := user.Create(tt.user)
+			err := user.Create(context.Background(), tt.user)
 			gotUser := tt.user
 
 			if errorCompare(t, err, tt.wantErr) {
@@ -150,7 +208,8 @@ func TestUserRepository_Create(t *testing.T) {
 					"ID",
 					"Password",
 					"CreatedAt",
-					"UpdatedAt")
+					"UpdatedAt",
+					"Version")
 				diff := cmp.Diff(tt.wantUser, gotUser, cmpopt)
 				if diff != "" {
 					t.Errorf("Data (-want +got) =\n%s\n", diff)
@@ -257,7 +316,7 @@ func TestUserRepository_Update(t *testing.T) {
 
 			addUserData(t, user, tt.prepareUsers)
 
-			err := user.Update(tt.user)
+			err := user.Update(context.Background(), tt.user)
 			gotUser := tt.user
 
 			if errorCompare(t, err, tt.wantErr) {
@@ -267,7 +326,8 @@ func TestUserRepository_Update(t *testing.T) {
 				cmpopt := cmpopts.IgnoreFields(entity.User{},
 					"Password",
 					"CreatedAt",
-					"UpdatedAt")
+					"UpdatedAt",
+					"Version")
 				diff := cmp.Diff(tt.wantUser, gotUser, cmpopt)
 				if diff != "" {
 					t.Errorf("Data (-want +got) =\n%s\n", diff)
@@ -317,7 +377,7 @@ func TestUserRepository_Delete(t *testing.T) {
 
 			addUserData(t, user, tt.prepareUsers)
 
-			err := user.Delete(tt.userid)
+			err := user.Delete(context.Background(), tt.userid, 0)
 
 			errorCompare(t, err, tt.wantErr)
 		})
@@ -352,7 +412,7 @@ func prepareUserT(t *testing.T) (user *UserRepository) {
 
 	// dbに接続
 	db := NewTestDB()
-	user = NewUserRepository(db)
+	user = NewUserRepository(db, logging.Discard())
 	// db.LogMode(true)
 
 	return
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceRepository の具体的な実装
+type WorkspaceRepository struct {
+	db      *gorm.DB
+	logger  *slog.Logger
+	timeout time.Duration
+}
+
+func NewWorkspaceRepository(db *DB, logger *slog.Logger) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db.Connect(), logger: logger, timeout: db.QueryTimeout()}
+}
+
+func (repo *WorkspaceRepository) Create(ctx context.Context, w *entity.Workspace, owner string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceRepository.Create")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	w.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "workspaces", func() error {
+		return tx.Create(w).Error
+	})
+	if err != nil {
+		return
+	}
+
+	// 作成したユーザーをownerにする
+	m := &entity.Membership{WorkspaceID: w.ID, UserID: entity.NewNullString(owner), Role: entity.WorkspaceOwner}
+	m.NewID()
+	err = traceQuery(ctx, repo.logger, "INSERT", "memberships", func() error {
+		return tx.Create(m).Error
+	})
+	return
+}
+
+func (repo *WorkspaceRepository) FindByID(ctx context.Context, id string) (workspace *entity.Workspace, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	db := session(ctx, repo.db)
+	workspace = &entity.Workspace{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "workspaces", func() error {
+		return db.Where("id = ?", id).First(workspace).Error
+	})
+	return
+}
+
+func (repo *WorkspaceRepository) FindByIDs(ctx context.Context, ids []string) (workspaces []*entity.Workspace, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceRepository.FindByIDs")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	workspaces = []*entity.Workspace{}
+	if len(ids) == 0 {
+		return
+	}
+	db := session(ctx, repo.db)
+	err = traceQuery(ctx, repo.logger, "SELECT", "workspaces", func() error {
+		return db.Where("id IN ?", ids).Order("created_at").Order("id").Find(&workspaces).Error
+	})
+	return
+}
+
+func (repo *WorkspaceRepository) Update(ctx context.Context, w *entity.Workspace) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceRepository.Update")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するWorkspaceがない場合を弾く
+	workspace := &entity.Workspace{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "workspaces", func() error {
+		return tx.Where("id = ?", w.ID).First(workspace).Error
+	})
+	if err != nil {
+		return
+	}
+
+	err = traceQuery(ctx, repo.logger, "UPDATE", "workspaces", func() error {
+		return tx.Model(workspace).Update("name", w.Name).Error
+	})
+	if err != nil {
+		return
+	}
+	w.CreatedAt, w.UpdatedAt = workspace.CreatedAt, workspace.UpdatedAt
+	return
+}
+
+func (repo *WorkspaceRepository) Delete(ctx context.Context, id string) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	ctx, cancel := withTimeout(ctx, repo.timeout)
+	defer cancel()
+	defer func() { err = contextError(ctx, err) }()
+	defer func() { err = mysqlError(err) }()
+
+	tx, err := beginTx(ctx, repo.db)
+	if err != nil {
+		return
+	}
+	defer func() { err = endTx(tx, err) }()
+
+	// idに該当するWorkspaceがない場合を弾く
+	workspace := &entity.Workspace{}
+	err = traceQuery(ctx, repo.logger, "SELECT", "workspaces", func() error {
+		return tx.Where("id = ?", id).First(workspace).Error
+	})
+	if err != nil {
+		return
+	}
+
+	// Taskは作成したユーザーのTaskとして残す
+	err = traceQuery(ctx, repo.logger, "SELECT", "tasks", func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Assignee").Where("workspace_id = ?", id).Find(&tasks).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "UPDATE", "tasks", func() error {
+		return tx.Model(&entity.Task{}).Where("workspace_id = ?", id).
+			Updates(map[string]interface{}{"workspace_id": nil, "version": incrementVersion}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "memberships", func() error {
+		return tx.Where("workspace_id = ?", id).Delete(&entity.Membership{}).Error
+	})
+	if err != nil {
+		return
+	}
+	err = traceQuery(ctx, repo.logger, "DELETE", "workspaces", func() error {
+		return tx.Where("id = ?", id).Delete(&entity.Workspace{}).Error
+	})
+	return
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestWorkspaceRepository_Create(t *testing.T) {
+
+	workspace, membership, task := prepareWorkspaceT(t)
+
+	addWorkspaceData(t, workspace)
+	addTaskData(t, task, []entity.Task{})
+
+	w := entity.NewWorkspace("", "team")
+	err := workspace.Create(context.Background(), w, uuidUA)
+	errorCompare(t, err, nil)
+
+	// 作成したユーザーはownerになる
+	owner, err := membership.Find(context.Background(), w.ID.String(), uuidUA)
+	errorCompare(t, err, nil)
+	if owner.Role != entity.WorkspaceOwner {
+		t.Errorf("Data got = %v", owner)
+	}
+
+	m := &entity.Membership{WorkspaceID: w.ID, UserID: entity.NewNullString(uuidUB), Role: entity.WorkspaceGuest}
+	err = membership.Create(context.Background(), m)
+	errorCompare(t, err, nil)
+
+	// 同じユーザーを二度は追加できない
+	err = membership.Create(context.Background(), &entity.Membership{WorkspaceID: w.ID, UserID: entity.NewNullString(uuidUB), Role: entity.WorkspaceMember})
+	if err == nil {
+		t.Errorf("Error got = nil, want duplicate entry")
+	}
+
+	m.Role = entity.WorkspaceMember
+	err = membership.Update(context.Background(), m)
+	errorCompare(t, err, nil)
+	got, err := membership.FindByWorkspace(context.Background(), w.ID.String())
+	errorCompare(t, err, nil)
+	if len(got) != 2 || got[1].Role != entity.WorkspaceMember {
+		t.Errorf("Data got = %v", got)
+	}
+
+	// WorkspaceのTaskはメンバーの一覧に含まれる
+	wt := entity.NewTask("", "title", "", uuidUA, "2020-12-27")
+	wt.WorkspaceID = w.ID
+	err = task.Create(context.Background(), wt)
+	errorCompare(t, err, nil)
+	tasks, err := task.FindByUser(context.Background(), uuidUB, repository.TaskQuery{WorkspaceIDs: []string{w.ID.String()}})
+	errorCompare(t, err, nil)
+	if len(tasks) != 1 || tasks[0].ID != wt.ID {
+		t.Errorf("Data got = %v", tasks)
+	}
+}
+
+func TestWorkspaceRepository_Delete(t *testing.T) {
+
+	workspace, membership, task := prepareWorkspaceT(t)
+
+	addWorkspaceData(t, workspace)
+	addTaskData(t, task, []entity.Task{})
+
+	w := entity.NewWorkspace("", "team")
+	err := workspace.Create(context.Background(), w, uuidUA)
+	errorCompare(t, err, nil)
+	wt := entity.NewTask("", "title", "", uuidUA, "2020-12-27")
+	wt.WorkspaceID = w.ID
+	err = task.Create(context.Background(), wt)
+	errorCompare(t, err, nil)
+
+	changed, err := workspace.Delete(context.Background(), w.ID.String())
+	errorCompare(t, err, nil)
+	// 変更前のTaskを返す
+	if len(changed) != 1 || changed[0].ID != wt.ID || changed[0].WorkspaceID != w.ID {
+		t.Errorf("Data got = %v", changed)
+	}
+
+	// メンバーも削除する
+	got, err := membership.FindByUser(context.Background(), uuidUA)
+	errorCompare(t, err, nil)
+	if len(got) != 0 {
+		t.Errorf("Data got = %v", got)
+	}
+
+	// WorkspaceのTaskは作成したユーザーのTaskとして残す
+	found, err := task.FindByID(context.Background(), wt.ID.String(), uuidUA)
+	errorCompare(t, err, nil)
+	if !found.WorkspaceID.IsNull() {
+		t.Errorf("Data got = %v", found)
+	}
+
+	_, err = workspace.Delete(context.Background(), w.ID.String())
+	errorCompare(t, err, entity.ErrRecordNotFound)
+}
+
+func addWorkspaceData(t *testing.T, repo *WorkspaceRepository) {
+	t.Helper()
+
+	// databaseを初期化する
+	db := repo.db
+	err := db.Exec("SET FOREIGN_KEY_CHECKS = 0").Error
+	err = db.Exec("TRUNCATE TABLE memberships").Error
+	err = db.Exec("TRUNCATE TABLE workspaces").Error
+	err = db.Exec("SET FOREIGN_KEY_CHECKS = 1").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func prepareWorkspaceT(t *testing.T) (workspace *WorkspaceRepository, membership *MembershipRepository, task *TaskRepository) {
+	t.Helper()
+
+	// dbに接続
+	db := NewTestDB()
+	workspace = NewWorkspaceRepository(db, logging.Discard())
+	membership = NewMembershipRepository(db, logging.Discard())
+	// Userデータの準備
+	task = prepareTaskT(t)
+
+	return
+}
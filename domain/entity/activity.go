@@ -0,0 +1,217 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityEntity はActivityで操作したエンティティの種類である
+type ActivityEntity string
+
+const (
+	ActivityTask ActivityEntity = "task"
+	ActivityUser ActivityEntity = "user"
+)
+
+// ActivityAction はActivityで行った操作である
+type ActivityAction string
+
+const (
+	ActionCreate   ActivityAction = "create"
+	ActionUpdate   ActivityAction = "update"
+	ActionDelete   ActivityAction = "delete"
+	ActionComplete ActivityAction = "complete"
+)
+
+// Activity はユーザーがTaskやUserに行った操作の記録である．記録した後に変更や削除はしない
+// ActorIDは操作したユーザーのIDであり，Actorは取得時にActorIDから読み込む(ユーザーを削除した後はnil)
+// Changesは操作で値が変わったフィールドである
+type Activity struct {
+	ID         NullString     `gorm:"primaryKey"`
+	ActorID    NullString     `gorm:"not null;index"`
+	Actor      *User          `gorm:"foreignKey:ActorID"`
+	EntityType ActivityEntity `gorm:"not null"`
+	EntityID   NullString     `gorm:"not null"`
+	Action     ActivityAction `gorm:"not null"`
+	Changes    Changes        `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// NewID はActivityのUUIDを生成
+func (a *Activity) NewID() *Activity {
+	a.ID = NewNullString(uuid.New().String())
+	return a
+}
+
+// MarshalJSON は操作したユーザーをIDではなくemailで表す
+func (a *Activity) MarshalJSON() ([]byte, error) {
+	var actor NullString
+	if a.Actor != nil {
+		actor = a.Actor.Email
+	}
+	changes := a.Changes
+	if changes == nil {
+		changes = Changes{}
+	}
+	return json.Marshal(&struct {
+		ID         NullString     `json:"id"`
+		Actor      NullString     `json:"actor"`
+		EntityType ActivityEntity `json:"entity_type"`
+		EntityID   NullString     `json:"entity_id"`
+		Action     ActivityAction `json:"action"`
+		Changes    Changes        `json:"changes"`
+		CreatedAt  time.Time      `json:"created_at"`
+	}{
+		ID:         a.ID,
+		Actor:      actor,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     a.Action,
+		Changes:    changes,
+		CreatedAt:  a.CreatedAt,
+	})
+}
+
+func (a *Activity) String() (str string) {
+	str = fmt.Sprintf("&entity.Activity{ID:%s, ActorID:%s, EntityType:%s, EntityID:%s, Action:%s, Changes:%v, CreatedAt:%s",
+		a.ID.String(), a.ActorID.String(), a.EntityType, a.EntityID.String(), a.Action, a.Changes, a.CreatedAt)
+	return
+}
+
+// ActivityPage はActivityの一覧の一部である
+// Totalは条件に一致するActivityの総数であり，Offset番目からLimit件をActivitiesに入れる
+type ActivityPage struct {
+	Total      int64       `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	Activities []*Activity `json:"activities"`
+}
+
+// Change はフィールドの変更前と変更後の値である．値がない場合はnilとする
+// パスワードのように値を残さないフィールドはBeforeとAfterをnilとして変わったことのみを表す
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Changes はActivityで変わったフィールドの一覧であり，databaseにはJSONとして保存する
+type Changes []Change
+
+// Scan はdatabaseのJSONをChangesにマッピングする
+func (c *Changes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("Invalid value type:%T", value)
+	}
+}
+
+// Value はChangesをJSONとしてdatabaseに保存する
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		c = Changes{}
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// taskFields はTaskChangesで比べるTaskのフィールドである．名前はTaskのJSONに合わせる
+var taskFields = []struct {
+	name  string
+	value func(t *Task) interface{}
+}{
+	{"title", func(t *Task) interface{} { return nullValue(t.Title) }},
+	{"content", func(t *Task) interface{} { return nullValue(t.Content) }},
+	{"project_id", func(t *Task) interface{} { return nullValue(t.ProjectID) }},
+	{"workspace_id", func(t *Task) interface{} { return nullValue(t.WorkspaceID) }},
+	{"parent_id", func(t *Task) interface{} { return nullValue(t.ParentID) }},
+	{"assignee", func(t *Task) interface{} {
+		if t.Assignee == nil {
+			return nil
+		}
+		return nullValue(t.Assignee.Email)
+	}},
+	{"iscomp", func(t *Task) interface{} { return t.IsCompleted }},
+	{"deadline", func(t *Task) interface{} {
+		if t.Deadline.IsNull() {
+			return nil
+		}
+		return t.Deadline.String()
+	}},
+	{"due_time", func(t *Task) interface{} { return nullValue(t.DueTime) }},
+	{"priority", func(t *Task) interface{} { return t.Priority.String() }},
+	{"position", func(t *Task) interface{} {
+		if t.Position == "" {
+			return nil
+		}
+		return t.Position
+	}},
+	{"recurrence", func(t *Task) interface{} { return nullValue(t.RRule) }},
+}
+
+// TaskChanges はTaskの変更前と変更後で値が異なるフィールドを返す
+// beforeがnilの場合は作成したTaskの値のあるフィールドを，afterがnilの場合は削除したTaskの値のあるフィールドを返す
+func TaskChanges(before, after *Task) Changes {
+	changes := Changes{}
+	for _, f := range taskFields {
+		var b, a interface{}
+		if before != nil {
+			b = f.value(before)
+		}
+		if after != nil {
+			a = f.value(after)
+		}
+		if b != a {
+			changes = append(changes, Change{Field: f.name, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+// userFields はUserChangesで比べるUserのフィールドである．名前はUserのJSONに合わせる
+var userFields = []struct {
+	name  string
+	value func(u *User) NullString
+}{
+	{"name", func(u *User) NullString { return u.Name }},
+	{"email", func(u *User) NullString { return u.Email }},
+	{"time_zone", func(u *User) NullString { return u.TimeZone }},
+}
+
+// UserChanges はUserの変更前と変更後で値が異なるフィールドをTaskChangesと同様に返す
+// パスワードは値を含めず，更新ではafterのパスワードがbeforeのハッシュと一致しない場合に変わったものとする
+func UserChanges(before, after *User) Changes {
+	changes := Changes{}
+	for _, f := range userFields {
+		var b, a interface{}
+		if before != nil {
+			b = nullValue(f.value(before))
+		}
+		if after != nil {
+			a = nullValue(f.value(after))
+		}
+		if b != a {
+			changes = append(changes, Change{Field: f.name, Before: b, After: a})
+		}
+	}
+	if before != nil && after != nil && !before.Password.Authenticate(&after.Password) {
+		changes = append(changes, Change{Field: "password"})
+	}
+	return changes
+}
+
+// nullValue はnullの場合にnilを，そうでない場合に文字列を返す
+func nullValue(s NullString) interface{} {
+	if s.IsNull() {
+		return nil
+	}
+	return s.String()
+}
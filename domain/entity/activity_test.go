@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTaskChanges(t *testing.T) {
+	before := NewTask("id", "title", "", "uid", "2020-12-27")
+	after := NewTask("id", "renamed", "content", "uid", "2020-12-27").SetComp(true)
+	after.Assignee = NewUser("uid", "username", "", "user@example.com")
+
+	tests := []struct {
+		name   string
+		before *Task
+		after  *Task
+		want   Changes
+	}{
+		{
+			name:   "変わったフィールドのみを返す",
+			before: before,
+			after:  after,
+			want: Changes{
+				{Field: "title", Before: "title", After: "renamed"},
+				{Field: "content", Before: nil, After: "content"},
+				{Field: "assignee", Before: nil, After: "user@example.com"},
+				{Field: "iscomp", Before: false, After: true},
+			},
+		},
+		{
+			name:   "作成では値のあるフィールドを返す",
+			before: nil,
+			after:  before,
+			want: Changes{
+				{Field: "title", Before: nil, After: "title"},
+				{Field: "iscomp", Before: nil, After: false},
+				{Field: "deadline", Before: nil, After: "2020-12-27"},
+				{Field: "priority", Before: nil, After: "none"},
+			},
+		},
+		{
+			name:   "変わらなければ空",
+			before: before,
+			after:  before,
+			want:   Changes{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TaskChanges(tt.before, tt.after)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("TaskChanges() (-want +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChanges_Scan(t *testing.T) {
+	want := Changes{{Field: "title", Before: "title", After: "renamed"}, {Field: "password"}}
+	v, err := want.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Changes
+	err = got.Scan([]byte(v.(string)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Scan() (-want +got) =\n%s", diff)
+	}
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxAssignmentAttempts は通知に失敗した担当者の変更を再送する回数の上限である
+const MaxAssignmentAttempts = 5
+
+// Assignment はTaskの担当者の変更の記録である
+// AssigneeIDは新しい担当者であり，nullの場合は担当者を外したことを表す．AssignerIDは変更したユーザーである
+// NotifiedAtは担当者に通知した時刻であり，nullの場合はまだ通知していない
+// ClaimedUntilは通知するために確保した期限であり，その間は他のサーバーが同じ変更を通知しない
+type Assignment struct {
+	ID           NullString `gorm:"primaryKey"`
+	TaskID       NullString `gorm:"not null;index"`
+	AssigneeID   NullString
+	AssignerID   NullString `gorm:"not null"`
+	NotifiedAt   *time.Time
+	Attempts     int `gorm:"not null"`
+	ClaimedUntil *time.Time
+	CreatedAt    time.Time
+}
+
+// NewID はAssignmentのUUIDを生成
+func (a *Assignment) NewID() *Assignment {
+	a.ID = NewNullString(uuid.New().String())
+	return a
+}
+
+func (a *Assignment) String() (str string) {
+	str = fmt.Sprintf("&entity.Assignment{ID:%s, TaskID:%s, AssigneeID:%s, AssignerID:%s, NotifiedAt:%v, Attempts:%d, CreatedAt:%s",
+		a.ID.String(), a.TaskID.String(), a.AssigneeID.String(), a.AssignerID.String(), a.NotifiedAt, a.Attempts, a.CreatedAt)
+	return
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxOrphanBlobAttempts は削除に失敗したファイルの削除を再び試みる回数の上限である
+const MaxOrphanBlobAttempts = 5
+
+// ErrQuotaExceeded はファイルを添付するとユーザーの容量の上限を超えることを示す
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Attachment はTaskに添付したファイルである
+// UserIDは添付したユーザーのIDであり，添付したファイルの合計のSizeがユーザーの容量の上限を超えないようにする
+// BlobKeyはファイルの中身を保存したBlobStoreのキーである
+type Attachment struct {
+	ID          NullString `gorm:"primaryKey" json:"id"`
+	TaskID      NullString `gorm:"not null;index" json:"task_id"`
+	UserID      NullString `gorm:"not null;index" json:"-"`
+	Name        NullString `gorm:"not null" json:"name"`
+	ContentType NullString `gorm:"not null" json:"content_type"`
+	Size        int64      `gorm:"not null" json:"size"`
+	BlobKey     NullString `gorm:"not null" json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// NewID はAttachmentのUUIDを生成
+func (a *Attachment) NewID() *Attachment {
+	a.ID = NewNullString(uuid.New().String())
+	return a
+}
+
+func (a *Attachment) String() (str string) {
+	str = fmt.Sprintf("&entity.Attachment{ID:%s, TaskID:%s, UserID:%s, Name:%s, ContentType:%s, Size:%d, BlobKey:%s, CreatedAt:%s",
+		a.ID.String(), a.TaskID.String(), a.UserID.String(), a.Name.String(), a.ContentType.String(), a.Size, a.BlobKey.String(), a.CreatedAt)
+	return
+}
+
+// OrphanBlob はAttachmentを削除した後にBlobStoreから削除するファイルのキーである
+// Taskの削除と同じトランザクションで記録し，スケジューラーがBlobStoreから削除する
+type OrphanBlob struct {
+	ID        NullString `gorm:"primaryKey"`
+	BlobKey   NullString `gorm:"not null"`
+	Attempts  int        `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// NewID はOrphanBlobのUUIDを生成
+func (o *OrphanBlob) NewID() *OrphanBlob {
+	o.ID = NewNullString(uuid.New().String())
+	return o
+}
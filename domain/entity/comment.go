@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCommentLength はCommentの本文の文字数の上限である
+const MaxCommentLength = 10000
+
+// Comment はTaskに付けるコメントである
+// UserIDはコメントを書いたユーザーのIDであり，Authorは取得時にUserIDから読み込む
+// Editedは書いた後に本文を編集したかを表す
+type Comment struct {
+	ID        NullString `gorm:"primaryKey"`
+	TaskID    NullString `gorm:"not null;index"`
+	UserID    NullString `gorm:"not null"`
+	Author    *User      `gorm:"foreignKey:UserID"`
+	Body      NullString `gorm:"not null"`
+	Edited    bool       `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewID はCommentのUUIDを生成
+func (c *Comment) NewID() *Comment {
+	c.ID = NewNullString(uuid.New().String())
+	return c
+}
+
+// MarshalJSON はCommentを書いたユーザーをIDではなくemailで表す
+func (c *Comment) MarshalJSON() ([]byte, error) {
+	var author NullString
+	if c.Author != nil {
+		author = c.Author.Email
+	}
+	return json.Marshal(&struct {
+		ID        NullString `json:"id"`
+		TaskID    NullString `json:"task_id"`
+		Author    NullString `json:"author"`
+		Body      NullString `json:"body"`
+		Edited    bool       `json:"edited"`
+		CreatedAt time.Time  `json:"created_at"`
+		UpdatedAt time.Time  `json:"updated_at"`
+	}{
+		ID:        c.ID,
+		TaskID:    c.TaskID,
+		Author:    author,
+		Body:      c.Body,
+		Edited:    c.Edited,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	})
+}
+
+func (c *Comment) String() (str string) {
+	str = fmt.Sprintf("&entity.Comment{ID:%s, TaskID:%s, UserID:%s, Body:%s, Edited:%t, CreatedAt:%s, UpdatedAt: %s",
+		c.ID.String(), c.TaskID.String(), c.UserID.String(), c.Body.String(), c.Edited, c.CreatedAt, c.UpdatedAt)
+	return
+}
+
+// CommentPage はTaskのCommentの一覧の一部である
+// TotalはTaskのCommentの総数であり，Offset番目からLimit件をCommentsに入れる
+type CommentPage struct {
+	Total    int64      `json:"total"`
+	Limit    int        `json:"limit"`
+	Offset   int        `json:"offset"`
+	Comments []*Comment `json:"comments"`
+}
@@ -6,21 +6,37 @@ import (
 
 	// "github.com/VividCortex/mysqlerr"
 	"github.com/go-sql-driver/mysql"
-	"github.com/jinzhu/gorm"
+	"gorm.io/gorm"
 )
 
-//Errors of jinzhu/gorm
+//Errors of go-gorm/gorm
 var (
-	// ErrRecordNotFound returns a "record not found error". Occurs only when attempting to query the database with a struct; querying with a slice won't return this error
+	// ErrRecordNotFound record not found error
 	ErrRecordNotFound = gorm.ErrRecordNotFound
-	// ErrInvalidSQL occurs when you attempt a query with invalid SQL
-	ErrInvalidSQL = gorm.ErrInvalidSQL
-	// ErrInvalidTransaction occurs when you are trying to `Commit` or `Rollback`
+	// ErrInvalidTransaction invalid transaction when you are trying to `Commit` or `Rollback`
 	ErrInvalidTransaction = gorm.ErrInvalidTransaction
-	// ErrCantStartTransaction can't start transaction when you are trying to start one with `Begin`
-	ErrCantStartTransaction = gorm.ErrCantStartTransaction
-	// ErrUnaddressable unaddressable value
-	ErrUnaddressable = gorm.ErrUnaddressable
+	// ErrNotImplemented not implemented
+	ErrNotImplemented = gorm.ErrNotImplemented
+	// ErrMissingWhereClause missing where clause
+	ErrMissingWhereClause = gorm.ErrMissingWhereClause
+	// ErrUnsupportedRelation unsupported relations
+	ErrUnsupportedRelation = gorm.ErrUnsupportedRelation
+	// ErrPrimaryKeyRequired primary keys required
+	ErrPrimaryKeyRequired = gorm.ErrPrimaryKeyRequired
+	// ErrModelValueRequired model value required
+	ErrModelValueRequired = gorm.ErrModelValueRequired
+	// ErrInvalidData unsupported data
+	ErrInvalidData = gorm.ErrInvalidData
+	// ErrUnsupportedDriver unsupported driver
+	ErrUnsupportedDriver = gorm.ErrUnsupportedDriver
+	// ErrRegistered registered
+	ErrRegistered = gorm.ErrRegistered
+	// ErrInvalidField invalid field
+	ErrInvalidField = gorm.ErrInvalidField
+	// ErrEmptySlice empty slice found
+	ErrEmptySlice = gorm.ErrEmptySlice
+	// ErrDryRunModeUnsupported dry run mode unsupported
+	ErrDryRunModeUnsupported = gorm.ErrDryRunModeUnsupported
 )
 
 // Errors of go-sql-driver/mysql. Various errors the driver might return. Can change between driver versions.
@@ -63,33 +79,3 @@ func (me *ErrMySQL) Error() string {
 // var (
 // 	ErrMySQLBadNullError = mysqlerr.ER_BAD_NULL_ERROR // 1048
 // )
-
-// //Errors of go-gorm/gorm
-// var (
-// 	// ErrRecordNotFound record not found error
-// 	ErrRecordNotFound = gorm.ErrRecordNotFound
-// 	// ErrInvalidTransaction invalid transaction when you are trying to `Commit` or `Rollback`
-// 	ErrInvalidTransaction = gorm.ErrInvalidTransaction
-// 	// ErrNotImplemented not implemented
-// 	ErrNotImplemented = gorm.ErrNotImplemented
-// 	// ErrMissingWhereClause missing where clause
-// 	ErrMissingWhereClause = gorm.ErrMissingWhereClause
-// 	// ErrUnsupportedRelation unsupported relations
-// 	ErrUnsupportedRelation = gorm.ErrUnsupportedRelation
-// 	// ErrPrimaryKeyRequired primary keys required
-// 	ErrPrimaryKeyRequired = gorm.ErrPrimaryKeyRequired
-// 	// ErrModelValueRequired model value required
-// 	ErrModelValueRequired = gorm.ErrModelValueRequired
-// 	// ErrInvalidData unsupported data
-// 	ErrInvalidData = gorm.ErrInvalidData
-// 	// ErrUnsupportedDriver unsupported driver
-// 	ErrUnsupportedDriver = gorm.ErrUnsupportedDriver
-// 	// ErrRegistered registered
-// 	ErrRegistered = gorm.ErrRegistered
-// 	// ErrInvalidField invalid field
-// 	ErrInvalidField = gorm.ErrInvalidField
-// 	// ErrEmptySlice empty slice found
-// 	ErrEmptySlice = gorm.ErrEmptySlice
-// 	// ErrDryRunModeUnsupported dry run mode unsupported
-// 	ErrDryRunModeUnsupported = gorm.ErrDryRunModeUnsupported
-// )
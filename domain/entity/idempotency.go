@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// MaxIdempotencyKeyLength はIdempotency-Keyの長さの上限である
+const MaxIdempotencyKeyLength = 255
+
+// AnonymousIdempotencyScope はログインしていないリクエストのキーをIPアドレスごとに区別するための接頭辞である
+const AnonymousIdempotencyScope = "anonymous:"
+
+// IdempotencyKey はIdempotency-Keyを指定したリクエストとそのレスポンスの記録である
+// UserIDとKeyの組は一意であり，同じキーのリクエストが同時に届いてもひとつだけが処理される
+// ログインしていないリクエストのUserIDはAnonymousIdempotencyScopeにクライアントのIPアドレスを付けたものとする
+type IdempotencyKey struct {
+	UserID      string `gorm:"primaryKey"`
+	Key         string `gorm:"primaryKey;column:idempotency_key"`
+	Fingerprint string `gorm:"not null"`
+	StatusCode  int    `gorm:"not null"` // 処理中の場合は0
+	ContentType string `gorm:"not null"`
+	ETag        string `gorm:"column:etag;not null"`
+	Body        []byte
+	// LockedUntil は処理中の記録を確保しておく期限である．過ぎた記録は処理したサーバーが停止したものとして他のリクエストが引き継ぐ
+	LockedUntil time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewIdempotencyKey is the constructor of IdempotencyKey.
+func NewIdempotencyKey(uid, key, fingerprint string, lockedUntil, expiresAt time.Time) *IdempotencyKey {
+	return &IdempotencyKey{UserID: uid, Key: key, Fingerprint: fingerprint, LockedUntil: lockedUntil, ExpiresAt: expiresAt}
+}
+
+// IsCompleted はレスポンスが保存されているかを返す
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
+}
+
+// NewFingerprint はリクエストのメソッド，パスとbodyから同じリクエストかを判定するための値を返す
+func NewFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "testing"
+
+func TestNewFingerprint(t *testing.T) {
+	base := NewFingerprint("POST", "/api/v1/task", []byte(`{"title":"a"}`))
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   bool
+	}{
+		{name: "同じリクエストは一致する", method: "POST", path: "/api/v1/task", body: `{"title":"a"}`, want: true},
+		{name: "bodyが異なれば一致しない", method: "POST", path: "/api/v1/task", body: `{"title":"b"}`, want: false},
+		{name: "パスが異なれば一致しない", method: "POST", path: "/api/v1/user", body: `{"title":"a"}`, want: false},
+		{name: "メソッドが異なれば一致しない", method: "PUT", path: "/api/v1/task", body: `{"title":"a"}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewFingerprint(tt.method, tt.path, []byte(tt.body)) == base; got != tt.want {
+				t.Errorf("NewFingerprint() matched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
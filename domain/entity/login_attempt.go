@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// LoginAttempt はアカウントごとの連続したログイン失敗の記録である
+type LoginAttempt struct {
+	Account     string     `gorm:"primaryKey"`
+	Failures    int        `gorm:"not null"`
+	LockedUntil *time.Time // ロックされていない場合はnil
+	UpdatedAt   time.Time
+}
+
+// NewLoginAttempt is the constructor of LoginAttempt.
+func NewLoginAttempt(account string) *LoginAttempt {
+	return &LoginAttempt{Account: account}
+}
+
+// IsLocked はnowの時点でアカウントがロックされているか判定する
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// RetryAfter はロックが解除されるまでの時間を返す
+func (a *LoginAttempt) RetryAfter(now time.Time) time.Duration {
+	if !a.IsLocked(now) {
+		return 0
+	}
+	return a.LockedUntil.Sub(now)
+}
+
+// Lock はlockが0より大きければその期間アカウントをロックする
+func (a *LoginAttempt) Lock(now time.Time, lock time.Duration) {
+	if lock > 0 {
+		until := now.Add(lock)
+		a.LockedUntil = &until
+	}
+}
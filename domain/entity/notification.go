@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// NotificationKind は通知の種類である
+type NotificationKind string
+
+const (
+	// NotificationReminder はTaskの期限のReminderの通知
+	NotificationReminder NotificationKind = "reminder"
+	// NotificationAssignment はTaskの担当者になったことの通知
+	NotificationAssignment NotificationKind = "assignment"
+)
+
+// Notification はユーザーに送る通知である
+type Notification struct {
+	Kind    NotificationKind `json:"kind"`
+	UserID  string           `json:"user_id"`
+	Email   string           `json:"email"`
+	TaskID  string           `json:"task_id"`
+	Subject string           `json:"subject"`
+	Body    string           `json:"body"`
+	At      time.Time        `json:"at"`
+}
@@ -25,6 +25,17 @@ func NewNullDate(s string) NullDate {
 	return *date
 }
 
+// NewNullDateFromTime はtのタイムゾーンでのtの日付をNullDateにする
+func NewNullDateFromTime(t time.Time) NullDate {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	return NullDate{sql.NullTime{Time: date, Valid: true}}
+}
+
+// Today はlocのタイムゾーンでのnowの日付を返す
+func Today(now time.Time, loc *time.Location) NullDate {
+	return NewNullDateFromTime(now.In(loc))
+}
+
 func (d *NullDate) Set(str string) error {
 	new, err := newNullDate(str)
 	if err != nil {
@@ -0,0 +1,27 @@
+package entity
+
+const (
+	// DefaultPageLimit は一覧の1ページの件数を指定しない場合の件数である
+	DefaultPageLimit = 20
+	// MaxPageLimit は一覧の1ページの件数の上限である
+	MaxPageLimit = 100
+)
+
+// Page は一覧のうちOffset番目からLimit件を取得することを表す
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// NewPage is the constructor of Page.(limitが0の場合はDefaultPageLimitとする)
+func NewPage(limit, offset int) Page {
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	return Page{Limit: limit, Offset: offset}
+}
+
+// IsValid はLimitが1からMaxPageLimitまでで，Offsetが負でないかを返す
+func (p Page) IsValid() bool {
+	return p.Limit >= 1 && p.Limit <= MaxPageLimit && p.Offset >= 0
+}
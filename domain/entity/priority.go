@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority はTaskの優先度である．値が大きいほど優先度が高い
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// ParsePriority は文字列からPriorityを取得する．空文字列はPriorityNoneとして扱う
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNone, nil
+	}
+	for i, name := range priorityNames {
+		if name == s {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("%s is invalid priority", s)
+}
+
+func (p Priority) String() string {
+	if !p.IsValid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// IsValid は定義されたPriorityかどうかを返す
+func (p Priority) IsValid() bool {
+	return p >= PriorityNone && p <= PriorityUrgent
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+	*p, err = ParsePriority(str)
+	return err
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Project はTaskをまとめるリストである．Projectに属さないTaskはインボックスにあるものとして扱う
+// TaskCountとCompletedCountは取得時に集計する
+// Roleは他のユーザーから共有されたProjectに対する権限であり，自分のProjectの場合は空である
+type Project struct {
+	ID             NullString `gorm:"primaryKey" json:"id"`
+	Name           NullString `gorm:"not null" json:"name"`
+	UserID         NullString `gorm:"not null;index"`
+	TaskCount      int        `gorm:"-" json:"task_count"`
+	CompletedCount int        `gorm:"-" json:"completed_count"`
+	Role           Role       `gorm:"-" json:"role,omitempty"`
+	CreatedAt      time.Time  `json:"-"`
+	UpdatedAt      time.Time  `json:"-"`
+}
+
+// MarshalJSON はjsonにエンコードするときにUserIDフィールドを隠す
+func (p *Project) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID             NullString `json:"id"`
+		Name           NullString `json:"name"`
+		TaskCount      int        `json:"task_count"`
+		CompletedCount int        `json:"completed_count"`
+		Role           Role       `json:"role,omitempty"`
+	}{
+		ID:             p.ID,
+		Name:           p.Name,
+		TaskCount:      p.TaskCount,
+		CompletedCount: p.CompletedCount,
+		Role:           p.Role,
+	})
+}
+
+// NewProject is the constructor of Project.(値が""の場合はsql.NullStringのnullとして扱う)
+func NewProject(id string, name string, userid string) (p *Project) {
+	p = &Project{
+		ID:     NewNullString(id),
+		Name:   NewNullString(name),
+		UserID: NewNullString(userid),
+	}
+	return
+}
+
+// NewID はProjectのUUIDを生成
+func (p *Project) NewID() *Project {
+	p.ID = NewNullString(uuid.New().String())
+	return p
+}
+
+func (p *Project) String() (str string) {
+	str = fmt.Sprintf("&entity.Project{ID:%s, Name:%s, UserID:%s, TaskCount:%d, CompletedCount:%d, CreatedAt:%s, UpdatedAt: %s",
+		p.ID.String(), p.Name.String(), p.UserID.String(), p.TaskCount, p.CompletedCount, p.CreatedAt, p.UpdatedAt)
+	return
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+Rank はTaskの並び順を表す文字列である．
+辞書順で比較し，任意の2つのRankの間に新しいRankを作れるので，
+ひとつのTaskを移動するときに他のTaskの順番を振り直す必要がない．
+末尾が最小の文字("0")にならないようにすることで，常に間に挿入できるようにしている．
+*/
+
+// rankDigits はRankに使う文字．ASCIIの順に並べている
+const rankDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength はRankの最大長．これを超える場合は順番を振り直す
+const MaxRankLength = 64
+
+var (
+	// ErrInvalidRank Rankの形式が不正か，前後関係が逆
+	ErrInvalidRank = errors.New("invalid rank")
+	// ErrRankTooLong 間に挿入し続けてRankが長くなりすぎた
+	ErrRankTooLong = errors.New("rank too long")
+)
+
+// RankBetween はprevとnextの間に並ぶRankを返す
+// prevが空の場合は先頭，nextが空の場合は末尾として扱う
+func RankBetween(prev, next string) (string, error) {
+	if !validRank(prev) || !validRank(next) || (next != "" && prev >= next) {
+		return "", ErrInvalidRank
+	}
+
+	base := len(rankDigits)
+	var rank strings.Builder
+	// nextが空のとき，またはprevとの共通部分より後ろは上限なしとして扱う
+	unbounded := next == ""
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(prev) {
+			lo = strings.IndexByte(rankDigits, prev[i])
+		}
+		hi := base
+		if !unbounded && i < len(next) {
+			hi = strings.IndexByte(rankDigits, next[i])
+		}
+
+		if hi-lo > 1 {
+			rank.WriteByte(rankDigits[(lo+hi)/2])
+			break
+		}
+		rank.WriteByte(rankDigits[lo])
+		if hi-lo == 1 {
+			// この桁でprevより大きくなったので，以降はprevより大きければよい
+			unbounded = true
+		}
+	}
+
+	if rank.Len() > MaxRankLength {
+		return "", ErrRankTooLong
+	}
+	return rank.String(), nil
+}
+
+// EvenRanks は等間隔に並んだn個のRankを返す．順番を振り直すときに使う
+func EvenRanks(n int) []string {
+	base := len(rankDigits)
+	// n個を表すのに必要な桁数
+	digits := 1
+	for capacity := base - 1; capacity < n; capacity *= base {
+		digits++
+	}
+	space := 1
+	for i := 0; i < digits; i++ {
+		space *= base
+	}
+	step := space / (n + 1)
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		v := step * (i + 1)
+		b := make([]byte, digits)
+		for j := digits - 1; j >= 0; j-- {
+			b[j] = rankDigits[v%base]
+			v /= base
+		}
+		ranks[i] = strings.TrimRight(string(b), "0")
+	}
+	return ranks
+}
+
+// validRank はrankに使えない文字が含まれておらず，末尾が"0"でないことを確認する
+func validRank(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(rank, "0")
+}
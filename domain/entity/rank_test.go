@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		prev    string
+		next    string
+		wantErr error
+	}{
+		{name: "空の場合", prev: "", next: ""},
+		{name: "先頭に挿入", prev: "", next: "V"},
+		{name: "末尾に挿入", prev: "V", next: ""},
+		{name: "間に挿入", prev: "A", next: "B"},
+		{name: "隣り合う桁の間に挿入", prev: "Az", next: "B"},
+		{name: "prefixの間に挿入", prev: "A", next: "A1"},
+		{name: "最小の文字の前に挿入", prev: "", next: "01"},
+		{name: "最大の文字の後に挿入", prev: "zz", next: ""},
+		{name: "前後関係が逆ならErrInvalidRank", prev: "B", next: "A", wantErr: ErrInvalidRank},
+		{name: "同じならErrInvalidRank", prev: "A", next: "A", wantErr: ErrInvalidRank},
+		{name: "使えない文字ならErrInvalidRank", prev: "a-b", next: "", wantErr: ErrInvalidRank},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RankBetween(tt.prev, tt.next)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Error (-want +got) =\n- %v\n+ %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got <= tt.prev || (tt.next != "" && got >= tt.next) || !validRank(got) {
+				t.Errorf("RankBetween(%q, %q) = %q", tt.prev, tt.next, got)
+			}
+		})
+	}
+}
+
+func TestRankBetween_Repeated(t *testing.T) {
+	// 同じ位置に挿入し続けても順序が保たれ，長さは100回で数十文字以内に収まる
+	prev, next := "A", "B"
+	for i := 0; i < 100; i++ {
+		r, err := RankBetween(prev, next)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if r <= prev || r >= next {
+			t.Fatalf("#%d: RankBetween(%q, %q) = %q", i, prev, next, r)
+		}
+		next = r
+	}
+	if len(next) > 25 {
+		t.Errorf("rank grows too fast: %q", next)
+	}
+}
+
+func TestEvenRanks(t *testing.T) {
+	for _, n := range []int{1, 10, 61, 62, 1000} {
+		ranks := EvenRanks(n)
+		if len(ranks) != n {
+			t.Fatalf("len(EvenRanks(%d)) = %d", n, len(ranks))
+		}
+		for i, r := range ranks {
+			if !validRank(r) || r == "" {
+				t.Fatalf("EvenRanks(%d)[%d] = %q is invalid", n, i, r)
+			}
+			if i > 0 && ranks[i-1] >= r {
+				t.Fatalf("EvenRanks(%d) is not sorted: %q >= %q", n, ranks[i-1], r)
+			}
+		}
+	}
+}
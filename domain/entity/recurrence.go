@@ -0,0 +1,333 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence はRRULEが不正であることを示す
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// maxRecurrencePeriods は回のない周期(日，週，月)が続いたときに次の回を探すのをやめる数である
+// BYDAYに一致する日がないDAILYの規則などで探し続けないようにする
+const maxRecurrencePeriods = 1000
+
+// Frequency は繰り返しの周期である
+type Frequency int
+
+const (
+	FreqDaily Frequency = iota
+	FreqWeekly
+	FreqMonthly
+)
+
+var frequencyNames = []string{"DAILY", "WEEKLY", "MONTHLY"}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ByDay はRRULEのBYDAYの要素である．Ordinalが0でない場合は月のうち何番目(負の場合は最後から何番目)の曜日かを表す
+type ByDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+func (b ByDay) String() string {
+	if b.Ordinal == 0 {
+		return weekdayNames[b.Weekday]
+	}
+	return strconv.Itoa(b.Ordinal) + weekdayNames[b.Weekday]
+}
+
+/*
+Recurrence はTaskの繰り返しの規則であり，iCalendar(RFC 5545)のRRULEのうち
+FREQ(DAILY, WEEKLY, MONTHLY)，INTERVAL，BYDAY，UNTIL，COUNTに対応する．
+TaskのDeadlineを最初の回(DTSTART)とし，COUNTはその回を含めた残りの回数を表す．
+*/
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []ByDay
+	Until    NullDate
+	Count    int
+}
+
+// ParseRecurrence はRRULE(先頭の"RRULE:"は省略可)をRecurrenceに変換する
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &Recurrence{Freq: -1, Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || seen[kv[0]] {
+			return nil, ErrInvalidRecurrence
+		}
+		seen[kv[0]] = true
+		key, value := kv[0], kv[1]
+		var err error
+		switch key {
+		case "FREQ":
+			for i, name := range frequencyNames {
+				if value == name {
+					r.Freq = Frequency(i)
+				}
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if r.Interval < 1 {
+				err = ErrInvalidRecurrence
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if r.Count < 1 {
+				err = ErrInvalidRecurrence
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		default:
+			err = ErrInvalidRecurrence
+		}
+		if err != nil {
+			return nil, ErrInvalidRecurrence
+		}
+	}
+	if r.Freq < 0 {
+		return nil, ErrInvalidRecurrence
+	}
+	// RFC 5545ではUNTILとCOUNTを同時に指定できない
+	if r.Count > 0 && !r.Until.IsNull() {
+		return nil, ErrInvalidRecurrence
+	}
+	// 何番目の曜日かはMONTHLYでのみ指定できる
+	for _, b := range r.ByDay {
+		if b.Ordinal != 0 && r.Freq != FreqMonthly {
+			return nil, ErrInvalidRecurrence
+		}
+	}
+	return r, nil
+}
+
+// parseUntil はUNTILの日付(YYYYMMDDまたはYYYYMMDDThhmmssZ)を取得する
+func parseUntil(value string) (NullDate, error) {
+	if len(value) != 8 && !(len(value) == 16 && value[8] == 'T' && value[15] == 'Z') {
+		return NullDate{}, ErrInvalidRecurrence
+	}
+	var until NullDate
+	err := until.Set(value[0:4] + "-" + value[4:6] + "-" + value[6:8])
+	return until, err
+}
+
+func parseByDay(value string) ([]ByDay, error) {
+	days := []ByDay{}
+	for _, s := range strings.Split(value, ",") {
+		if len(s) < 2 {
+			return nil, ErrInvalidRecurrence
+		}
+		b := ByDay{Weekday: -1}
+		for i, name := range weekdayNames {
+			if s[len(s)-2:] == name {
+				b.Weekday = time.Weekday(i)
+			}
+		}
+		if b.Weekday < 0 {
+			return nil, ErrInvalidRecurrence
+		}
+		if ordinal := s[:len(s)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, ErrInvalidRecurrence
+			}
+			b.Ordinal = n
+		}
+		days = append(days, b)
+	}
+	return days, nil
+}
+
+// String はRecurrenceをRRULE("RRULE:"を除く)に変換する
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, b := range r.ByDay {
+			days[i] = b.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if !r.Until.IsNull() {
+		parts = append(parts, "UNTIL="+r.Until.GetTime().Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next はstartを最初の回としたときの次の回の日付を返す．次の回がない場合はfalseを返す
+func (r *Recurrence) Next(start time.Time) (next time.Time, ok bool) {
+	first := true
+	r.each(start, start, func(date time.Time) bool {
+		if first {
+			first = false
+			return true
+		}
+		next, ok = date, true
+		return false
+	})
+	return
+}
+
+// Between はstartを最初の回としたときのfromからtoまで(両端を含む)の回の日付を返す
+func (r *Recurrence) Between(start, from, to time.Time) []time.Time {
+	dates := []time.Time{}
+	r.each(start, from, func(date time.Time) bool {
+		if date.After(to) {
+			return false
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+		return true
+	})
+	return dates
+}
+
+// After はCOUNTを次の回以降の残りの回数にしたRecurrenceを返す
+func (r *Recurrence) After() *Recurrence {
+	next := *r
+	if next.Count > 0 {
+		next.Count--
+	}
+	return &next
+}
+
+// each はstartから順に回の日付をfnに渡す．fnがfalseを返すか，回がなくなると終了する
+// COUNTがない場合はfromより前の周期を飛ばすので，fromより前の回は最初の回を除いて渡さない
+func (r *Recurrence) each(start, from time.Time, fn func(date time.Time) bool) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	count := 0
+	emit := func(date time.Time) bool {
+		if !r.Until.IsNull() && date.After(r.Until.GetTime()) {
+			return false
+		}
+		if r.Count > 0 && count >= r.Count {
+			return false
+		}
+		count++
+		return fn(date)
+	}
+
+	// startはRRULEに一致しなくても最初の回とする
+	if !emit(start) {
+		return
+	}
+	// COUNTは最初の回から数えるので飛ばさない
+	period := 0
+	if r.Count == 0 {
+		period = r.periodBefore(start, from)
+	}
+	for empty := 0; empty < maxRecurrencePeriods; period++ {
+		empty++
+		for _, date := range r.candidates(start, period) {
+			if !date.After(start) {
+				continue
+			}
+			if !emit(date) {
+				return
+			}
+			empty = 0
+		}
+	}
+}
+
+// periodBefore はfromより前の回しか含まない周期を飛ばしたときに最初に調べる周期を返す
+func (r *Recurrence) periodBefore(start, from time.Time) int {
+	var period int
+	switch r.Freq {
+	case FreqDaily:
+		period = daysBetween(start, from) / r.Interval
+	case FreqWeekly:
+		// 週の途中の日を含めるためにひとつ前の周期から調べる
+		period = daysBetween(start, from)/(7*r.Interval) - 1
+	default:
+		period = ((from.Year()-start.Year())*12 + int(from.Month()-start.Month())) / r.Interval
+	}
+	if period < 0 {
+		return 0
+	}
+	return period
+}
+
+// daysBetween はfromからtoまでの日数を返す．夏時間の影響を受けないようにUTCの日付で数える
+func daysBetween(from, to time.Time) int {
+	f := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	t := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(f).Hours() / 24)
+}
+
+// candidates はperiod番目の周期に含まれる日付を昇順に返す
+func (r *Recurrence) candidates(start time.Time, period int) []time.Time {
+	switch r.Freq {
+	case FreqDaily:
+		date := start.AddDate(0, 0, period*r.Interval)
+		if len(r.ByDay) > 0 && !r.hasWeekday(date.Weekday()) {
+			return nil
+		}
+		return []time.Time{date}
+	case FreqWeekly:
+		// 週は月曜日から始まる(WKST=MO)
+		monday := start.AddDate(0, 0, -(int(start.Weekday())+6)%7+period*r.Interval*7)
+		dates := []time.Time{}
+		for i := 0; i < 7; i++ {
+			date := monday.AddDate(0, 0, i)
+			if (len(r.ByDay) == 0 && date.Weekday() == start.Weekday()) || r.hasWeekday(date.Weekday()) {
+				dates = append(dates, date)
+			}
+		}
+		return dates
+	default:
+		first := time.Date(start.Year(), start.Month()+time.Month(period*r.Interval), 1, 0, 0, 0, 0, start.Location())
+		days := first.AddDate(0, 1, -1).Day()
+		if len(r.ByDay) == 0 {
+			// その月に同じ日がない場合は飛ばす
+			if start.Day() > days {
+				return nil
+			}
+			return []time.Time{first.AddDate(0, 0, start.Day()-1)}
+		}
+		dates := []time.Time{}
+		for day := 1; day <= days; day++ {
+			date := first.AddDate(0, 0, day-1)
+			for _, b := range r.ByDay {
+				if b.Weekday != date.Weekday() {
+					continue
+				}
+				if b.Ordinal == 0 || b.Ordinal == (day-1)/7+1 || b.Ordinal == -((days-day)/7+1) {
+					dates = append(dates, date)
+					break
+				}
+			}
+		}
+		return dates
+	}
+}
+
+func (r *Recurrence) hasWeekday(weekday time.Weekday) bool {
+	for _, b := range r.ByDay {
+		if b.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
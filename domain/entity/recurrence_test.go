@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr error
+	}{
+		{name: "FREQのみ", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "RRULE:を省略しない", rule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE", want: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{name: "INTERVAL=1は省略する", rule: "FREQ=WEEKLY;INTERVAL=1", want: "FREQ=WEEKLY"},
+		{name: "UNTILの時刻は無視する", rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20201231T000000Z", want: "FREQ=DAILY;INTERVAL=2;UNTIL=20201231"},
+		{name: "MONTHLYは何番目の曜日かを指定できる", rule: "FREQ=MONTHLY;BYDAY=1MO,-1FR;COUNT=3", want: "FREQ=MONTHLY;BYDAY=1MO,-1FR;COUNT=3"},
+		{name: "FREQがないならErrInvalidRecurrence", rule: "INTERVAL=2", wantErr: ErrInvalidRecurrence},
+		{name: "対応していないFREQならErrInvalidRecurrence", rule: "FREQ=YEARLY", wantErr: ErrInvalidRecurrence},
+		{name: "対応していない項目ならErrInvalidRecurrence", rule: "FREQ=DAILY;BYHOUR=9", wantErr: ErrInvalidRecurrence},
+		{name: "UNTILとCOUNTを同時に指定したらErrInvalidRecurrence", rule: "FREQ=DAILY;UNTIL=20201231;COUNT=3", wantErr: ErrInvalidRecurrence},
+		{name: "INTERVALが0ならErrInvalidRecurrence", rule: "FREQ=DAILY;INTERVAL=0", wantErr: ErrInvalidRecurrence},
+		{name: "WEEKLYで何番目の曜日かを指定したらErrInvalidRecurrence", rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: ErrInvalidRecurrence},
+		{name: "存在しない曜日ならErrInvalidRecurrence", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: ErrInvalidRecurrence},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecurrence(tt.rule)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Error (-want +got) =\n- %v\n+ %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && got.String() != tt.want {
+				t.Errorf("String (-want +got) =\n- %s\n+ %s", tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestRecurrence_Between(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		from  string
+		to    string
+		want  []string
+	}{
+		{
+			name:  "2日ごと",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: "2020-12-01", from: "2020-12-01", to: "2020-12-07",
+			want: []string{"2020-12-01", "2020-12-03", "2020-12-05", "2020-12-07"},
+		},
+		{
+			name:  "平日のみ",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: "2020-12-04", from: "2020-12-04", to: "2020-12-08",
+			want: []string{"2020-12-04", "2020-12-07", "2020-12-08"},
+		},
+		{
+			name:  "隔週の月曜日と水曜日",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: "2020-12-02", from: "2020-12-01", to: "2020-12-31",
+			want: []string{"2020-12-02", "2020-12-14", "2020-12-16", "2020-12-28", "2020-12-30"},
+		},
+		{
+			name:  "BYDAYがなければstartと同じ曜日",
+			rule:  "FREQ=WEEKLY",
+			start: "2020-12-07", from: "2020-12-10", to: "2020-12-31",
+			want: []string{"2020-12-14", "2020-12-21", "2020-12-28"},
+		},
+		{
+			name:  "31日がない月は飛ばす",
+			rule:  "FREQ=MONTHLY",
+			start: "2021-01-31", from: "2021-01-01", to: "2021-05-31",
+			want: []string{"2021-01-31", "2021-03-31", "2021-05-31"},
+		},
+		{
+			name:  "毎月第1月曜日と最終金曜日",
+			rule:  "FREQ=MONTHLY;BYDAY=1MO,-1FR",
+			start: "2020-12-07", from: "2020-12-01", to: "2021-01-31",
+			want: []string{"2020-12-07", "2020-12-25", "2021-01-04", "2021-01-29"},
+		},
+		{
+			name:  "COUNTはstartを含めた回数",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2020-12-01", from: "2020-12-01", to: "2020-12-31",
+			want: []string{"2020-12-01", "2020-12-02", "2020-12-03"},
+		},
+		{
+			name:  "startが古くても期間内の回をすべて返す",
+			rule:  "FREQ=DAILY",
+			start: "2000-01-01", from: "2020-12-01", to: "2020-12-03",
+			want: []string{"2020-12-01", "2020-12-02", "2020-12-03"},
+		},
+		{
+			name:  "startが古い毎週の規則",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE",
+			start: "2000-01-05", from: "2020-12-01", to: "2020-12-07",
+			want: []string{"2020-12-02", "2020-12-07"},
+		},
+		{
+			name:  "startが古い毎月の規則",
+			rule:  "FREQ=MONTHLY",
+			start: "2000-01-31", from: "2020-12-01", to: "2021-03-31",
+			want: []string{"2020-12-31", "2021-01-31", "2021-03-31"},
+		},
+		{
+			name:  "COUNTが多くても最後の回まで返す",
+			rule:  "FREQ=DAILY;COUNT=2000",
+			start: "2000-01-01", from: "2005-06-20", to: "2005-06-30",
+			want: []string{"2005-06-20", "2005-06-21", "2005-06-22"},
+		},
+		{
+			name:  "UNTILの日を含む",
+			rule:  "FREQ=WEEKLY;UNTIL=20201215",
+			start: "2020-12-01", from: "2020-12-01", to: "2020-12-31",
+			want: []string{"2020-12-01", "2020-12-08", "2020-12-15"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, date := range r.Between(date(tt.start), date(tt.from), date(tt.to)) {
+				got = append(got, date.Format(layout))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Between (-want +got) =\n- %v\n+ %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Between (-want +got) =\n- %v\n+ %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrence_Next(t *testing.T) {
+	r, _ := ParseRecurrence("FREQ=WEEKLY;BYDAY=MO;COUNT=2")
+
+	next, ok := r.Next(date("2020-12-02"))
+	if !ok || next.Format(layout) != "2020-12-07" {
+		t.Errorf("Next = %s, %t", next, ok)
+	}
+	// 次の回ではCOUNTが1減り，その次の回はない
+	r = r.After()
+	if r.Count != 1 {
+		t.Errorf("Count = %d", r.Count)
+	}
+	if _, ok = r.Next(next); ok {
+		t.Errorf("Next after last occurrence should not exist")
+	}
+
+	// BYDAYに一致する日がない規則は次の回を探し続けない
+	r, _ = ParseRecurrence("FREQ=DAILY;INTERVAL=7;BYDAY=TU")
+	if _, ok = r.Next(date("2020-12-07")); ok {
+		t.Errorf("Next without matching weekday should not exist")
+	}
+}
+
+func date(s string) time.Time {
+	return NewNullDate(s).Time
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxReminderAttempts は通知に失敗したReminderを再送する回数の上限である
+const MaxReminderAttempts = 5
+
+// Reminder はTaskの期限を通知する時刻である
+// Beforeがnullでない場合は期限のBefore(30m, 1hなど)前に通知し，RemindAtはTaskの期限から計算する
+// SentAtは通知を送った時刻であり，nullの場合はまだ送っていない
+// ClaimedUntilは送信するために確保した期限であり，その間は他のサーバーが同じReminderを送らない
+type Reminder struct {
+	ID           NullString `gorm:"primaryKey" json:"id"`
+	TaskID       NullString `gorm:"not null;index" json:"task_id"`
+	UserID       NullString `gorm:"not null;index"`
+	RemindAt     time.Time  `gorm:"not null;index" json:"remind_at"`
+	Before       NullString `gorm:"column:remind_before" json:"before"`
+	SentAt       *time.Time `json:"sent_at"`
+	Attempts     int        `gorm:"not null" json:"-"`
+	ClaimedUntil *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"-"`
+	UpdatedAt    time.Time  `json:"-"`
+}
+
+// MarshalJSON はjsonにエンコードするときにUserIDフィールドを隠す
+func (r *Reminder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID       NullString `json:"id"`
+		TaskID   NullString `json:"task_id"`
+		RemindAt time.Time  `json:"remind_at"`
+		Before   NullString `json:"before"`
+		SentAt   *time.Time `json:"sent_at"`
+	}{
+		ID:       r.ID,
+		TaskID:   r.TaskID,
+		RemindAt: r.RemindAt,
+		Before:   r.Before,
+		SentAt:   r.SentAt,
+	})
+}
+
+// NewID はReminderのUUIDを生成
+func (r *Reminder) NewID() *Reminder {
+	r.ID = NewNullString(uuid.New().String())
+	return r
+}
+
+// Offset はBeforeを期間として返す．Beforeがnullの場合はfalseを返す
+func (r *Reminder) Offset() (time.Duration, bool) {
+	d, err := time.ParseDuration(r.Before.String())
+	if r.Before.IsNull() || err != nil {
+		return 0, false
+	}
+	return d, true
+}
+
+// Schedule はBeforeが指定されている場合にTaskの期限からRemindAtを計算する
+func (r *Reminder) Schedule(t *Task, loc *time.Location) {
+	if d, ok := r.Offset(); ok {
+		r.RemindAt = t.DueAt(loc).Add(-d).UTC()
+	}
+}
+
+func (r *Reminder) String() (str string) {
+	str = fmt.Sprintf("&entity.Reminder{ID:%s, TaskID:%s, UserID:%s, RemindAt:%s, Before:%s, SentAt:%v, Attempts:%d}",
+		r.ID.String(), r.TaskID.String(), r.UserID.String(), r.RemindAt, r.Before.String(), r.SentAt, r.Attempts)
+	return
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchTerm はTaskを検索するときの1つの語である
+// Phraseがtrueの場合はTextをそのまま1つの句として，Prefixがtrueの場合はTextで始まる語として検索する
+type SearchTerm struct {
+	Text   string
+	Phrase bool
+	Prefix bool
+}
+
+// ParseSearchQuery は検索文字列を空白で区切ってSearchTermにする
+// "..."で囲んだ部分は句として，末尾に*を付けた語は前方一致として扱う
+func ParseSearchQuery(q string) []SearchTerm {
+	terms := []SearchTerm{}
+	rest := strings.TrimSpace(q)
+	for rest != "" {
+		var term SearchTerm
+		if rest[0] == '"' {
+			// 閉じていない"は文字列の終わりまでを句とする
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			term = SearchTerm{Text: strings.Join(strings.Fields(rest[1:end+1]), " "), Phrase: true}
+			rest = rest[min(end+2, len(rest)):]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			word := rest[:end]
+			term = SearchTerm{Text: strings.TrimRight(word, "*"), Prefix: strings.HasSuffix(word, "*")}
+			rest = rest[end:]
+		}
+		if term.Text != "" {
+			terms = append(terms, term)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return terms
+}
+
+// SearchResult はTaskの検索結果である
+// Titleはタイトルを，SnippetはContentの一致した部分の前後を，一致した部分を<mark>で囲んでHTMLエスケープしたものである
+type SearchResult struct {
+	Task    *Task   `json:"task"`
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+}
+
+// Highlight はtermsに一致するtextの部分を<mark>で囲む
+// widthが0より大きい場合は最初に一致した部分を中心にwidth文字に切り詰め，切り詰めた側に…を付ける
+func Highlight(text string, terms []SearchTerm, width int) string {
+	re := termsRegexp(terms)
+	if width > 0 && utf8.RuneCountInString(text) > width {
+		center := 0
+		if re != nil {
+			if loc := re.FindStringIndex(text); loc != nil {
+				center = utf8.RuneCountInString(text[:loc[0]])
+			}
+		}
+		text = truncate(text, center-width/3, width)
+	}
+	if re == nil {
+		return html.EscapeString(text)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[loc[0]:loc[1]]))
+		b.WriteString("</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// termsRegexp はtermsのいずれかに大文字小文字を区別せずに一致する正規表現を返す
+func termsRegexp(terms []SearchTerm) *regexp.Regexp {
+	patterns := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t.Text != "" {
+			patterns = append(patterns, regexp.QuoteMeta(t.Text))
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(patterns, "|"))
+}
+
+// truncate はtextのstart文字目からwidth文字を切り出す
+func truncate(text string, start, width int) string {
+	runes := []rune(text)
+	start = max(0, min(start, len(runes)-width))
+	end := start + width
+	s := string(runes[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
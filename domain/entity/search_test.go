@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want []SearchTerm
+	}{
+		{name: "空白で区切る", q: " buy  milk ", want: []SearchTerm{{Text: "buy"}, {Text: "milk"}}},
+		{name: "\"で囲むと句になる", q: `"buy  milk" today`, want: []SearchTerm{{Text: "buy milk", Phrase: true}, {Text: "today"}}},
+		{name: "閉じていない\"は最後までを句とする", q: `"buy milk`, want: []SearchTerm{{Text: "buy milk", Phrase: true}}},
+		{name: "末尾の*は前方一致になる", q: "meet* 会議", want: []SearchTerm{{Text: "meet", Prefix: true}, {Text: "会議"}}},
+		{name: "空の語は無視する", q: `* "" **`, want: []SearchTerm{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, ParseSearchQuery(tt.q)); diff != "" {
+				t.Errorf("ParseSearchQuery() (-want +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []SearchTerm
+		width int
+		want  string
+	}{
+		{name: "一致した部分を大文字小文字を区別せずに囲む", text: "Buy milk and bread", terms: []SearchTerm{{Text: "buy"}, {Text: "BREAD"}}, want: "<mark>Buy</mark> milk and <mark>bread</mark>"},
+		{name: "HTMLをエスケープする", text: "<b>milk</b> & eggs", terms: []SearchTerm{{Text: "milk"}}, want: "&lt;b&gt;<mark>milk</mark>&lt;/b&gt; &amp; eggs"},
+		{name: "一致した部分の前後に切り詰める", text: "0123456789abcdefghij", terms: []SearchTerm{{Text: "ef"}}, width: 6, want: "…cd<mark>ef</mark>gh…"},
+		{name: "一致しなければ先頭から切り詰める", text: "今日は会議がある", terms: []SearchTerm{{Text: "買い物"}}, width: 3, want: "今日は…"},
+		{name: "短いテキストは切り詰めない", text: "会議", terms: []SearchTerm{{Text: "会議"}}, width: 10, want: "<mark>会議</mark>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.terms, tt.width); got != tt.want {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role はTaskやProjectに対するユーザーの権限である
+type Role string
+
+const (
+	// RoleViewer は閲覧のみできる
+	RoleViewer Role = "viewer"
+	// RoleEditor は閲覧と編集ができる
+	RoleEditor Role = "editor"
+	// RoleOwner は所有者であり，削除や共有もできる
+	RoleOwner Role = "owner"
+)
+
+// roleLevels は権限の強さである．強い権限は弱い権限でできることをすべてできる
+var roleLevels = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Can はrがneedの権限でできることをできるかを返す
+func (r Role) Can(need Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[need]
+}
+
+// IsShareable は共有するときに指定できる権限かを返す
+func (r Role) IsShareable() bool {
+	return r == RoleViewer || r == RoleEditor
+}
+
+// ShareResource は共有するものの種類である
+type ShareResource string
+
+const (
+	ShareTask    ShareResource = "task"
+	ShareProject ShareResource = "project"
+)
+
+// Share はOwnerIDのユーザーのTaskまたはProjectをUserIDのユーザーにRoleの権限で共有する招待である
+// AcceptedAtはUserIDのユーザーが招待を承諾した時刻であり，nullの場合は承諾していないので共有されない
+// EmailとOwnerはそれぞれ共有されたユーザーと所有者のEmailであり，取得時に設定する
+type Share struct {
+	ID           NullString    `gorm:"primaryKey" json:"id"`
+	ResourceType ShareResource `gorm:"not null" json:"resource_type"`
+	ResourceID   NullString    `gorm:"not null" json:"resource_id"`
+	OwnerID      NullString    `gorm:"not null;index" json:"-"`
+	UserID       NullString    `gorm:"not null;index" json:"-"`
+	Role         Role          `gorm:"not null" json:"role"`
+	Email        string        `gorm:"-" json:"email"`
+	Owner        string        `gorm:"-" json:"owner"`
+	AcceptedAt   *time.Time    `json:"accepted_at"`
+	CreatedAt    time.Time     `json:"-"`
+	UpdatedAt    time.Time     `json:"-"`
+}
+
+// NewID はShareのUUIDを生成
+func (s *Share) NewID() *Share {
+	s.ID = NewNullString(uuid.New().String())
+	return s
+}
+
+// IsAccepted は招待が承諾されて共有されているかを返す
+func (s *Share) IsAccepted() bool {
+	return s.AcceptedAt != nil
+}
+
+func (s *Share) String() (str string) {
+	str = fmt.Sprintf("&entity.Share{ID:%s, ResourceType:%s, ResourceID:%s, OwnerID:%s, UserID:%s, Role:%s, AcceptedAt:%v, CreatedAt:%s, UpdatedAt: %s",
+		s.ID.String(), s.ResourceType, s.ResourceID.String(), s.OwnerID.String(), s.UserID.String(), s.Role, s.AcceptedAt, s.CreatedAt, s.UpdatedAt)
+	return
+}
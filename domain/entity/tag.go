@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag はTaskを分類するためのラベルである．ユーザーごとに作成する
+type Tag struct {
+	ID        NullString `gorm:"primaryKey" json:"id"`
+	Name      NullString `gorm:"not null" json:"name"`
+	Color     NullString `json:"color"`
+	UserID    NullString `gorm:"not null;index"`
+	CreatedAt time.Time  `json:"-"`
+	UpdatedAt time.Time  `json:"-"`
+}
+
+// MarshalJSON はjsonにエンコードするときにUserIDフィールドを隠す
+func (t *Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		ID    NullString `json:"id"`
+		Name  NullString `json:"name"`
+		Color NullString `json:"color"`
+	}{
+		ID:    t.ID,
+		Name:  t.Name,
+		Color: t.Color,
+	})
+}
+
+// NewTag is the constructor of Tag.(値が""の場合はsql.NullStringのnullとして扱う)
+func NewTag(id string, name string, color string, userid string) (t *Tag) {
+	t = &Tag{
+		ID:     NewNullString(id),
+		Name:   NewNullString(name),
+		Color:  NewNullString(color),
+		UserID: NewNullString(userid),
+	}
+	return
+}
+
+// NewID はTagのUUIDを生成
+func (t *Tag) NewID() *Tag {
+	t.ID = NewNullString(uuid.New().String())
+	return t
+}
+
+func (t *Tag) String() (str string) {
+	str = fmt.Sprintf("&entity.Tag{ID:%s, Name:%s, Color:%s, UserID:%s, CreatedAt:%s, UpdatedAt: %s",
+		t.ID.String(), t.Name.String(), t.Color.String(), t.UserID.String(), t.CreatedAt, t.UpdatedAt)
+	return
+}
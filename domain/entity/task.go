@@ -8,32 +8,93 @@ import (
 	"github.com/google/uuid"
 )
 
+// MaxTaskDepth はサブタスクを入れ子にできる深さの上限である．親のないTaskの深さを1とする
+const MaxTaskDepth = 3
+
 // Task は内部で処理する際のTask情報である
+// Positionは同じ日のTask(サブタスクの場合は同じ親のTask)の中での並び順(Rank)である
+// ProjectIDがnullのTaskはインボックスにある
+// WorkspaceIDがnullでないTaskはWorkspaceのメンバーにも見える
+// ParentIDがnullでないTaskはサブタスクである
+// AssigneeIDはTaskの担当者のユーザーのIDであり，Assigneeは取得時にAssigneeIDから読み込む
+// RRuleがnullでないTaskは繰り返しのTaskであり，Deadlineがその最初の回である
+// DueTimeは期限の時刻(HH:MM)であり，nullの場合はDeadlineの日の終わりを期限とする
+// OverdueはTaskの期限がユーザーのタイムゾーンで過ぎているかであり，取得時に計算する
+// VirtualがtrueのTaskは繰り返しのTaskを一覧で展開した回であり，databaseには存在しない
+// Roleは他のユーザーから共有されたTaskやWorkspaceのTaskに対する権限であり，自分のTaskの場合は空である
+// Versionは作成時に1から始まり，Taskを変更するたびに1ずつ増える
 type Task struct {
-	ID          NullString `gorm:"primary_key" json:"id"`
+	ID          NullString `gorm:"primaryKey" json:"id"`
 	Title       NullString `gorm:"not null" json:"title"`
 	Content     NullString `json:"content"`
 	UserID      NullString `gorm:"not null;index"`
+	ProjectID   NullString `gorm:"index" json:"project_id"`
+	WorkspaceID NullString `gorm:"index" json:"workspace_id"`
+	ParentID    NullString `gorm:"index" json:"parent_id"`
+	AssigneeID  NullString `gorm:"index" json:"-"`
+	Assignee    *User      `json:"-"`
 	IsCompleted bool       `gorm:"not null" json:"iscomp"`
 	Deadline    NullDate   `gorm:"not null" json:"deadline"`
+	DueTime     NullString `json:"due_time"`
+	Overdue     bool       `gorm:"-" json:"overdue"`
+	Priority    Priority   `gorm:"not null" json:"priority"`
+	Position    string     `gorm:"not null" json:"position"`
+	RRule       NullString `gorm:"column:recurrence" json:"recurrence"`
+	Virtual     bool       `gorm:"-" json:"virtual"`
+	Role        Role       `gorm:"-" json:"role,omitempty"`
+	Tags        []*Tag     `gorm:"many2many:task_tags" json:"tags"`
+	Version     int        `gorm:"not null" json:"-"`
 	CreatedAt   time.Time  `json:"-"`
 	UpdatedAt   time.Time  `json:"-"`
 }
 
 // MarshalJSON はjsonにエンコードするときにUserIDフィールドを隠す
+// ユーザーのIDは外部に公開しないので，担当者はEmailで表す
 func (t *Task) MarshalJSON() ([]byte, error) {
+	tags := t.Tags
+	if tags == nil {
+		tags = []*Tag{}
+	}
+	assignee := NullString{}
+	if t.Assignee != nil {
+		assignee = t.Assignee.Email
+	}
 	return json.Marshal(&struct {
 		ID          NullString `json:"id"`
 		Title       NullString `json:"title"`
 		Content     NullString `json:"content"`
+		ProjectID   NullString `json:"project_id"`
+		WorkspaceID NullString `json:"workspace_id"`
+		ParentID    NullString `json:"parent_id"`
+		Assignee    NullString `json:"assignee"`
 		IsCompleted bool       `json:"iscomp"`
 		Deadline    NullDate   `json:"deadline"`
+		DueTime     NullString `json:"due_time"`
+		Overdue     bool       `json:"overdue"`
+		Priority    Priority   `json:"priority"`
+		Position    string     `json:"position"`
+		RRule       NullString `json:"recurrence"`
+		Virtual     bool       `json:"virtual"`
+		Role        Role       `json:"role,omitempty"`
+		Tags        []*Tag     `json:"tags"`
 	}{
 		ID:          t.ID,
 		Title:       t.Title,
 		Content:     t.Content,
+		ProjectID:   t.ProjectID,
+		WorkspaceID: t.WorkspaceID,
+		ParentID:    t.ParentID,
+		Assignee:    assignee,
 		IsCompleted: t.IsCompleted,
 		Deadline:    t.Deadline,
+		DueTime:     t.DueTime,
+		Overdue:     t.Overdue,
+		Priority:    t.Priority,
+		Position:    t.Position,
+		RRule:       t.RRule,
+		Virtual:     t.Virtual,
+		Role:        t.Role,
+		Tags:        tags,
 	})
 }
 
@@ -61,18 +122,79 @@ func (t *Task) NewID() *Task {
 	return t
 }
 
+// ETag はTaskのバージョンと期限を過ぎたかを表すETagを返す
+// 期限を過ぎるとバージョンが同じでもoverdueが変わるので，ETagも変える
+func (t *Task) ETag() string {
+	if t.Overdue {
+		return DerivedETag(t.Version, "overdue")
+	}
+	return ETag(t.Version)
+}
+
 // SetID はTaskのIDを設定
 func (t *Task) SetID(id string) *Task {
 	t.ID = NewNullString(id)
 	return t
 }
 
+// Recurrence はTaskの繰り返しの規則を返す．繰り返さないTaskの場合はnilを返す
+func (t *Task) Recurrence() (*Recurrence, error) {
+	if t.RRule.IsNull() {
+		return nil, nil
+	}
+	return ParseRecurrence(t.RRule.String())
+}
+
+// DueAt はTaskの期限をlocの時刻で返す．DueTimeがnullの場合はDeadlineの翌日の0時とする
+func (t *Task) DueAt(loc *time.Location) time.Time {
+	y, m, d := t.Deadline.Time.Date()
+	clock, err := time.Parse(timeOfDayLayout, t.DueTime.String())
+	if t.DueTime.IsNull() || err != nil {
+		return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+}
+
+// IsOverdue は未完了のTaskの期限がnowの時点で過ぎているかをlocのタイムゾーンで判定する
+func (t *Task) IsOverdue(now time.Time, loc *time.Location) bool {
+	return !t.IsCompleted && !t.Deadline.IsNull() && !now.Before(t.DueAt(loc))
+}
+
+// timeOfDayLayout はDueTimeの形式である
+const timeOfDayLayout = "15:04"
+
+// ValidTimeOfDay はsが時刻(HH:MM)として正しいかを返す
+func ValidTimeOfDay(s string) bool {
+	_, err := time.Parse(timeOfDayLayout, s)
+	return err == nil && len(s) == len(timeOfDayLayout)
+}
+
 // SetComp はTaskのIsCompletedを設定する
 func (t *Task) SetComp(comp bool) *Task {
 	t.IsCompleted = comp
 	return t
 }
 
+// TaskView は期間で絞り込んだTaskの一覧である
+// Countはバッジに表示するための未完了のTaskの件数であり，Fromがnilの場合はToまでのすべての日付を表す
+type TaskView struct {
+	From  *NullDate `json:"from"`
+	To    *NullDate `json:"to"`
+	Count int       `json:"count"`
+	Tasks []*Task   `json:"tasks"`
+}
+
+// NewTaskView はtasksとその未完了の件数からTaskViewを作る
+func NewTaskView(from, to *NullDate, tasks []*Task) *TaskView {
+	count := 0
+	for _, t := range tasks {
+		if !t.IsCompleted {
+			count++
+		}
+	}
+	return &TaskView{From: from, To: to, Count: count, Tasks: tasks}
+}
+
 // // TaskForJSON はJSONにして外部に公開するTask情報である
 // type TaskForJSON struct {
 // 	ID          string   `json:"id"`
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTask_IsOverdue(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2020-01-01 12:00 UTC は東京では2020-01-01 21:00，ニューヨークでは2020-01-01 07:00
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		deadline string
+		dueTime  string
+		comp     bool
+		loc      *time.Location
+		want     bool
+	}{
+		{name: "期限の日の途中ならoverdueでない", deadline: "2020-01-01", loc: time.UTC, want: false},
+		{name: "期限の日を過ぎたらoverdue", deadline: "2019-12-31", loc: time.UTC, want: true},
+		{name: "ユーザーのタイムゾーンで期限の日を過ぎていなければoverdueでない", deadline: "2019-12-31", loc: time.FixedZone("UTC-13", -13*60*60), want: false},
+		{name: "期限の時刻を過ぎたらoverdue", deadline: "2020-01-01", dueTime: "20:00", loc: tokyo, want: true},
+		{name: "期限の時刻の前ならoverdueでない", deadline: "2020-01-01", dueTime: "08:00", loc: newYork, want: false},
+		{name: "完了したタスクはoverdueでない", deadline: "2019-12-31", comp: true, loc: time.UTC, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := NewTask("id", "title", "", "uid", tt.deadline).SetComp(tt.comp)
+			task.DueTime = NewNullString(tt.dueTime)
+			if got := task.IsOverdue(now, tt.loc); got != tt.want {
+				t.Errorf("IsOverdue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidTimeOfDay(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "00:00", want: true},
+		{s: "23:59", want: true},
+		{s: "9:30", want: false},
+		{s: "24:00", want: false},
+		{s: "12:60", want: false},
+		{s: "12:00:00", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := ValidTimeOfDay(tt.s); got != tt.want {
+				t.Errorf("ValidTimeOfDay(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -14,11 +15,30 @@ type Token struct {
 	is_encrypted bool
 }
 
+// redacted はログなどに出力する際にトークンの代わりに表示する文字列
+const redacted = "[REDACTED]"
+
 func NewToken(s string) Token {
 	return Token{value: NewNullString(s), is_encrypted: false}
 }
 
+// String はfmtなどで出力されたときに値が漏れないように伏せ字を返す
 func (t Token) String() string {
+	return redacted
+}
+
+// GoString は%#vで出力されたときに値が漏れないように伏せ字を返す
+func (t Token) GoString() string {
+	return redacted
+}
+
+// LogValue はslogで出力されたときに値が漏れないように伏せ字を返す
+func (t Token) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// raw はトークンの値そのものを返す
+func (t Token) raw() string {
 	return t.value.String()
 }
 
@@ -43,18 +63,18 @@ func (t Token) IsNull() bool {
 }
 
 func (t Token) Equal(s Token) bool {
-	return t.String() == s.String() && t.is_encrypted == s.is_encrypted
+	return t.raw() == s.raw() && t.is_encrypted == s.is_encrypted
 }
 
 // Encrypt はトークンをハッシュ化する
 func (t *Token) Encrypt() error {
 	if t.is_encrypted {
-		return fmt.Errorf("Already encrypted:%s", t)
+		return fmt.Errorf("Already encrypted")
 	}
-	if t.String() == "" {
+	if t.raw() == "" {
 		return nil
 	}
-	token := []byte(t.String())
+	token := []byte(t.raw())
 	digest, err := bcrypt.GenerateFromPassword(token, bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -79,8 +99,8 @@ func (t *Token) authenticate(plain *Token) error {
 	if !t.is_encrypted || plain.is_encrypted {
 		return fmt.Errorf("Invalid tokens")
 	}
-	p1 := []byte(t.String())
-	p2 := []byte(plain.String())
+	p1 := []byte(t.raw())
+	p2 := []byte(plain.raw())
 
 	return bcrypt.CompareHashAndPassword(p1, p2)
 }
@@ -92,11 +112,16 @@ func (t *Token) authenticate(plain *Token) error {
 
 // Scan はデータベースの値をTokenにマッピングする
 func (t *Token) Scan(value interface{}) error {
-	str, ok := value.([]byte)
-	if !ok {
-		return fmt.Errorf("Invalid value:%s", value)
+	var str string
+	switch v := value.(type) {
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("Invalid value type:%T", value)
 	}
-	t.Set(string(str))
+	t.Set(str)
 	t.is_encrypted = true
 	return nil
 }
@@ -8,19 +8,24 @@ package entity
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jinzhu/gorm"
+	"gorm.io/gorm"
 )
 
 // User は内部で処理する際のUser情報である
+// TimeZoneはIANAのタイムゾーン名(Asia/Tokyoなど)であり，nullの場合はUTCとして扱う
+// Versionは作成時に1から始まり，Userを変更するたびに1ずつ増える
 type User struct {
-	// ID        int        `gorm:"primary_key"`
-	ID        NullString `gorm:"primary_key" json:"id"`
+	// ID        int        `gorm:"primaryKey"`
+	ID        NullString `gorm:"primaryKey" json:"id"`
 	Name      NullString `gorm:"not null" json:"name"`
 	Password  Token      `gorm:"not null" json:"password"`
 	Email     NullString `gorm:"not null;unique" json:"email"`
+	TimeZone  NullString `json:"time_zone"`
+	Version   int        `gorm:"not null" json:"-"`
 	CreatedAt time.Time  `json:"-"`
 	UpdatedAt time.Time  `json:"-"`
 }
@@ -28,13 +33,15 @@ type User struct {
 // MarshalJSON はjsonにエンコードするときにパスワードフィールドを隠す
 func (u *User) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		ID    NullString `json:"id"`
-		Name  NullString `json:"name"`
-		Email NullString `json:"email"`
+		ID       NullString `json:"id"`
+		Name     NullString `json:"name"`
+		Email    NullString `json:"email"`
+		TimeZone NullString `json:"time_zone"`
 	}{
-		ID:    u.ID,
-		Name:  u.Name,
-		Email: u.Email,
+		ID:       u.ID,
+		Name:     u.Name,
+		Email:    u.Email,
+		TimeZone: u.TimeZone,
 	})
 }
 
@@ -60,12 +67,25 @@ func (u *User) NewID() *User {
 	return u
 }
 
+// ETag はUserのバージョンを表すETagを返す
+func (u *User) ETag() string {
+	return ETag(u.Version)
+}
+
 // SetID はUserのIDを設定
 func (u *User) SetID(id string) *User {
 	u.ID = NewNullString(id)
 	return u
 }
 
+// Location はUserのタイムゾーンを返す
+func (u *User) Location() (*time.Location, error) {
+	if u.TimeZone.IsNull() {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(u.TimeZone.String())
+}
+
 func (u *User) EncryptPassword() error {
 	return u.Password.Encrypt()
 }
@@ -87,12 +107,22 @@ func (u *User) EncryptPassword() error {
 // 	return
 // }
 
+// String はパスワードを伏せ字にした文字列を返す
 func (u *User) String() (str string) {
 	str = fmt.Sprintf("&entity.User{ID:%s, Name:%s, Password:%s, Email:%s, CreatedAt:%s, UpdatedAt: %s",
 		u.ID.String(), u.Name.String(), u.Password.String(), u.Email.String(), u.CreatedAt, u.UpdatedAt)
 	return
 }
 
+// LogValue はslogで出力する際にパスワードを含めない
+func (u *User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", u.ID.String()),
+		slog.String("name", u.Name.String()),
+		slog.String("email", u.Email.String()),
+	)
+}
+
 // BeforeSave はデータベースに保存する際のフック
 func (u *User) BeforeSave(tx *gorm.DB) (err error) {
 	u.EncryptPassword()
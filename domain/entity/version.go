@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrVersionConflict は更新や削除しようとしたエンティティのバージョンが指定したものと異なることを示す
+var ErrVersionConflict = errors.New("version conflict")
+
+// ETag はエンティティのバージョンを表すETagを返す
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// DerivedETag はバージョンと取得時に計算する状態(期限を過ぎたかなど)を表すETagを返す．stateが空の場合はETagと同じである
+// 状態が変わればバージョンが同じでもETagが変わるので，If-None-Matchで古い状態のレスポンスを使わせない
+func DerivedETag(version int, state string) string {
+	if state == "" {
+		return ETag(version)
+	}
+	return strconv.Quote(strconv.Itoa(version) + "-" + state)
+}
+
+// versionTag はDerivedETagから状態を除いたバージョンのETagを返す
+func versionTag(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i > 0 && strings.HasSuffix(tag, `"`) {
+		return tag[:i] + `"`
+	}
+	return tag
+}
+
+// Precondition はIf-MatchやIf-None-Matchで指定されたETagの一覧である
+// *はどのバージョンにも一致する
+type Precondition []string
+
+// NewPrecondition はカンマ区切りのETagの一覧からPreconditionを生成する．headerが空の場合は空とする
+func NewPrecondition(header string) Precondition {
+	p := Precondition{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			p = append(p, tag)
+		}
+	}
+	return p
+}
+
+// IsEmpty はETagが指定されていないかを返す
+func (p Precondition) IsEmpty() bool {
+	return len(p) == 0
+}
+
+// IsAny は*が指定されているかを返す．*は現在のバージョンに関わらず一致する
+func (p Precondition) IsAny() bool {
+	for _, tag := range p {
+		if tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Match はversionのETagが一覧のいずれかに一致するかを返す．弱いETag(W/)は一致しないものとする
+// 取得時に計算する状態は変更の競合とは関係ないので，DerivedETagは状態を除いてバージョンのみを比べる
+func (p Precondition) Match(version int) bool {
+	etag := ETag(version)
+	for _, tag := range p {
+		if tag == "*" || versionTag(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchWeak はW/を除いてetagと比べる．If-None-Matchでは弱いETagも一致するものとする
+// etagが取得時に計算する状態を含む場合は状態も一致しなければならない
+func (p Precondition) MatchWeak(etag string) bool {
+	for _, tag := range p {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
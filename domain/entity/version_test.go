@@ -0,0 +1,51 @@
+package entity
+
+import "testing"
+
+func TestPrecondition_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		version int
+		want    bool
+	}{
+		{name: "同じバージョンのETagに一致する", header: `"3"`, version: 3, want: true},
+		{name: "異なるバージョンのETagには一致しない", header: `"2"`, version: 3, want: false},
+		{name: "一覧のいずれかに一致すればよい", header: `"1", "3"`, version: 3, want: true},
+		{name: "*はどのバージョンにも一致する", header: `*`, version: 3, want: true},
+		{name: "弱いETagは一致しない", header: `W/"3"`, version: 3, want: false},
+		{name: "引用符のないETagは一致しない", header: `3`, version: 3, want: false},
+		{name: "空の場合は一致しない", header: ``, version: 3, want: false},
+		{name: "取得時に計算する状態を含むETagはバージョンのみを比べる", header: `"3-overdue"`, version: 3, want: true},
+		{name: "取得時に計算する状態を含むETagでもバージョンが異なれば一致しない", header: `"2-overdue"`, version: 3, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPrecondition(tt.header).Match(tt.version); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrecondition_MatchWeak(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		etag   string
+		want   bool
+	}{
+		{name: "弱いETagも一致する", header: `W/"3"`, etag: `"3"`, want: true},
+		{name: "強いETagも一致する", header: `"3"`, etag: `"3"`, want: true},
+		{name: "異なるバージョンのETagには一致しない", header: `W/"2"`, etag: `"3"`, want: false},
+		{name: "取得時に計算する状態が異なれば一致しない", header: `"3"`, etag: DerivedETag(3, "overdue"), want: false},
+		{name: "取得時に計算する状態も同じなら一致する", header: `"3-overdue"`, etag: DerivedETag(3, "overdue"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPrecondition(tt.header).MatchWeak(tt.etag); got != tt.want {
+				t.Errorf("MatchWeak() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
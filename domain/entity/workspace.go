@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceRole はWorkspaceでのメンバーの役割である
+type WorkspaceRole string
+
+const (
+	// WorkspaceOwner はWorkspaceの作成者であり，Workspaceの削除とadminの任命もできる
+	WorkspaceOwner WorkspaceRole = "owner"
+	// WorkspaceAdmin はWorkspaceの変更とメンバーの管理ができ，WorkspaceのすべてのTaskを削除できる
+	WorkspaceAdmin WorkspaceRole = "admin"
+	// WorkspaceMember はWorkspaceのTaskを作成して編集できる
+	WorkspaceMember WorkspaceRole = "member"
+	// WorkspaceGuest はWorkspaceのTaskを閲覧のみできる
+	WorkspaceGuest WorkspaceRole = "guest"
+)
+
+// workspaceRoleLevels は役割の強さである．強い役割は弱い役割でできることをすべてできる
+var workspaceRoleLevels = map[WorkspaceRole]int{
+	WorkspaceGuest:  1,
+	WorkspaceMember: 2,
+	WorkspaceAdmin:  3,
+	WorkspaceOwner:  4,
+}
+
+// IsValid は定義された役割かを返す
+func (r WorkspaceRole) IsValid() bool {
+	_, ok := workspaceRoleLevels[r]
+	return ok
+}
+
+// Can はrがneedの役割でできることをできるかを返す
+func (r WorkspaceRole) Can(need WorkspaceRole) bool {
+	level, ok := workspaceRoleLevels[r]
+	return ok && level >= workspaceRoleLevels[need]
+}
+
+// TaskRole はWorkspaceのTaskに対する権限を返す．未定義の役割の場合は""を返す
+func (r WorkspaceRole) TaskRole() Role {
+	switch r {
+	case WorkspaceOwner, WorkspaceAdmin:
+		return RoleOwner
+	case WorkspaceMember:
+		return RoleEditor
+	case WorkspaceGuest:
+		return RoleViewer
+	}
+	return ""
+}
+
+// Workspace はメンバーでTaskを共有する場所である
+// Roleは取得したユーザーのWorkspaceでの役割であり，取得時に設定する
+type Workspace struct {
+	ID        NullString    `gorm:"primaryKey" json:"id"`
+	Name      NullString    `gorm:"not null" json:"name"`
+	Role      WorkspaceRole `gorm:"-" json:"role"`
+	CreatedAt time.Time     `json:"-"`
+	UpdatedAt time.Time     `json:"-"`
+}
+
+// NewWorkspace is the constructor of Workspace.(値が""の場合はsql.NullStringのnullとして扱う)
+func NewWorkspace(id string, name string) *Workspace {
+	return &Workspace{
+		ID:   NewNullString(id),
+		Name: NewNullString(name),
+	}
+}
+
+// NewID はWorkspaceのUUIDを生成
+func (w *Workspace) NewID() *Workspace {
+	w.ID = NewNullString(uuid.New().String())
+	return w
+}
+
+func (w *Workspace) String() (str string) {
+	str = fmt.Sprintf("&entity.Workspace{ID:%s, Name:%s, Role:%s, CreatedAt:%s, UpdatedAt: %s",
+		w.ID.String(), w.Name.String(), w.Role, w.CreatedAt, w.UpdatedAt)
+	return
+}
+
+// Membership はUserIDのユーザーがWorkspaceIDのWorkspaceにRoleの役割で参加していることを表す
+// Emailはメンバーのemailであり，取得時に設定する
+type Membership struct {
+	ID          NullString    `gorm:"primaryKey" json:"id"`
+	WorkspaceID NullString    `gorm:"not null" json:"workspace_id"`
+	UserID      NullString    `gorm:"not null;index" json:"-"`
+	Role        WorkspaceRole `gorm:"not null" json:"role"`
+	Email       string        `gorm:"-" json:"email"`
+	CreatedAt   time.Time     `json:"-"`
+	UpdatedAt   time.Time     `json:"-"`
+}
+
+// NewID はMembershipのUUIDを生成
+func (m *Membership) NewID() *Membership {
+	m.ID = NewNullString(uuid.New().String())
+	return m
+}
+
+func (m *Membership) String() (str string) {
+	str = fmt.Sprintf("&entity.Membership{ID:%s, WorkspaceID:%s, UserID:%s, Role:%s, CreatedAt:%s, UpdatedAt: %s",
+		m.ID.String(), m.WorkspaceID.String(), m.UserID.String(), m.Role, m.CreatedAt, m.UpdatedAt)
+	return
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: activity.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockActivityRepository is a mock of ActivityRepository interface.
+type MockActivityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockActivityRepositoryMockRecorder
+}
+
+// MockActivityRepositoryMockRecorder is the mock recorder for MockActivityRepository.
+type MockActivityRepositoryMockRecorder struct {
+	mock *MockActivityRepository
+}
+
+// NewMockActivityRepository creates a new mock instance.
+func NewMockActivityRepository(ctrl *gomock.Controller) *MockActivityRepository {
+	mock := &MockActivityRepository{ctrl: ctrl}
+	mock.recorder = &MockActivityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActivityRepository) EXPECT() *MockActivityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockActivityRepository) Create(ctx context.Context, a *entity.Activity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockActivityRepositoryMockRecorder) Create(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockActivityRepository)(nil).Create), ctx, a)
+}
+
+// FindByActor mocks base method.
+func (m *MockActivityRepository) FindByActor(ctx context.Context, uid string, page entity.Page) ([]*entity.Activity, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByActor", ctx, uid, page)
+	ret0, _ := ret[0].([]*entity.Activity)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByActor indicates an expected call of FindByActor.
+func (mr *MockActivityRepositoryMockRecorder) FindByActor(ctx, uid, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByActor", reflect.TypeOf((*MockActivityRepository)(nil).FindByActor), ctx, uid, page)
+}
+
+// FindByEntity mocks base method.
+func (m *MockActivityRepository) FindByEntity(ctx context.Context, entityType entity.ActivityEntity, id string, page entity.Page) ([]*entity.Activity, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEntity", ctx, entityType, id, page)
+	ret0, _ := ret[0].([]*entity.Activity)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindByEntity indicates an expected call of FindByEntity.
+func (mr *MockActivityRepositoryMockRecorder) FindByEntity(ctx, entityType, id, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEntity", reflect.TypeOf((*MockActivityRepository)(nil).FindByEntity), ctx, entityType, id, page)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: assignment.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockAssignmentRepository is a mock of AssignmentRepository interface.
+type MockAssignmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssignmentRepositoryMockRecorder
+}
+
+// MockAssignmentRepositoryMockRecorder is the mock recorder for MockAssignmentRepository.
+type MockAssignmentRepositoryMockRecorder struct {
+	mock *MockAssignmentRepository
+}
+
+// NewMockAssignmentRepository creates a new mock instance.
+func NewMockAssignmentRepository(ctrl *gomock.Controller) *MockAssignmentRepository {
+	mock := &MockAssignmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAssignmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssignmentRepository) EXPECT() *MockAssignmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAssignmentRepository) Create(ctx context.Context, a *entity.Assignment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, a)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAssignmentRepositoryMockRecorder) Create(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAssignmentRepository)(nil).Create), ctx, a)
+}
+
+// Dispatch mocks base method.
+func (m *MockAssignmentRepository) Dispatch(ctx context.Context, now time.Time, limit int, send func(context.Context, *entity.Assignment) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dispatch", ctx, now, limit, send)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockAssignmentRepositoryMockRecorder) Dispatch(ctx, now, limit, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockAssignmentRepository)(nil).Dispatch), ctx, now, limit, send)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: attachment.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockAttachmentRepository is a mock of AttachmentRepository interface.
+type MockAttachmentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentRepositoryMockRecorder
+}
+
+// MockAttachmentRepositoryMockRecorder is the mock recorder for MockAttachmentRepository.
+type MockAttachmentRepositoryMockRecorder struct {
+	mock *MockAttachmentRepository
+}
+
+// NewMockAttachmentRepository creates a new mock instance.
+func NewMockAttachmentRepository(ctrl *gomock.Controller) *MockAttachmentRepository {
+	mock := &MockAttachmentRepository{ctrl: ctrl}
+	mock.recorder = &MockAttachmentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentRepository) EXPECT() *MockAttachmentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAttachmentRepository) Create(ctx context.Context, a *entity.Attachment, quota int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, a, quota)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAttachmentRepositoryMockRecorder) Create(ctx, a, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAttachmentRepository)(nil).Create), ctx, a, quota)
+}
+
+// Delete mocks base method.
+func (m *MockAttachmentRepository) Delete(ctx context.Context, tid, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, tid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAttachmentRepositoryMockRecorder) Delete(ctx, tid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachmentRepository)(nil).Delete), ctx, tid, id)
+}
+
+// FindByID mocks base method.
+func (m *MockAttachmentRepository) FindByID(ctx context.Context, tid, id string) (*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, tid, id)
+	ret0, _ := ret[0].(*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockAttachmentRepositoryMockRecorder) FindByID(ctx, tid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockAttachmentRepository)(nil).FindByID), ctx, tid, id)
+}
+
+// FindByTask mocks base method.
+func (m *MockAttachmentRepository) FindByTask(ctx context.Context, tid string) ([]*entity.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTask", ctx, tid)
+	ret0, _ := ret[0].([]*entity.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTask indicates an expected call of FindByTask.
+func (mr *MockAttachmentRepositoryMockRecorder) FindByTask(ctx, tid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTask", reflect.TypeOf((*MockAttachmentRepository)(nil).FindByTask), ctx, tid)
+}
+
+// Purge mocks base method.
+func (m *MockAttachmentRepository) Purge(ctx context.Context, limit int, remove func(context.Context, string) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, limit, remove)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockAttachmentRepositoryMockRecorder) Purge(ctx, limit, remove interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockAttachmentRepository)(nil).Purge), ctx, limit, remove)
+}
+
+// Usage mocks base method.
+func (m *MockAttachmentRepository) Usage(ctx context.Context, uid string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockAttachmentRepositoryMockRecorder) Usage(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockAttachmentRepository)(nil).Usage), ctx, uid)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: membership.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockMembershipRepository is a mock of MembershipRepository interface.
+type MockMembershipRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipRepositoryMockRecorder
+}
+
+// MockMembershipRepositoryMockRecorder is the mock recorder for MockMembershipRepository.
+type MockMembershipRepositoryMockRecorder struct {
+	mock *MockMembershipRepository
+}
+
+// NewMockMembershipRepository creates a new mock instance.
+func NewMockMembershipRepository(ctrl *gomock.Controller) *MockMembershipRepository {
+	mock := &MockMembershipRepository{ctrl: ctrl}
+	mock.recorder = &MockMembershipRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipRepository) EXPECT() *MockMembershipRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m_2 *MockMembershipRepository) Create(ctx context.Context, m *entity.Membership) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Create", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMembershipRepositoryMockRecorder) Create(ctx, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMembershipRepository)(nil).Create), ctx, m)
+}
+
+// Delete mocks base method.
+func (m *MockMembershipRepository) Delete(ctx context.Context, wid, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, wid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMembershipRepositoryMockRecorder) Delete(ctx, wid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMembershipRepository)(nil).Delete), ctx, wid, id)
+}
+
+// Find mocks base method.
+func (m *MockMembershipRepository) Find(ctx context.Context, wid, uid string) (*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, wid, uid)
+	ret0, _ := ret[0].(*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockMembershipRepositoryMockRecorder) Find(ctx, wid, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMembershipRepository)(nil).Find), ctx, wid, uid)
+}
+
+// FindByID mocks base method.
+func (m *MockMembershipRepository) FindByID(ctx context.Context, wid, id string) (*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, wid, id)
+	ret0, _ := ret[0].(*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockMembershipRepositoryMockRecorder) FindByID(ctx, wid, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockMembershipRepository)(nil).FindByID), ctx, wid, id)
+}
+
+// FindByUser mocks base method.
+func (m *MockMembershipRepository) FindByUser(ctx context.Context, uid string) ([]*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUser", ctx, uid)
+	ret0, _ := ret[0].([]*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUser indicates an expected call of FindByUser.
+func (mr *MockMembershipRepositoryMockRecorder) FindByUser(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUser", reflect.TypeOf((*MockMembershipRepository)(nil).FindByUser), ctx, uid)
+}
+
+// FindByWorkspace mocks base method.
+func (m *MockMembershipRepository) FindByWorkspace(ctx context.Context, wid string) ([]*entity.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByWorkspace", ctx, wid)
+	ret0, _ := ret[0].([]*entity.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByWorkspace indicates an expected call of FindByWorkspace.
+func (mr *MockMembershipRepositoryMockRecorder) FindByWorkspace(ctx, wid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByWorkspace", reflect.TypeOf((*MockMembershipRepository)(nil).FindByWorkspace), ctx, wid)
+}
+
+// Update mocks base method.
+func (m_2 *MockMembershipRepository) Update(ctx context.Context, m *entity.Membership) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Update", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMembershipRepositoryMockRecorder) Update(ctx, m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMembershipRepository)(nil).Update), ctx, m)
+}
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reminder.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockReminderRepository is a mock of ReminderRepository interface.
+type MockReminderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderRepositoryMockRecorder
+}
+
+// MockReminderRepositoryMockRecorder is the mock recorder for MockReminderRepository.
+type MockReminderRepositoryMockRecorder struct {
+	mock *MockReminderRepository
+}
+
+// NewMockReminderRepository creates a new mock instance.
+func NewMockReminderRepository(ctrl *gomock.Controller) *MockReminderRepository {
+	mock := &MockReminderRepository{ctrl: ctrl}
+	mock.recorder = &MockReminderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderRepository) EXPECT() *MockReminderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReminderRepository) Create(ctx context.Context, r *entity.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReminderRepositoryMockRecorder) Create(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReminderRepository)(nil).Create), ctx, r)
+}
+
+// Delete mocks base method.
+func (m *MockReminderRepository) Delete(ctx context.Context, id, tid, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, tid, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockReminderRepositoryMockRecorder) Delete(ctx, id, tid, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReminderRepository)(nil).Delete), ctx, id, tid, uid)
+}
+
+// Dispatch mocks base method.
+func (m *MockReminderRepository) Dispatch(ctx context.Context, now time.Time, limit int, send func(context.Context, *entity.Reminder) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dispatch", ctx, now, limit, send)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockReminderRepositoryMockRecorder) Dispatch(ctx, now, limit, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockReminderRepository)(nil).Dispatch), ctx, now, limit, send)
+}
+
+// FindByTask mocks base method.
+func (m *MockReminderRepository) FindByTask(ctx context.Context, tid, uid string) ([]*entity.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByTask", ctx, tid, uid)
+	ret0, _ := ret[0].([]*entity.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByTask indicates an expected call of FindByTask.
+func (mr *MockReminderRepositoryMockRecorder) FindByTask(ctx, tid, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByTask", reflect.TypeOf((*MockReminderRepository)(nil).FindByTask), ctx, tid, uid)
+}
+
+// Reschedule mocks base method.
+func (m *MockReminderRepository) Reschedule(ctx context.Context, uid string, reminders []*entity.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, uid, reminders)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockReminderRepositoryMockRecorder) Reschedule(ctx, uid, reminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockReminderRepository)(nil).Reschedule), ctx, uid, reminders)
+}
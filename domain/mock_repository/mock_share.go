@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: share.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockShareRepository is a mock of ShareRepository interface.
+type MockShareRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareRepositoryMockRecorder
+}
+
+// MockShareRepositoryMockRecorder is the mock recorder for MockShareRepository.
+type MockShareRepositoryMockRecorder struct {
+	mock *MockShareRepository
+}
+
+// NewMockShareRepository creates a new mock instance.
+func NewMockShareRepository(ctrl *gomock.Controller) *MockShareRepository {
+	mock := &MockShareRepository{ctrl: ctrl}
+	mock.recorder = &MockShareRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareRepository) EXPECT() *MockShareRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockShareRepository) Accept(ctx context.Context, id, uid string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", ctx, id, uid, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockShareRepositoryMockRecorder) Accept(ctx, id, uid, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockShareRepository)(nil).Accept), ctx, id, uid, at)
+}
+
+// Create mocks base method.
+func (m *MockShareRepository) Create(ctx context.Context, s *entity.Share) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockShareRepositoryMockRecorder) Create(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShareRepository)(nil).Create), ctx, s)
+}
+
+// Delete mocks base method.
+func (m *MockShareRepository) Delete(ctx context.Context, id, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockShareRepositoryMockRecorder) Delete(ctx, id, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockShareRepository)(nil).Delete), ctx, id, uid)
+}
+
+// FindByID mocks base method.
+func (m *MockShareRepository) FindByID(ctx context.Context, id string) (*entity.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*entity.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockShareRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockShareRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByUser mocks base method.
+func (m *MockShareRepository) FindByUser(ctx context.Context, uid string) ([]*entity.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUser", ctx, uid)
+	ret0, _ := ret[0].([]*entity.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUser indicates an expected call of FindByUser.
+func (mr *MockShareRepositoryMockRecorder) FindByUser(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUser", reflect.TypeOf((*MockShareRepository)(nil).FindByUser), ctx, uid)
+}
@@ -0,0 +1,136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tag.go
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MockTagRepository is a mock of TagRepository interface.
+type MockTagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagRepositoryMockRecorder
+}
+
+// MockTagRepositoryMockRecorder is the mock recorder for MockTagRepository.
+type MockTagRepositoryMockRecorder struct {
+	mock *MockTagRepository
+}
+
+// NewMockTagRepository creates a new mock instance.
+func NewMockTagRepository(ctrl *gomock.Controller) *MockTagRepository {
+	mock := &MockTagRepository{ctrl: ctrl}
+	mock.recorder = &MockTagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagRepository) EXPECT() *MockTagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Attach mocks base method.
+func (m *MockTagRepository) Attach(ctx context.Context, tid, tagID, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attach", ctx, tid, tagID, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Attach indicates an expected call of Attach.
+func (mr *MockTagRepositoryMockRecorder) Attach(ctx, tid, tagID, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attach", reflect.TypeOf((*MockTagRepository)(nil).Attach), ctx, tid, tagID, uid)
+}
+
+// Create mocks base method.
+func (m *MockTagRepository) Create(ctx context.Context, t *entity.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTagRepositoryMockRecorder) Create(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTagRepository)(nil).Create), ctx, t)
+}
+
+// Delete mocks base method.
+func (m *MockTagRepository) Delete(ctx context.Context, id, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTagRepositoryMockRecorder) Delete(ctx, id, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTagRepository)(nil).Delete), ctx, id, uid)
+}
+
+// Detach mocks base method.
+func (m *MockTagRepository) Detach(ctx context.Context, tid, tagID, uid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detach", ctx, tid, tagID, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Detach indicates an expected call of Detach.
+func (mr *MockTagRepositoryMockRecorder) Detach(ctx, tid, tagID, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detach", reflect.TypeOf((*MockTagRepository)(nil).Detach), ctx, tid, tagID, uid)
+}
+
+// FindByID mocks base method.
+func (m *MockTagRepository) FindByID(ctx context.Context, id, uid string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id, uid)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockTagRepositoryMockRecorder) FindByID(ctx, id, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTagRepository)(nil).FindByID), ctx, id, uid)
+}
+
+// FindByUser mocks base method.
+func (m *MockTagRepository) FindByUser(ctx context.Context, uid string) ([]*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUser", ctx, uid)
+	ret0, _ := ret[0].([]*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUser indicates an expected call of FindByUser.
+func (mr *MockTagRepositoryMockRecorder) FindByUser(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUser", reflect.TypeOf((*MockTagRepository)(nil).FindByUser), ctx, uid)
+}
+
+// Update mocks base method.
+func (m *MockTagRepository) Update(ctx context.Context, t *entity.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTagRepositoryMockRecorder) Update(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTagRepository)(nil).Update), ctx, t)
+}
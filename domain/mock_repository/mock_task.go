@@ -5,87 +5,205 @@
 package mock_repository
 
 import (
+	context "context"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/hiroyaonoe/todoapp-server/domain/entity"
-	reflect "reflect"
+	repository "github.com/hiroyaonoe/todoapp-server/domain/repository"
 )
 
-// MockTaskRepository is a mock of TaskRepository interface
+// MockTaskRepository is a mock of TaskRepository interface.
 type MockTaskRepository struct {
 	ctrl     *gomock.Controller
 	recorder *MockTaskRepositoryMockRecorder
 }
 
-// MockTaskRepositoryMockRecorder is the mock recorder for MockTaskRepository
+// MockTaskRepositoryMockRecorder is the mock recorder for MockTaskRepository.
 type MockTaskRepositoryMockRecorder struct {
 	mock *MockTaskRepository
 }
 
-// NewMockTaskRepository creates a new mock instance
+// NewMockTaskRepository creates a new mock instance.
 func NewMockTaskRepository(ctrl *gomock.Controller) *MockTaskRepository {
 	mock := &MockTaskRepository{ctrl: ctrl}
 	mock.recorder = &MockTaskRepositoryMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method
-func (m *MockTaskRepository) Create(t *entity.Task) error {
+// CheckVersion mocks base method.
+func (m *MockTaskRepository) CheckVersion(ctx context.Context, tid, uid string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckVersion", ctx, tid, uid, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckVersion indicates an expected call of CheckVersion.
+func (mr *MockTaskRepositoryMockRecorder) CheckVersion(ctx, tid, uid, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckVersion", reflect.TypeOf((*MockTaskRepository)(nil).CheckVersion), ctx, tid, uid, version)
+}
+
+// Create mocks base method.
+func (m *MockTaskRepository) Create(ctx context.Context, t *entity.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTaskRepositoryMockRecorder) Create(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), ctx, t)
+}
+
+// Delete mocks base method.
+func (m *MockTaskRepository) Delete(ctx context.Context, tid, uid string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", t)
+	ret := m.ctrl.Call(m, "Delete", ctx, tid, uid, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Create indicates an expected call of Create
-func (mr *MockTaskRepositoryMockRecorder) Create(t interface{}) *gomock.Call {
+// Delete indicates an expected call of Delete.
+func (mr *MockTaskRepositoryMockRecorder) Delete(ctx, tid, uid, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), t)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepository)(nil).Delete), ctx, tid, uid, version)
 }
 
-// FindByID mocks base method
-func (m *MockTaskRepository) FindByID(tid, uid string) (*entity.Task, error) {
+// Find mocks base method.
+func (m *MockTaskRepository) Find(ctx context.Context, tid string) (*entity.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", tid, uid)
+	ret := m.ctrl.Call(m, "Find", ctx, tid)
 	ret0, _ := ret[0].(*entity.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindByID indicates an expected call of FindByID
-func (mr *MockTaskRepositoryMockRecorder) FindByID(tid, uid interface{}) *gomock.Call {
+// Find indicates an expected call of Find.
+func (mr *MockTaskRepositoryMockRecorder) Find(ctx, tid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockTaskRepository)(nil).Find), ctx, tid)
+}
+
+// FindByID mocks base method.
+func (m *MockTaskRepository) FindByID(ctx context.Context, tid, uid string) (*entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, tid, uid)
+	ret0, _ := ret[0].(*entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockTaskRepositoryMockRecorder) FindByID(ctx, tid, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTaskRepository)(nil).FindByID), ctx, tid, uid)
+}
+
+// FindByUser mocks base method.
+func (m *MockTaskRepository) FindByUser(ctx context.Context, uid string, q repository.TaskQuery) ([]*entity.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUser", ctx, uid, q)
+	ret0, _ := ret[0].([]*entity.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUser indicates an expected call of FindByUser.
+func (mr *MockTaskRepositoryMockRecorder) FindByUser(ctx, uid, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUser", reflect.TypeOf((*MockTaskRepository)(nil).FindByUser), ctx, uid, q)
+}
+
+// Recur mocks base method.
+func (m *MockTaskRepository) Recur(ctx context.Context, done, next *entity.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recur", ctx, done, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Recur indicates an expected call of Recur.
+func (mr *MockTaskRepositoryMockRecorder) Recur(ctx, done, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recur", reflect.TypeOf((*MockTaskRepository)(nil).Recur), ctx, done, next)
+}
+
+// Search mocks base method.
+func (m *MockTaskRepository) Search(ctx context.Context, uid string, terms []entity.SearchTerm, limit int) ([]*entity.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, uid, terms, limit)
+	ret0, _ := ret[0].([]*entity.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockTaskRepositoryMockRecorder) Search(ctx, uid, terms, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockTaskRepository)(nil).Search), ctx, uid, terms, limit)
+}
+
+// Update mocks base method.
+func (m *MockTaskRepository) Update(ctx context.Context, t *entity.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTaskRepositoryMockRecorder) Update(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), ctx, t)
+}
+
+// UpdateCompletions mocks base method.
+func (m *MockTaskRepository) UpdateCompletions(ctx context.Context, uid string, tasks []*entity.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCompletions", ctx, uid, tasks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCompletions indicates an expected call of UpdateCompletions.
+func (mr *MockTaskRepositoryMockRecorder) UpdateCompletions(ctx, uid, tasks interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockTaskRepository)(nil).FindByID), tid, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompletions", reflect.TypeOf((*MockTaskRepository)(nil).UpdateCompletions), ctx, uid, tasks)
 }
 
-// Update mocks base method
-func (m *MockTaskRepository) Update(t *entity.Task) error {
+// UpdateParent mocks base method.
+func (m *MockTaskRepository) UpdateParent(ctx context.Context, t *entity.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", t)
+	ret := m.ctrl.Call(m, "UpdateParent", ctx, t)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update
-func (mr *MockTaskRepositoryMockRecorder) Update(t interface{}) *gomock.Call {
+// UpdateParent indicates an expected call of UpdateParent.
+func (mr *MockTaskRepositoryMockRecorder) UpdateParent(ctx, t interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), t)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateParent", reflect.TypeOf((*MockTaskRepository)(nil).UpdateParent), ctx, t)
 }
 
-// Delete mocks base method
-func (m *MockTaskRepository) Delete(tid, uid string) error {
+// UpdatePositions mocks base method.
+func (m *MockTaskRepository) UpdatePositions(ctx context.Context, uid string, tasks []*entity.Task) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", tid, uid)
+	ret := m.ctrl.Call(m, "UpdatePositions", ctx, uid, tasks)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Delete indicates an expected call of Delete
-func (mr *MockTaskRepositoryMockRecorder) Delete(tid, uid interface{}) *gomock.Call {
+// UpdatePositions indicates an expected call of UpdatePositions.
+func (mr *MockTaskRepositoryMockRecorder) UpdatePositions(ctx, uid, tasks interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskRepository)(nil).Delete), tid, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePositions", reflect.TypeOf((*MockTaskRepository)(nil).UpdatePositions), ctx, uid, tasks)
 }
@@ -0,0 +1,19 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// ActivityRepository is interface of Activity
+// Activityは追記のみで，変更や削除はしない
+type ActivityRepository interface {
+	Create(ctx context.Context, a *entity.Activity) (err error)
+	// FindByEntity はエンティティへのActivityを新しい順にpageの範囲だけ取得し，Activityの総数とともに返す
+	FindByEntity(ctx context.Context, entityType entity.ActivityEntity, id string, page entity.Page) (activities []*entity.Activity, total int64, err error)
+	// FindByActor はuidのユーザーが行ったActivityを新しい順にpageの範囲だけ取得し，Activityの総数とともに返す
+	FindByActor(ctx context.Context, uid string, page entity.Page) (activities []*entity.Activity, total int64, err error)
+}
@@ -0,0 +1,20 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// AssignmentRepository is interface of Assignment
+type AssignmentRepository interface {
+	// Create はTaskの担当者をaのAssigneeIDに変更し，その変更を記録する．Taskが存在しない場合はentity.ErrRecordNotFoundを返す
+	Create(ctx context.Context, a *entity.Assignment) (err error)
+	// Dispatch は通知していない担当者の変更を古い順に最大limit件確保してsendに渡し，sendが成功したものを通知済みにする
+	// 確保した変更は他のDispatchでは取得せず，通知済みにするのは変更ごとにコミットするので，同じ変更を通知するのは一度だけである
+	// 担当者を外した変更は通知しない．sendが失敗したものはAttemptsを増やし，entity.MaxAssignmentAttempts回失敗するまで再送する
+	Dispatch(ctx context.Context, now time.Time, limit int, send func(ctx context.Context, a *entity.Assignment) error) (sent int, err error)
+}
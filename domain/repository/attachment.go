@@ -0,0 +1,25 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// AttachmentRepository is interface of Attachment
+type AttachmentRepository interface {
+	// Create はAttachmentを記録する．UserIDのユーザーが添付したファイルの合計のSizeがquotaを超える場合はentity.ErrQuotaExceededを返す
+	Create(ctx context.Context, a *entity.Attachment, quota int64) (err error)
+	// FindByID はtidのTaskのidのAttachmentを取得する
+	FindByID(ctx context.Context, tid string, id string) (attachment *entity.Attachment, err error)
+	// FindByTask はTaskのAttachmentを添付した順に取得する
+	FindByTask(ctx context.Context, tid string) (attachments []*entity.Attachment, err error)
+	// Usage はuidのユーザーが添付したファイルの合計のSizeを返す
+	Usage(ctx context.Context, uid string) (size int64, err error)
+	// Delete はAttachmentを削除し，ファイルをOrphanBlobとしてBlobStoreから削除するように記録する
+	Delete(ctx context.Context, tid string, id string) (err error)
+	// Purge はOrphanBlobを古い順に最大limit件ロックしてremoveに渡し，removeが成功したものを削除する
+	Purge(ctx context.Context, limit int, remove func(ctx context.Context, key string) error) (removed int, err error)
+}
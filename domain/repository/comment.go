@@ -0,0 +1,21 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// CommentRepository is interface of Comment
+type CommentRepository interface {
+	Create(ctx context.Context, c *entity.Comment) (err error)
+	// FindByID はtidのTaskのidのCommentを取得する
+	FindByID(ctx context.Context, tid string, id string) (comment *entity.Comment, err error)
+	// FindByTask はTaskのCommentを書いた順にpageの範囲だけ取得し，Commentの総数とともに返す
+	FindByTask(ctx context.Context, tid string, page entity.Page) (comments []*entity.Comment, total int64, err error)
+	// Update はCommentのBodyを更新し，編集したことを記録する
+	Update(ctx context.Context, c *entity.Comment) (err error)
+	Delete(ctx context.Context, tid string, id string) (err error)
+}
@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// IdempotencyRepository is interface of IdempotencyKey
+type IdempotencyRepository interface {
+	// Reserve は同じUserIDとKeyの記録がなければkを処理中として保存してnilを返し，あればその記録を返す
+	// nowまでに期限の切れた記録と，処理中のままLockedUntilを過ぎた記録はないものとして扱う
+	Reserve(ctx context.Context, k *entity.IdempotencyKey, now time.Time) (existing *entity.IdempotencyKey, err error)
+	// Complete は処理中のkにレスポンスを保存する．LockedUntilが異なる記録は他のリクエストが引き継いだものとして変更しない
+	Complete(ctx context.Context, k *entity.IdempotencyKey) (err error)
+	// Release は処理中のkを削除する．LockedUntilが異なる記録は削除しない
+	Release(ctx context.Context, k *entity.IdempotencyKey) (err error)
+	// DeleteExpired はnowまでに期限の切れた記録を削除する
+	DeleteExpired(ctx context.Context, now time.Time) (deleted int64, err error)
+}
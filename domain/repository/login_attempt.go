@@ -0,0 +1,19 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// LoginAttemptRepository is interface of LoginAttempt
+type LoginAttemptRepository interface {
+	FindByAccount(ctx context.Context, account string) (attempt *entity.LoginAttempt, err error)
+	// Fail はaccountの失敗回数を1増やし，増やした後の回数をlockに渡して返った期間アカウントをロックする
+	// 同時に失敗しても回数を数え漏らさないように，記録がなければ作成し，あれば行をロックして更新する
+	Fail(ctx context.Context, account string, now time.Time, lock func(failures int) time.Duration) (attempt *entity.LoginAttempt, err error)
+	Delete(ctx context.Context, account string) (err error)
+}
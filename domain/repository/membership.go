@@ -0,0 +1,26 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MembershipRepository is interface of Membership
+type MembershipRepository interface {
+	// Create はメンバーを追加する．既にメンバーである場合はErrMySQL(ER_DUP_ENTRY)を返す
+	Create(ctx context.Context, m *entity.Membership) (err error)
+	// FindByID はWorkspaceIDのWorkspaceのidのMembershipを取得する
+	FindByID(ctx context.Context, wid string, id string) (membership *entity.Membership, err error)
+	// Find はuidのユーザーのWorkspaceIDのWorkspaceでのMembershipを取得する
+	Find(ctx context.Context, wid string, uid string) (membership *entity.Membership, err error)
+	// FindByWorkspace はWorkspaceのメンバーを追加した順に取得する
+	FindByWorkspace(ctx context.Context, wid string) (memberships []*entity.Membership, err error)
+	// FindByUser はuidのユーザーが参加しているWorkspaceのMembershipを追加した順に取得する
+	FindByUser(ctx context.Context, uid string) (memberships []*entity.Membership, err error)
+	// Update はMembershipのRoleのみを更新する
+	Update(ctx context.Context, m *entity.Membership) (err error)
+	Delete(ctx context.Context, wid string, id string) (err error)
+}
@@ -0,0 +1,25 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// ProjectRepository is interface of Project
+// 取得したProjectにはTaskの件数と完了したTaskの件数が含まれる
+type ProjectRepository interface {
+	Create(ctx context.Context, p *entity.Project) (err error)
+	FindByID(ctx context.Context, id string, uid string) (project *entity.Project, err error)
+	FindByUser(ctx context.Context, uid string) (projects []*entity.Project, err error)
+	// Find は所有者に関わらずProjectを取得する．アクセスできるかはusecaseで判定する
+	Find(ctx context.Context, id string) (project *entity.Project, err error)
+	// FindByIDs は所有者に関わらずidsのProjectを作成した順に取得する
+	FindByIDs(ctx context.Context, ids []string) (projects []*entity.Project, err error)
+	Update(ctx context.Context, p *entity.Project) (err error)
+	// Delete はProjectを削除する．deleteTasksがfalseの場合はTaskをインボックスに移動する
+	// tasksは削除または移動したTaskの変更前の状態であり，deleteTasksがtrueの場合は一緒に削除したサブタスクも含む
+	Delete(ctx context.Context, id string, uid string, deleteTasks bool) (tasks []*entity.Task, err error)
+}
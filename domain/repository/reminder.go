@@ -0,0 +1,25 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// ReminderRepository is interface of Reminder
+type ReminderRepository interface {
+	Create(ctx context.Context, r *entity.Reminder) (err error)
+	// FindByTask はTaskのReminderを通知する時刻の順に取得する
+	FindByTask(ctx context.Context, tid string, uid string) (reminders []*entity.Reminder, err error)
+	// Reschedule は未送信のremindersのRemindAtのみを更新する
+	Reschedule(ctx context.Context, uid string, reminders []*entity.Reminder) (err error)
+	Delete(ctx context.Context, id string, tid string, uid string) (err error)
+	// Dispatch はnow以前に通知する未送信のReminderを最大limit件確保してsendに渡し，sendが成功したものを送信済みにする
+	// 確保したReminderは他のDispatchでは取得しないので，複数のサーバーで同時に実行しても送るのは一度だけである
+	// 送信済みにするのはReminderごとにコミットするので，途中でctxが期限を過ぎても送ったReminderを再び送ることはない
+	// sendが失敗したReminderはAttemptsを増やし，entity.MaxReminderAttempts回失敗するまで再送する
+	Dispatch(ctx context.Context, now time.Time, limit int, send func(ctx context.Context, r *entity.Reminder) error) (sent int, err error)
+}
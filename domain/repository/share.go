@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// ShareRepository is interface of Share
+type ShareRepository interface {
+	// Create は招待を作成する．同じものを同じユーザーに共有する招待が既にある場合はErrMySQL(ER_DUP_ENTRY)を返す
+	Create(ctx context.Context, s *entity.Share) (err error)
+	FindByID(ctx context.Context, id string) (share *entity.Share, err error)
+	// FindByUser はuidが所有者または共有されたユーザーであるShareを作成した順に取得する
+	FindByUser(ctx context.Context, uid string) (shares []*entity.Share, err error)
+	// Accept はuidに共有する未承諾のShareのAcceptedAtをatにする
+	Accept(ctx context.Context, id string, uid string, at time.Time) (err error)
+	// Delete はuidが所有者または共有されたユーザーであるShareを削除する
+	Delete(ctx context.Context, id string, uid string) (err error)
+}
@@ -0,0 +1,22 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// TagRepository is interface of Tag
+type TagRepository interface {
+	Create(ctx context.Context, t *entity.Tag) (err error)
+	FindByID(ctx context.Context, id string, uid string) (tag *entity.Tag, err error)
+	FindByUser(ctx context.Context, uid string) (tags []*entity.Tag, err error)
+	Update(ctx context.Context, t *entity.Tag) (err error)
+	Delete(ctx context.Context, id string, uid string) (err error)
+	// Attach はuidのTaskにuidのTagを付ける．既に付いている場合は何もしない
+	Attach(ctx context.Context, tid string, tagID string, uid string) (err error)
+	// Detach はuidのTaskからTagを外す
+	Detach(ctx context.Context, tid string, tagID string, uid string) (err error)
+}
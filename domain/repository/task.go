@@ -3,14 +3,77 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 )
 
+// TaskSort はTaskの一覧の並び順である
+type TaskSort int
+
+const (
+	// SortByPosition は日付ごとに手動で並べた順
+	SortByPosition TaskSort = iota
+	// SortByPriority は優先度の高い順．同じ優先度の場合はSortByPositionの順
+	SortByPriority
+)
+
+// TaskQuery はTaskの一覧を取得する条件である
+type TaskQuery struct {
+	// Date が指定されている場合はその日のTaskのみを取得する
+	Date entity.NullDate
+	// From とTo が指定されている場合はその期間のTaskと，期間より前に始まる未完了の繰り返しのTaskを取得する
+	From entity.NullDate
+	To   entity.NullDate
+	// Before が指定されている場合はその日より前のTaskのみを取得する
+	Before entity.NullDate
+	// Incomplete がtrueの場合は未完了のTaskのみを取得する
+	Incomplete bool
+	// SharedTaskIDs とSharedProjectIDs が指定されている場合はuidのTaskに加えて，それらのTaskとProjectのTaskも取得する
+	SharedTaskIDs    []string
+	SharedProjectIDs []string
+	// WorkspaceIDs が指定されている場合はそれらのWorkspaceのTaskも取得する
+	WorkspaceIDs []string
+	// TagIDs が指定されている場合はそのすべてのTagが付いたTaskのみを取得する
+	TagIDs []string
+	// ProjectID が指定されている場合はそのProjectのTaskのみを取得する
+	ProjectID string
+	// Inbox がtrueの場合はProjectに属さないTaskのみを取得する
+	Inbox bool
+	// WorkspaceID が指定されている場合はそのWorkspaceのTaskのみを取得する
+	WorkspaceID string
+	// AssigneeID が指定されている場合はそのユーザーが担当するTaskのみを，サブタスクも含めて取得する
+	AssigneeID string
+	// ParentID が指定されている場合はそのTaskのサブタスクのみを，指定されていない場合は親のないTaskのみを取得する
+	// AssigneeID が指定されている場合は親の有無で絞り込まない
+	ParentID string
+	Sort     TaskSort
+}
+
 // TaskRepository is interface of Task
 type TaskRepository interface {
-	Create(t *entity.Task) (err error)
-	FindByID(tid string, uid string) (task *entity.Task, err error)
-	// 	FindByUser(uid int) (tasks []*entity.Task, err error)
-	Update(t *entity.Task) (err error)
-	Delete(tid string, uid string) (err error)
+	Create(ctx context.Context, t *entity.Task) (err error)
+	FindByID(ctx context.Context, tid string, uid string) (task *entity.Task, err error)
+	// Find は所有者に関わらずTaskを取得する．アクセスできるかはusecaseで判定する
+	Find(ctx context.Context, tid string) (task *entity.Task, err error)
+	FindByUser(ctx context.Context, uid string, q TaskQuery) (tasks []*entity.Task, err error)
+	// Search はuidのTaskのうちtermsのすべてにTitleかContentが一致するものを一致度の高い順に最大limit件取得する
+	// 結果のTitleとSnippetは設定しない
+	Search(ctx context.Context, uid string, terms []entity.SearchTerm, limit int) (results []*entity.SearchResult, err error)
+	// Update はt.Versionが0でない場合，Taskのバージョンが一致しなければentity.ErrVersionConflictを返す
+	// 更新後のバージョンをt.Versionに設定する
+	Update(ctx context.Context, t *entity.Task) (err error)
+	// CheckVersion はTaskの行をロックし，バージョンがversionと一致しなければentity.ErrVersionConflictを返す
+	// トランザクションの中で呼び，コミットするまで他の変更を待たせる
+	CheckVersion(ctx context.Context, tid string, uid string, version int) (err error)
+	// UpdatePositions はtasksのPositionのみを更新する
+	UpdatePositions(ctx context.Context, uid string, tasks []*entity.Task) (err error)
+	// UpdateCompletions はtasksのIsCompletedのみを更新する
+	UpdateCompletions(ctx context.Context, uid string, tasks []*entity.Task) (err error)
+	// UpdateParent はtのParentIDとPositionのみを更新する
+	UpdateParent(ctx context.Context, t *entity.Task) (err error)
+	// Recur は繰り返しのTaskの回doneを完了にして繰り返しを外し，次の回nextを作成する．nextがnilの場合は作成しない
+	Recur(ctx context.Context, done *entity.Task, next *entity.Task) (err error)
+	// Delete はversionが0でない場合，Taskのバージョンが一致しなければentity.ErrVersionConflictを返す
+	Delete(ctx context.Context, tid string, uid string, version int) (err error)
 }
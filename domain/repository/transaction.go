@@ -0,0 +1,12 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import "context"
+
+// Transactor はリポジトリの複数の操作をひとつのトランザクションで実行する
+type Transactor interface {
+	// Transaction はfnに渡したctxで行うリポジトリの操作をひとつのトランザクションで実行する
+	// fnがエラーを返した場合はすべての操作を取り消す
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) (err error)
+}
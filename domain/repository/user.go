@@ -9,13 +9,19 @@ Package repository is Enterprise Business Rules.
 package repository
 
 import (
+	"context"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 )
 
 // UserRepository is interface of User
 type UserRepository interface {
-	FindByID(id string) (user *entity.User, err error)
-	Create(u *entity.User) (err error)
-	Update(u *entity.User) (err error)
-	Delete(id string) (err error)
+	FindByID(ctx context.Context, id string) (user *entity.User, err error)
+	FindByEmail(ctx context.Context, email string) (user *entity.User, err error)
+	Create(ctx context.Context, u *entity.User) (err error)
+	// Update はu.Versionが0でない場合，Userのバージョンが一致しなければentity.ErrVersionConflictを返す
+	// 更新後のバージョンをu.Versionに設定する
+	Update(ctx context.Context, u *entity.User) (err error)
+	// Delete はversionが0でない場合，Userのバージョンが一致しなければentity.ErrVersionConflictを返す
+	Delete(ctx context.Context, id string, version int) (err error)
 }
@@ -0,0 +1,23 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_repository/mock_$GOFILE -package=mock_repository
+
+package repository
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// WorkspaceRepository is interface of Workspace
+// 取得したWorkspaceのRoleは設定しない
+type WorkspaceRepository interface {
+	// Create はWorkspaceを作成し，ownerのユーザーをWorkspaceOwnerのメンバーにする
+	Create(ctx context.Context, w *entity.Workspace, owner string) (err error)
+	FindByID(ctx context.Context, id string) (workspace *entity.Workspace, err error)
+	// FindByIDs はidsのWorkspaceを作成した順に取得する
+	FindByIDs(ctx context.Context, ids []string) (workspaces []*entity.Workspace, err error)
+	Update(ctx context.Context, w *entity.Workspace) (err error)
+	// Delete はWorkspaceとそのメンバーを削除する．WorkspaceのTaskは作成したユーザーのTaskとして残す
+	// tasksはWorkspaceから外したTaskの変更前の状態である
+	Delete(ctx context.Context, id string) (tasks []*entity.Task, err error)
+}
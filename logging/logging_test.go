@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+func TestNew_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.InfoContext(ctx, "hello")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", got["request_id"])
+	}
+}
+
+func TestNew_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	user := entity.NewUser("userid", "name", "secretpassword", "email@example.com")
+	logger.Info("user", slog.Any("user", user), slog.Any("password", user.Password))
+	logger.Info(user.String())
+
+	if strings.Contains(buf.String(), "secretpassword") {
+		t.Errorf("password is leaked:\n%s", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{in: "debug", want: slog.LevelDebug},
+		{in: "WARN", want: slog.LevelWarn},
+		{in: "", want: slog.LevelInfo},
+		{in: "unknown", want: slog.LevelInfo},
+	}
+	for _, tt := range tests {
+		if got := ParseLevel(tt.in); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,98 @@
 package main
 
 import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/hiroyaonoe/todoapp-server/blobstore"
+	"github.com/hiroyaonoe/todoapp-server/config"
 	"github.com/hiroyaonoe/todoapp-server/database"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+	"github.com/hiroyaonoe/todoapp-server/notifier"
+	"github.com/hiroyaonoe/todoapp-server/scheduler"
+	"github.com/hiroyaonoe/todoapp-server/telemetry"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
 	"github.com/hiroyaonoe/todoapp-server/web"
 )
 
 func main() {
+	logger := logging.New(os.Stdout, logging.ParseLevel(config.LogLevel()))
+
+	shutdown, err := telemetry.Setup(context.Background())
+	if err != nil {
+		logger.Error("failed to set up tracing", slog.Any("error", err))
+		os.Exit(1)
+	}
+	defer shutdown(context.Background())
+
 	db := database.NewDB()
 	// db := database.NewTestDB()
-	db.LogMode(true)
-	user := database.NewUserRepository(db)
-	task := database.NewTaskRepository(db)
-	r := web.NewRouting(user, task)
+	db.SetLogger(logger)
+	user := database.NewUserRepository(db, logger)
+	task := database.NewTaskRepository(db, logger)
+	tag := database.NewTagRepository(db, logger)
+	project := database.NewProjectRepository(db, logger)
+	reminder := database.NewReminderRepository(db, logger)
+	share := database.NewShareRepository(db, logger)
+	workspace := database.NewWorkspaceRepository(db, logger)
+	membership := database.NewMembershipRepository(db, logger)
+	assignment := database.NewAssignmentRepository(db, logger)
+	comment := database.NewCommentRepository(db, logger)
+	attachment := database.NewAttachmentRepository(db, logger)
+	activity := database.NewActivityRepository(db, logger)
+	transactor := database.NewTransactor(db)
+	attempt := database.NewLoginAttemptRepository(db, logger)
+	idempotency := database.NewIdempotencyRepository(db, logger)
+
+	blob, err := blobstore.New(config.BlobStore())
+	if err != nil {
+		logger.Error("failed to set up blob store", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	// 期限のReminderと担当者の変更の通知，削除した添付ファイルと期限の切れたIdempotency-Keyの削除を行うスケジューラーをサーバーと同じプロセスで動かす
+	// 削除した添付ファイルとIdempotency-Keyの削除は通知を動かさない場合も行うので，それぞれ別のスケジューラーで動かす
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if interval := config.ReminderInterval(); interval > 0 {
+		n, err := notifier.New(config.Notifiers(), logger)
+		if err != nil {
+			logger.Error("failed to set up notifier", slog.Any("error", err))
+			os.Exit(1)
+		}
+		dispatcher := usecase.NewReminderDispatcher(reminder, task, user, n, config.ReminderBatchSize(), logger)
+		s := scheduler.New(interval, logger)
+		s.Add("reminder", func(ctx context.Context) error {
+			_, err := dispatcher.Dispatch(ctx)
+			return err
+		})
+		assignments := usecase.NewAssignmentDispatcher(assignment, task, user, n, config.ReminderBatchSize(), logger)
+		s.Add("assignment", func(ctx context.Context) error {
+			_, err := assignments.Dispatch(ctx)
+			return err
+		})
+		go s.Run(ctx)
+	}
+	if interval := config.BlobCleanupInterval(); interval > 0 {
+		cleaner := usecase.NewBlobCleaner(attachment, blob, config.ReminderBatchSize(), logger)
+		s := scheduler.New(interval, logger)
+		s.Add("attachment", func(ctx context.Context) error {
+			_, err := cleaner.Clean(ctx)
+			return err
+		})
+		go s.Run(ctx)
+	}
+	if interval := config.IdempotencyCleanupInterval(); interval > 0 {
+		keys := usecase.NewIdempotencyInteractor(idempotency, config.IdempotencyTTL(), config.IdempotencyLockTimeout(), logger)
+		s := scheduler.New(interval, logger)
+		s.Add("idempotency", func(ctx context.Context) error {
+			_, err := keys.Clean(ctx)
+			return err
+		})
+		go s.Run(ctx)
+	}
+
+	r := web.NewRouting(user, task, tag, project, reminder, share, workspace, membership, assignment, comment, attachment, blob, activity, transactor, attempt, idempotency, logger)
 	r.Run()
 }
@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// Email は通知をユーザーのメールアドレスにSMTPで送る
+type Email struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+	// send はテストで差し替えるためのsmtp.SendMailである
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewEmail(addr, from, username, password string) *Email {
+	e := &Email{Addr: addr, From: from, send: smtp.SendMail}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		e.Auth = smtp.PlainAuth("", username, password, host)
+	}
+	return e
+}
+
+func (e *Email) Notify(ctx context.Context, n *entity.Notification) error {
+	if n.Email == "" {
+		return errors.New("notification has no email address")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.send(e.Addr, e.Auth, e.From, []string{n.Email}, e.message(n))
+}
+
+// message はメールのヘッダーと本文を作る．件名はヘッダーの改行を含まないようにMIMEエンコードする
+func (e *Email) message(n *entity.Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", n.Email)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
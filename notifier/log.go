@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// Log は通知をログに出力する．メールアドレスは出力しない
+type Log struct {
+	logger *slog.Logger
+}
+
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+func (l *Log) Notify(ctx context.Context, n *entity.Notification) error {
+	l.logger.InfoContext(ctx, "notification",
+		slog.String("kind", string(n.Kind)),
+		slog.String("user_id", n.UserID),
+		slog.String("task_id", n.TaskID),
+		slog.String("subject", n.Subject),
+	)
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Package notifier is Frameworks & Drivers.
+ユーザーへの通知をwebhook，メール，ログに送る
+*/
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/config"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// 通知の送り先の種類
+const (
+	KindLog     = "log"
+	KindWebhook = "webhook"
+	KindEmail   = "email"
+)
+
+// Notifier はユーザーに通知を送る
+type Notifier interface {
+	Notify(ctx context.Context, n *entity.Notification) error
+}
+
+// Multi は複数のNotifierに通知を送る．ひとつでも失敗した場合はエラーを返す
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, n *entity.Notification) error {
+	errs := []error{}
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New は環境変数に従ってkindsの送り先に通知を送るNotifierを返す
+func New(kinds []string, logger *slog.Logger) (Multi, error) {
+	m := Multi{}
+	for _, kind := range kinds {
+		switch kind {
+		case KindLog:
+			m = append(m, NewLog(logger))
+		case KindWebhook:
+			if config.WebhookURL() == "" {
+				return nil, errors.New("WEBHOOK_URL is required for webhook notifier")
+			}
+			m = append(m, NewWebhook(config.WebhookURL(), config.WebhookTimeout()))
+		case KindEmail:
+			if config.SMTPAddr() == "" || config.SMTPFrom() == "" {
+				return nil, errors.New("SMTP_ADDR and SMTP_FROM are required for email notifier")
+			}
+			username, password := config.SMTPAuth()
+			m = append(m, NewEmail(config.SMTPAddr(), config.SMTPFrom(), username, password))
+		default:
+			return nil, fmt.Errorf("unknown notifier: %q", kind)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+func newNotification() *entity.Notification {
+	return &entity.Notification{
+		Kind:    entity.NotificationReminder,
+		UserID:  "userid",
+		Email:   "user@example.com",
+		TaskID:  "taskid",
+		Subject: "Reminder: title",
+		Body:    "\"title\" is due at 2020-12-06 09:00 JST.",
+		At:      time.Date(2020, 12, 5, 22, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "2xxなら成功", status: http.StatusNoContent, wantErr: false},
+		{name: "2xx以外なら失敗", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got entity.Notification
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			want := newNotification()
+			err := NewWebhook(server.URL, time.Second).Notify(context.Background(), want)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(*want, got); diff != "" {
+				t.Errorf("body (-want +got) =\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEmail_Notify(t *testing.T) {
+	e := NewEmail("smtp.example.com:587", "todo@example.com", "", "")
+	var to []string
+	var msg string
+	e.send = func(addr string, a smtp.Auth, from string, rcpt []string, m []byte) error {
+		to, msg = rcpt, string(m)
+		return nil
+	}
+
+	n := newNotification()
+	// 件名の改行でヘッダーを追加できないようにする
+	n.Subject = "Reminder: title\r\nBcc: attacker@example.com"
+	err := e.Notify(context.Background(), n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"user@example.com"}, to); diff != "" {
+		t.Errorf("to (-want +got) =\n%s", diff)
+	}
+	header, body, _ := strings.Cut(msg, "\r\n\r\n")
+	if strings.Contains(header, "\r\nBcc:") {
+		t.Errorf("header contains injected field:\n%s", header)
+	}
+	if !strings.Contains(body, n.Body) {
+		t.Errorf("body = %q, want to contain %q", body, n.Body)
+	}
+
+	n.Email = ""
+	if err := e.Notify(context.Background(), n); err == nil {
+		t.Error("Notify() without email address should fail")
+	}
+}
+
+type notifierFunc func(ctx context.Context, n *entity.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n *entity.Notification) error {
+	return f(ctx, n)
+}
+
+func TestMulti_Notify(t *testing.T) {
+	called := 0
+	ok := notifierFunc(func(ctx context.Context, n *entity.Notification) error {
+		called++
+		return nil
+	})
+	errFailed := errors.New("failed")
+	ng := notifierFunc(func(ctx context.Context, n *entity.Notification) error {
+		called++
+		return errFailed
+	})
+
+	err := Multi{ng, ok}.Notify(context.Background(), newNotification())
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Notify() error = %v, want %v", err, errFailed)
+	}
+	// 失敗しても残りのNotifierに送る
+	if called != 2 {
+		t.Errorf("called = %d, want 2", called)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Package scheduler is Frameworks & Drivers.
+サーバー内で定期的に実行する処理(Job)を動かす
+*/
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Clock は次の実行まで待つための時計である．テストでは偽の時計に差し替える
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock はシステムの時刻で待つClockである
+type SystemClock struct{}
+
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Job は定期的に実行する処理である
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Scheduler はIntervalごとにJobを順に実行する
+// 複数のサーバーで同時に動かすので，Jobは重複して実行されても問題ないようにする
+type Scheduler struct {
+	Interval time.Duration
+	Clock    Clock
+	Logger   *slog.Logger
+	jobs     []Job
+}
+
+func New(interval time.Duration, logger *slog.Logger) *Scheduler {
+	return &Scheduler{Interval: interval, Clock: SystemClock{}, Logger: logger}
+}
+
+// Add はJobを追加する
+func (s *Scheduler) Add(name string, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Run: run})
+}
+
+// Run はctxがキャンセルされるまでIntervalごとにJobを実行する
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.Clock.After(s.Interval):
+		}
+		s.RunOnce(ctx)
+	}
+}
+
+// RunOnce はすべてのJobを1回ずつ実行する．Jobが次の実行まで終わらない場合はキャンセルする
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	for _, job := range s.jobs {
+		jobCtx, cancel := context.WithTimeout(ctx, s.Interval)
+		err := job.Run(jobCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			s.Logger.ErrorContext(ctx, "scheduled job failed", slog.String("job", job.Name), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+// fakeClock はticksに送った時刻に待ちが終わり，Nowはnowを返す時計である
+type fakeClock struct {
+	now   time.Time
+	ticks chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, ticks: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.ticks
+}
+
+func TestScheduler_Run(t *testing.T) {
+	clock := newFakeClock(time.Time{})
+	s := New(time.Minute, logging.Discard())
+	s.Clock = clock
+	runs := make(chan struct{})
+	s.Add("count", func(ctx context.Context) error {
+		runs <- struct{}{}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// 時計が進むまではJobを実行しない
+	select {
+	case <-runs:
+		t.Fatal("job ran before the clock ticked")
+	case <-time.After(10 * time.Millisecond):
+	}
+	for i := 0; i < 2; i++ {
+		clock.ticks <- time.Time{}
+		<-runs
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was canceled")
+	}
+}
+
+type recordNotifier struct {
+	notifications []*entity.Notification
+}
+
+func (r *recordNotifier) Notify(ctx context.Context, n *entity.Notification) error {
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func TestScheduler_ReminderDispatcher(t *testing.T) {
+	now := time.Date(2020, 12, 5, 22, 30, 0, 0, time.UTC)
+	due := &entity.Reminder{
+		ID:       entity.NewNullString("due"),
+		TaskID:   entity.NewNullString("taskA"),
+		UserID:   entity.NewNullString("userA"),
+		RemindAt: now,
+	}
+	completed := &entity.Reminder{
+		ID:       entity.NewNullString("completed"),
+		TaskID:   entity.NewNullString("taskB"),
+		UserID:   entity.NewNullString("userA"),
+		RemindAt: now.Add(-time.Hour),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reminderRepo := mock_repository.NewMockReminderRepository(ctrl)
+	reminderRepo.EXPECT().Dispatch(gomock.Any(), now, 10, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ time.Time, _ int, send func(context.Context, *entity.Reminder) error) (int, error) {
+			for _, r := range []*entity.Reminder{completed, due} {
+				if err := send(ctx, r); err != nil {
+					return 0, err
+				}
+			}
+			return 2, nil
+		})
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	taskA := entity.NewTask("taskA", "title", "", "userA", "2020-12-06")
+	taskA.DueTime = entity.NewNullString("09:00")
+	taskRepo.EXPECT().FindByID(gomock.Any(), "taskA", "userA").Return(taskA, nil)
+	taskRepo.EXPECT().FindByID(gomock.Any(), "taskB", "userA").Return(entity.NewTask("taskB", "done", "", "userA", "2020-12-06").SetComp(true), nil)
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	user := entity.NewUser("userA", "username", "", "user@example.com")
+	user.TimeZone = entity.NewNullString("Asia/Tokyo")
+	userRepo.EXPECT().FindByID(gomock.Any(), "userA").Return(user, nil)
+
+	notifier := &recordNotifier{}
+	dispatcher := usecase.NewReminderDispatcher(reminderRepo, taskRepo, userRepo, notifier, 10, logging.Discard())
+	dispatcher.Clock = newFakeClock(now)
+
+	s := New(time.Minute, logging.Discard())
+	s.Add("reminder", func(ctx context.Context) error {
+		_, err := dispatcher.Dispatch(ctx)
+		return err
+	})
+	s.RunOnce(context.Background())
+
+	// 完了したTaskのReminderは通知しない
+	want := []*entity.Notification{
+		{
+			Kind:    entity.NotificationReminder,
+			UserID:  "userA",
+			Email:   "user@example.com",
+			TaskID:  "taskA",
+			Subject: "Reminder: title",
+			Body:    "\"title\" is due at 2020-12-06 09:00 JST.",
+			At:      now,
+		},
+	}
+	if diff := cmp.Diff(want, notifier.notifications); diff != "" {
+		t.Errorf("notifications (-want +got) =\n%s", diff)
+	}
+}
+
+func TestScheduler_AssignmentDispatcher(t *testing.T) {
+	now := time.Date(2020, 12, 5, 22, 30, 0, 0, time.UTC)
+	assigned := &entity.Assignment{
+		ID:         entity.NewNullString("assigned"),
+		TaskID:     entity.NewNullString("taskA"),
+		AssigneeID: entity.NewNullString("userB"),
+		AssignerID: entity.NewNullString("userA"),
+		CreatedAt:  now.Add(-time.Minute),
+	}
+	self := &entity.Assignment{
+		ID:         entity.NewNullString("self"),
+		TaskID:     entity.NewNullString("taskA"),
+		AssigneeID: entity.NewNullString("userA"),
+		AssignerID: entity.NewNullString("userA"),
+		CreatedAt:  now.Add(-time.Hour),
+	}
+	stale := &entity.Assignment{
+		ID:         entity.NewNullString("stale"),
+		TaskID:     entity.NewNullString("taskB"),
+		AssigneeID: entity.NewNullString("userB"),
+		AssignerID: entity.NewNullString("userA"),
+		CreatedAt:  now.Add(-time.Hour),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	assignmentRepo := mock_repository.NewMockAssignmentRepository(ctrl)
+	assignmentRepo.EXPECT().Dispatch(gomock.Any(), now, 10, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ time.Time, _ int, send func(context.Context, *entity.Assignment) error) (int, error) {
+			for _, a := range []*entity.Assignment{self, stale, assigned} {
+				if err := send(ctx, a); err != nil {
+					return 0, err
+				}
+			}
+			return 3, nil
+		})
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	taskA := entity.NewTask("taskA", "title", "", "userA", "2020-12-06")
+	taskA.AssigneeID = entity.NewNullString("userB")
+	taskRepo.EXPECT().Find(gomock.Any(), "taskA").Return(taskA, nil)
+	// 担当者が既に外されたTask
+	taskRepo.EXPECT().Find(gomock.Any(), "taskB").Return(entity.NewTask("taskB", "other", "", "userA", "2020-12-06"), nil)
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().FindByID(gomock.Any(), "userA").Return(entity.NewUser("userA", "owner", "", "owner@example.com"), nil)
+	userRepo.EXPECT().FindByID(gomock.Any(), "userB").Return(entity.NewUser("userB", "guest", "", "guest@example.com"), nil)
+
+	notifier := &recordNotifier{}
+	dispatcher := usecase.NewAssignmentDispatcher(assignmentRepo, taskRepo, userRepo, notifier, 10, logging.Discard())
+	dispatcher.Clock = newFakeClock(now)
+
+	s := New(time.Minute, logging.Discard())
+	s.Add("assignment", func(ctx context.Context) error {
+		_, err := dispatcher.Dispatch(ctx)
+		return err
+	})
+	s.RunOnce(context.Background())
+
+	// 自分を担当者にした変更と担当者が既に変わった変更は通知しない
+	want := []*entity.Notification{
+		{
+			Kind:    entity.NotificationAssignment,
+			UserID:  "userB",
+			Email:   "guest@example.com",
+			TaskID:  "taskA",
+			Subject: "Assigned: title",
+			Body:    "owner assigned \"title\" to you.",
+			At:      now.Add(-time.Minute),
+		},
+	}
+	if diff := cmp.Diff(want, notifier.notifications); diff != "" {
+		t.Errorf("notifications (-want +got) =\n%s", diff)
+	}
+}
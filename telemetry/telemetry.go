@@ -0,0 +1,86 @@
+/*
+Package telemetry is Frameworks & Drivers.
+OpenTelemetryのトレーサーとエクスポーターの設定を行う
+*/
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/hiroyaonoe/todoapp-server/config"
+)
+
+// ServiceName はトレースに記録するサービス名
+const ServiceName = "todoapp-server"
+
+// エクスポーターの種類
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup は環境変数に従ってTracerProviderとW3C Trace Contextのpropagatorをグローバルに登録する
+// 返り値のshutdownはサーバー終了時に呼び出してバッファされたスパンを送信する
+func Setup(ctx context.Context) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(NewPropagator())
+
+	exporter, err := NewExporter(ctx, config.TraceExporter(), os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		// エクスポーターが指定されていない場合はotelのno-op providerのまま
+		return func(context.Context) error { return nil }, nil
+	}
+
+	tp := NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// NewPropagator はW3C traceparent/tracestateとbaggageを伝搬するpropagatorを返す
+func NewPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+}
+
+// NewTracerProvider はサービス名を設定したTracerProviderを生成する
+// テストではsdktrace.WithSyncer(tracetest.NewInMemoryExporter())を渡す
+func NewTracerProvider(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	)
+	opts = append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// NewExporter は名前に対応するSpanExporterを生成する
+// ExporterNoneの場合はnilを返す
+// OTLPの送信先はOTEL_EXPORTER_OTLP_ENDPOINTなどの標準の環境変数で設定する
+func NewExporter(ctx context.Context, name string, w io.Writer) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("%s is unknown trace exporter", name)
+	}
+}
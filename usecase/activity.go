@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// recordActivity はuidがエンティティに行った操作をActivityとして記録する．更新で変わったフィールドがない場合は記録しない
+// 記録が欠けないように，操作と同じトランザクションの中で呼び出し，失敗した場合は操作も取り消す
+func recordActivity(ctx context.Context, activity repository.ActivityRepository, uid string, entityType entity.ActivityEntity, id string, action entity.ActivityAction, changes entity.Changes) error {
+	if len(changes) == 0 && (action == entity.ActionUpdate || action == entity.ActionComplete) {
+		return nil
+	}
+	a := &entity.Activity{
+		ActorID:    entity.NewNullString(uid),
+		EntityType: entityType,
+		EntityID:   entity.NewNullString(id),
+		Action:     action,
+		Changes:    changes,
+	}
+	return activity.Create(ctx, a)
+}
+
+// recordTasks はProjectやWorkspaceの削除で変わったtasksをTaskごとにActivityとして記録する
+// changeがnilの場合はTaskの削除として，そうでない場合はchangeを適用した後の状態への更新として記録する
+func recordTasks(ctx context.Context, activity repository.ActivityRepository, uid string, tasks []*entity.Task, change func(task *entity.Task)) error {
+	for _, before := range tasks {
+		action, after := entity.ActionDelete, (*entity.Task)(nil)
+		if change != nil {
+			copied := *before
+			change(&copied)
+			action, after = entity.ActionUpdate, &copied
+		}
+		err := recordActivity(ctx, activity, uid, entity.ActivityTask, before.ID.String(), action, entity.TaskChanges(before, after))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
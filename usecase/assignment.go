@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// AssignmentDispatcher はTaskの担当者になったことをNotifierで担当者に送る
+type AssignmentDispatcher struct {
+	Assignment repository.AssignmentRepository
+	Task       repository.TaskRepository
+	User       repository.UserRepository
+	Notifier   Notifier
+	Clock      Clock
+	BatchSize  int
+	Logger     *slog.Logger
+}
+
+func NewAssignmentDispatcher(assignment repository.AssignmentRepository, task repository.TaskRepository, user repository.UserRepository, notifier Notifier, batchSize int, logger *slog.Logger) *AssignmentDispatcher {
+	return &AssignmentDispatcher{
+		Assignment: assignment,
+		Task:       task,
+		User:       user,
+		Notifier:   notifier,
+		Clock:      SystemClock{},
+		BatchSize:  batchSize,
+		Logger:     logger,
+	}
+}
+
+// Dispatch は通知していない担当者の変更を最大BatchSize件送る
+func (dispatcher *AssignmentDispatcher) Dispatch(ctx context.Context) (sent int, err error) {
+	ctx, span := startSpan(ctx, "AssignmentDispatcher.Dispatch")
+	defer func() { endSpan(span, err) }()
+
+	now := dispatcher.Clock.Now()
+	sent, err = dispatcher.Assignment.Dispatch(ctx, now, dispatcher.BatchSize, dispatcher.send)
+	if err != nil {
+		return
+	}
+	if sent > 0 {
+		dispatcher.Logger.InfoContext(ctx, "assignments sent", slog.Int("count", sent))
+	}
+	return
+}
+
+// send は担当者の変更を通知する
+// 自分を担当者にした場合と，Taskが削除されたか担当者が既に変わっている場合は通知せずに通知済みにする
+func (dispatcher *AssignmentDispatcher) send(ctx context.Context, a *entity.Assignment) error {
+	if a.AssigneeID.Equal(a.AssignerID) {
+		return nil
+	}
+	task, err := dispatcher.Task.Find(ctx, a.TaskID.String())
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !task.AssigneeID.Equal(a.AssigneeID) {
+		return nil
+	}
+	assignee, err := dispatcher.User.FindByID(ctx, a.AssigneeID.String())
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	assigner := "Someone"
+	user, err := dispatcher.User.FindByID(ctx, a.AssignerID.String())
+	if err == nil {
+		assigner = user.Name.String()
+	} else if !errors.Is(err, entity.ErrRecordNotFound) {
+		return err
+	}
+
+	return dispatcher.Notifier.Notify(ctx, &entity.Notification{
+		Kind:    entity.NotificationAssignment,
+		UserID:  assignee.ID.String(),
+		Email:   assignee.Email.String(),
+		TaskID:  task.ID.String(),
+		Subject: "Assigned: " + task.Title.String(),
+		Body:    fmt.Sprintf("%s assigned %q to you.", assigner, task.Title.String()),
+		At:      a.CreatedAt,
+	})
+}
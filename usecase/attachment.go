@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+const (
+	// maxAttachmentNameLength はファイル名の文字数の上限である
+	maxAttachmentNameLength = 255
+	// sniffLength はファイルの種類を判定するために読む先頭のバイト数である(http.DetectContentTypeが見る範囲)
+	sniffLength = 512
+)
+
+// attachmentTypes は添付できるファイルの種類である．ファイル名やリクエストのContent-Typeではなく中身から判定する
+var attachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// AttachmentInteractor はTaskに添付したファイルを扱う
+// 一覧と取得にはTaskの閲覧の権限が，添付にはTaskの編集の権限が必要である
+// 削除は添付したユーザーとTaskの所有者ができる
+type AttachmentInteractor struct {
+	Attachment repository.AttachmentRepository
+	Blob       BlobStore
+	Policy     *Policy
+	MaxSize    int64
+	Quota      int64
+	Logger     *slog.Logger
+}
+
+func NewAttachmentInteractor(attachment repository.AttachmentRepository, blob BlobStore, task repository.TaskRepository, project repository.ProjectRepository, share repository.ShareRepository, membership repository.MembershipRepository, maxSize, quota int64, logger *slog.Logger) *AttachmentInteractor {
+	return &AttachmentInteractor{
+		Attachment: attachment,
+		Blob:       blob,
+		Policy:     NewPolicy(task, project, share, membership),
+		MaxSize:    maxSize,
+		Quota:      quota,
+		Logger:     logger,
+	}
+}
+
+// Upload はUserIDのユーザーがTaskIDのTaskにSizeバイトのファイルrを添付する
+// ContentTypeはファイルの先頭から判定して設定する
+func (interactor *AttachmentInteractor) Upload(ctx context.Context, attachment *entity.Attachment, r io.Reader) (err error) {
+	ctx, span := startSpan(ctx, "AttachmentInteractor.Upload")
+	defer func() { endSpan(span, err) }()
+
+	name, ok := attachmentName(attachment.Name.String())
+	if !attachment.ID.IsNull() || attachment.TaskID.IsNull() || attachment.UserID.IsNull() || !ok || attachment.Size <= 0 {
+		return ErrInvalidAttachment
+	}
+	if attachment.Size > interactor.MaxSize {
+		return ErrAttachmentTooLarge
+	}
+	attachment.Name = entity.NewNullString(name)
+	uid := attachment.UserID.String()
+	_, err = interactor.Policy.AuthorizeTask(ctx, attachment.TaskID.String(), uid, entity.RoleEditor)
+	if err != nil {
+		return
+	}
+	// ファイルを保存する前に容量を確かめる．同時に添付した場合はCreateで弾く
+	usage, err := interactor.Attachment.Usage(ctx, uid)
+	if err != nil {
+		return
+	}
+	if usage+attachment.Size > interactor.Quota {
+		return ErrQuotaExceeded
+	}
+
+	head := make([]byte, min(sniffLength, attachment.Size))
+	n, err := io.ReadFull(r, head)
+	if err != nil {
+		return ErrInvalidAttachment
+	}
+	contentType := http.DetectContentType(head[:n])
+	if mediaType, _, _ := mime.ParseMediaType(contentType); !attachmentTypes[mediaType] {
+		return ErrUnsupportedMediaType
+	}
+	attachment.ContentType = entity.NewNullString(contentType)
+
+	attachment.BlobKey = entity.NewNullString("attachments/" + uuid.New().String())
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head[:n]), r), attachment.Size)
+	err = interactor.Blob.Put(ctx, attachment.BlobKey.String(), body, attachment.Size, contentType)
+	if err != nil {
+		return
+	}
+	err = interactor.Attachment.Create(ctx, attachment, interactor.Quota)
+	if err != nil {
+		// 記録できなかったファイルは残さない
+		if derr := interactor.Blob.Delete(ctx, attachment.BlobKey.String()); derr != nil {
+			interactor.Logger.WarnContext(ctx, "failed to remove blob",
+				slog.String("blob_key", attachment.BlobKey.String()),
+				slog.Any("error", derr),
+			)
+		}
+		if errors.Is(err, entity.ErrQuotaExceeded) {
+			return ErrQuotaExceeded
+		}
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "attachment uploaded",
+		slog.String("attachment_id", attachment.ID.String()),
+		slog.String("task_id", attachment.TaskID.String()),
+		slog.String("user_id", uid),
+		slog.Int64("size", attachment.Size),
+	)
+	return
+}
+
+// List はTaskのAttachmentを添付した順に取得する
+func (interactor *AttachmentInteractor) List(ctx context.Context, tid, uid string) (attachments []*entity.Attachment, err error) {
+	ctx, span := startSpan(ctx, "AttachmentInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	_, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return
+	}
+	attachments, err = interactor.Attachment.FindByTask(ctx, tid)
+	return
+}
+
+// Download はAttachmentとファイルの中身を取得する．呼び出し元は読み終えたらbodyをCloseする
+func (interactor *AttachmentInteractor) Download(ctx context.Context, tid, id, uid string) (attachment *entity.Attachment, body io.ReadCloser, err error) {
+	ctx, span := startSpan(ctx, "AttachmentInteractor.Download")
+	defer func() { endSpan(span, err) }()
+
+	_, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return
+	}
+	attachment, err = interactor.find(ctx, tid, id)
+	if err != nil {
+		return
+	}
+	body, err = interactor.Blob.Get(ctx, attachment.BlobKey.String())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	return
+}
+
+// Delete はAttachmentを削除する．他のユーザーが添付したファイルはTaskの所有者のみが削除できる
+// ファイルの中身はBlobCleanerが後でBlobStoreから削除する
+func (interactor *AttachmentInteractor) Delete(ctx context.Context, tid, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "AttachmentInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	task, err := interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return
+	}
+	attachment, err := interactor.find(ctx, tid, id)
+	if err != nil {
+		return
+	}
+	// 自分のTaskではRoleを省略する
+	if attachment.UserID.String() != uid && task.Role != "" && !task.Role.Can(entity.RoleOwner) {
+		return ErrForbidden
+	}
+
+	err = interactor.Attachment.Delete(ctx, tid, id)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "attachment deleted",
+		slog.String("attachment_id", id),
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// find はTaskのAttachmentを取得する．存在しない場合はErrAttachmentNotFoundを返す
+func (interactor *AttachmentInteractor) find(ctx context.Context, tid, id string) (*entity.Attachment, error) {
+	attachment, err := interactor.Attachment.FindByID(ctx, tid, id)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, err
+}
+
+// attachmentName はクライアントが送ったファイル名からディレクトリを除く
+// 空の場合と制御文字を含む場合，maxAttachmentNameLength文字を超える場合はfalseを返す
+func attachmentName(name string) (string, bool) {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > maxAttachmentNameLength {
+		return "", false
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 || !utf8.ValidString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// BlobCleaner は削除したAttachmentのファイルをBlobStoreから削除する
+type BlobCleaner struct {
+	Attachment repository.AttachmentRepository
+	Blob       BlobStore
+	BatchSize  int
+	Logger     *slog.Logger
+}
+
+func NewBlobCleaner(attachment repository.AttachmentRepository, blob BlobStore, batchSize int, logger *slog.Logger) *BlobCleaner {
+	return &BlobCleaner{Attachment: attachment, Blob: blob, BatchSize: batchSize, Logger: logger}
+}
+
+// Clean は削除したAttachmentのファイルを最大BatchSize件BlobStoreから削除する
+func (cleaner *BlobCleaner) Clean(ctx context.Context) (removed int, err error) {
+	ctx, span := startSpan(ctx, "BlobCleaner.Clean")
+	defer func() { endSpan(span, err) }()
+
+	removed, err = cleaner.Attachment.Purge(ctx, cleaner.BatchSize, cleaner.Blob.Delete)
+	if err != nil {
+		return
+	}
+	if removed > 0 {
+		cleaner.Logger.InfoContext(ctx, "blobs removed", slog.Int("count", removed))
+	}
+	return
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// LockoutPolicy はログインに連続して失敗したアカウントをロックする条件である
+// Threshold回失敗するとBaseの期間ロックし，以降は失敗するたびにロック期間を2倍にする(最大Max)
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+// DefaultLockoutPolicy は5回失敗で1分，以降2分，4分，...と最大1時間までロックする
+var DefaultLockoutPolicy = LockoutPolicy{
+	Threshold: 5,
+	Base:      time.Minute,
+	Max:       time.Hour,
+}
+
+// lockDuration はfailures回目の失敗でロックする期間を返す
+func (p LockoutPolicy) lockDuration(failures int) time.Duration {
+	if failures < p.Threshold {
+		return 0
+	}
+	d := p.Base
+	for i := p.Threshold; i < failures; i++ {
+		d *= 2
+		if d >= p.Max {
+			return p.Max
+		}
+	}
+	return d
+}
+
+// dummyPassword は存在しないアカウントのログインでも同じ時間をかけてパスワードを照合するためのハッシュである
+var dummyPassword = sync.OnceValue(func() *entity.Token {
+	t := entity.NewToken("dummy password")
+	t.Encrypt()
+	return &t
+})
+
+// AuthInteractor はログインを扱う
+type AuthInteractor struct {
+	User         repository.UserRepository
+	LoginAttempt repository.LoginAttemptRepository
+	Policy       LockoutPolicy
+	Logger       *slog.Logger
+	// Now はテストで時刻を固定するために差し替える
+	Now func() time.Time
+}
+
+func NewAuthInteractor(user repository.UserRepository, attempt repository.LoginAttemptRepository, logger *slog.Logger) *AuthInteractor {
+	return &AuthInteractor{
+		User:         user,
+		LoginAttempt: attempt,
+		Policy:       DefaultLockoutPolicy,
+		Logger:       logger,
+		Now:          time.Now,
+	}
+}
+
+// Login はemailとpasswordが一致するUserを返す
+// 連続して失敗したアカウントは一定期間ロックし，その間は*AccountLockedErrorを返す
+func (interactor *AuthInteractor) Login(ctx context.Context, email, password string) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, "AuthInteractor.Login")
+	defer func() { endSpan(span, err) }()
+
+	if email == "" || password == "" {
+		return nil, ErrInvalidUser
+	}
+	now := interactor.Now()
+
+	attempt, err := interactor.LoginAttempt.FindByAccount(ctx, email)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		attempt, err = entity.NewLoginAttempt(email), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attempt.IsLocked(now) {
+		return nil, &AccountLockedError{RetryAfter: attempt.RetryAfter(now)}
+	}
+
+	user, err = interactor.User.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, err
+	}
+	// 存在しないアカウントでもパスワードを照合して失敗を記録し，応答時間や記録からアカウントの有無を推測されないようにする
+	plain := entity.NewToken(password)
+	if err != nil {
+		dummyPassword().Authenticate(&plain)
+		return nil, interactor.fail(ctx, email, now)
+	}
+	if !user.Password.Authenticate(&plain) {
+		return nil, interactor.fail(ctx, email, now)
+	}
+
+	if attempt.Failures > 0 {
+		err = interactor.LoginAttempt.Delete(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+	}
+	interactor.Logger.InfoContext(ctx, "login succeeded", slog.String("user_id", user.ID.String()))
+	return user, nil
+}
+
+// fail はログインの失敗を記録し，ErrLoginFailedかロックされた場合は*AccountLockedErrorを返す
+func (interactor *AuthInteractor) fail(ctx context.Context, email string, now time.Time) error {
+	attempt, err := interactor.LoginAttempt.Fail(ctx, email, now, interactor.Policy.lockDuration)
+	if err != nil {
+		return err
+	}
+	if attempt.IsLocked(now) {
+		interactor.Logger.WarnContext(ctx, "account locked",
+			slog.Int("failures", attempt.Failures),
+			slog.Duration("retry_after", attempt.RetryAfter(now)),
+		)
+		return &AccountLockedError{RetryAfter: attempt.RetryAfter(now)}
+	}
+	interactor.Logger.InfoContext(ctx, "login failed", slog.Int("failures", attempt.Failures))
+	return ErrLoginFailed
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// MaxBatchSize はBatchで一度に実行できる操作の数の上限である
+const MaxBatchSize = 100
+
+// TaskOperationType はBatchで実行するTaskの操作の種類である
+type TaskOperationType string
+
+const (
+	TaskOpCreate   TaskOperationType = "create"
+	TaskOpUpdate   TaskOperationType = "update"
+	TaskOpDelete   TaskOperationType = "delete"
+	TaskOpComplete TaskOperationType = "complete"
+)
+
+// TaskOperation はBatchで実行するTaskの操作である
+// Taskはcreateとupdateで，IsCompletedはcompleteで使う．Preconditionはcreateでは親のTaskのバージョンと比べる
+type TaskOperation struct {
+	Type         TaskOperationType
+	ID           string
+	Task         *entity.Task
+	IsCompleted  bool
+	Precondition entity.Precondition
+}
+
+// TaskOperationResult はTaskOperationの結果である．deleteの場合と失敗した場合はTaskはnil
+type TaskOperationResult struct {
+	Task *entity.Task
+	Err  error
+}
+
+// Batch はuidとしてopsを順に実行し，操作ごとの結果を返す
+// continueOnErrorがfalseの場合はすべての操作をひとつのトランザクションで実行し，ひとつでも失敗するとすべてを取り消す
+// その場合は失敗した操作以外の結果をErrBatchAbortedとする
+// continueOnErrorがtrueの場合は操作ごとに実行し，失敗した操作があっても残りの操作を続ける
+// 各操作の検証と権限の確認はCreate，Update，Delete，Completeと同じである
+func (interactor *TaskInteractor) Batch(ctx context.Context, uid string, ops []*TaskOperation, continueOnError bool) (results []*TaskOperationResult, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Batch")
+	defer func() { endSpan(span, err) }()
+
+	if len(ops) == 0 || len(ops) > MaxBatchSize {
+		return nil, ErrInvalidBatch
+	}
+	results = make([]*TaskOperationResult, len(ops))
+	if continueOnError {
+		failed := 0
+		for i, op := range ops {
+			results[i] = interactor.execute(ctx, uid, op)
+			if results[i].Err != nil {
+				failed++
+			}
+		}
+		interactor.Logger.InfoContext(ctx, "task batch executed",
+			slog.String("user_id", uid),
+			slog.Int("operations", len(ops)),
+			slog.Int("failed", failed),
+		)
+		return results, nil
+	}
+
+	failed := -1
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		for i, op := range ops {
+			results[i] = interactor.execute(ctx, uid, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if failed >= 0 {
+		// 失敗した操作より前の操作は取り消し，後の操作は実行していない
+		for i := range results {
+			if i != failed {
+				results[i] = &TaskOperationResult{Err: ErrBatchAborted}
+			}
+		}
+		interactor.Logger.InfoContext(ctx, "task batch rolled back",
+			slog.String("user_id", uid),
+			slog.Int("operations", len(ops)),
+			slog.Int("failed_index", failed),
+		)
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	interactor.Logger.InfoContext(ctx, "task batch executed",
+		slog.String("user_id", uid),
+		slog.Int("operations", len(ops)),
+		slog.Int("failed", 0),
+	)
+	return results, nil
+}
+
+// execute はBatchのひとつの操作を実行する
+func (interactor *TaskInteractor) execute(ctx context.Context, uid string, op *TaskOperation) *TaskOperationResult {
+	var task *entity.Task
+	var err error
+	switch op.Type {
+	case TaskOpCreate:
+		if op.Task == nil {
+			return &TaskOperationResult{Err: ErrInvalidTask}
+		}
+		task = op.Task
+		task.UserID.Set(uid)
+		err = interactor.Create(ctx, task, op.Precondition)
+	case TaskOpUpdate:
+		if op.Task == nil || op.ID == "" {
+			return &TaskOperationResult{Err: ErrInvalidTask}
+		}
+		task = op.Task
+		task.ID.Set(op.ID)
+		task.UserID.Set(uid)
+		err = interactor.Update(ctx, task, op.Precondition)
+	case TaskOpDelete:
+		if op.ID == "" {
+			return &TaskOperationResult{Err: ErrInvalidTask}
+		}
+		err = interactor.Delete(ctx, op.ID, uid, op.Precondition)
+	case TaskOpComplete:
+		if op.ID == "" {
+			return &TaskOperationResult{Err: ErrInvalidTask}
+		}
+		task, err = interactor.Complete(ctx, op.ID, uid, op.IsCompleted, op.Precondition)
+	default:
+		return &TaskOperationResult{Err: ErrInvalidTask}
+	}
+	if err != nil {
+		return &TaskOperationResult{Err: err}
+	}
+	return &TaskOperationResult{Task: task}
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore は添付ファイルの中身をキーごとに保存する
+// 存在しないキーをGetした場合はfs.ErrNotExistをラップしたエラーを返す
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import "time"
+
+// Clock は現在時刻を取得する
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock はシステムの現在時刻を返すClockである
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// CommentInteractor はTaskのCommentを扱う
+// Commentの閲覧にはTaskの閲覧の権限が，作成と編集にはTaskの編集の権限が必要である
+// Commentを編集できるのは書いたユーザーのみであり，削除は書いたユーザーに加えてTaskの所有者もできる
+type CommentInteractor struct {
+	Comment repository.CommentRepository
+	Policy  *Policy
+	Logger  *slog.Logger
+}
+
+func NewCommentInteractor(comment repository.CommentRepository, task repository.TaskRepository, project repository.ProjectRepository, share repository.ShareRepository, membership repository.MembershipRepository, logger *slog.Logger) *CommentInteractor {
+	return &CommentInteractor{Comment: comment, Policy: NewPolicy(task, project, share, membership), Logger: logger}
+}
+
+// Create はUserIDのユーザーがTaskIDのTaskにCommentを書く
+func (interactor *CommentInteractor) Create(ctx context.Context, comment *entity.Comment) (err error) {
+	ctx, span := startSpan(ctx, "CommentInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	if !comment.ID.IsNull() || comment.TaskID.IsNull() || comment.UserID.IsNull() || !isValidCommentBody(comment.Body) {
+		return ErrInvalidComment
+	}
+	_, err = interactor.Policy.AuthorizeTask(ctx, comment.TaskID.String(), comment.UserID.String(), entity.RoleEditor)
+	if err != nil {
+		return
+	}
+
+	err = interactor.Comment.Create(ctx, comment)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "comment created",
+		slog.String("comment_id", comment.ID.String()),
+		slog.String("task_id", comment.TaskID.String()),
+		slog.String("user_id", comment.UserID.String()),
+	)
+	return interactor.reload(ctx, comment)
+}
+
+// List はTaskのCommentを書いた順にpageの範囲だけ取得する
+func (interactor *CommentInteractor) List(ctx context.Context, tid, uid string, page entity.Page) (comments *entity.CommentPage, err error) {
+	ctx, span := startSpan(ctx, "CommentInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	if !page.IsValid() {
+		return nil, ErrInvalidPage
+	}
+	_, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return
+	}
+	list, total, err := interactor.Comment.FindByTask(ctx, tid, page)
+	if err != nil {
+		return
+	}
+	comments = &entity.CommentPage{Total: total, Limit: page.Limit, Offset: page.Offset, Comments: list}
+	return
+}
+
+// Update はuidのユーザーが書いたCommentの本文を更新する
+func (interactor *CommentInteractor) Update(ctx context.Context, comment *entity.Comment, uid string) (err error) {
+	ctx, span := startSpan(ctx, "CommentInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
+	if comment.ID.IsNull() || comment.TaskID.IsNull() || !isValidCommentBody(comment.Body) {
+		return ErrInvalidComment
+	}
+	_, err = interactor.Policy.AuthorizeTask(ctx, comment.TaskID.String(), uid, entity.RoleEditor)
+	if err != nil {
+		return
+	}
+	current, err := interactor.find(ctx, comment.TaskID.String(), comment.ID.String())
+	if err != nil {
+		return
+	}
+	if current.UserID.String() != uid {
+		return ErrForbidden
+	}
+
+	current.Body = comment.Body
+	err = interactor.Comment.Update(ctx, current)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "comment updated",
+		slog.String("comment_id", current.ID.String()),
+		slog.String("task_id", current.TaskID.String()),
+		slog.String("user_id", uid),
+	)
+	*comment = *current
+	return interactor.reload(ctx, comment)
+}
+
+// Delete はCommentを削除する．他のユーザーのCommentはTaskの所有者のみが削除できる
+func (interactor *CommentInteractor) Delete(ctx context.Context, tid, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "CommentInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	task, err := interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return
+	}
+	comment, err := interactor.find(ctx, tid, id)
+	if err != nil {
+		return
+	}
+	// 自分のTaskではRoleを省略する
+	if comment.UserID.String() != uid && task.Role != "" && !task.Role.Can(entity.RoleOwner) {
+		return ErrForbidden
+	}
+
+	err = interactor.Comment.Delete(ctx, tid, id)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "comment deleted",
+		slog.String("comment_id", id),
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// find はTaskのCommentを取得する．存在しない場合はErrCommentNotFoundを返す
+func (interactor *CommentInteractor) find(ctx context.Context, tid, id string) (*entity.Comment, error) {
+	comment, err := interactor.Comment.FindByID(ctx, tid, id)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// reload は保存したCommentを書いたユーザーと時刻を含めて取得し直す
+func (interactor *CommentInteractor) reload(ctx context.Context, comment *entity.Comment) error {
+	saved, err := interactor.Comment.FindByID(ctx, comment.TaskID.String(), comment.ID.String())
+	if err != nil {
+		return err
+	}
+	*comment = *saved
+	return nil
+}
+
+// isValidCommentBody は本文が空白のみでなく，entity.MaxCommentLength文字以下であるかを返す
+func isValidCommentBody(body entity.NullString) bool {
+	return strings.TrimSpace(body.String()) != "" && utf8.RuneCountInString(body.String()) <= entity.MaxCommentLength
+}
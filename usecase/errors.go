@@ -1,15 +1,136 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
-//Errors of user
+// Errors of user
 var (
 	// ErrInvalidUser invalid user request error
 	ErrInvalidUser = errors.New("invalid user")
 )
 
-//Errors of task
+// Errors of task
 var (
 	// ErrInvalidTask invalid task request error
 	ErrInvalidTask = errors.New("invalid task")
+	// ErrParentNotFound 親に指定したTaskが存在しない
+	ErrParentNotFound = errors.New("parent task not found")
+	// ErrTaskCycle Task自身またはそのサブタスクを親に指定した
+	ErrTaskCycle = errors.New("task cycle")
+	// ErrTaskTooDeep サブタスクの深さがentity.MaxTaskDepthを超える
+	ErrTaskTooDeep = errors.New("task too deep")
+	// ErrInvalidSearchQuery 検索文字列が空または長すぎる
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+	// ErrInvalidAssignee 担当者に指定したユーザーが存在しないかTaskにアクセスできない
+	ErrInvalidAssignee = errors.New("invalid assignee")
+)
+
+// Errors of project
+var (
+	// ErrInvalidProject invalid project request error
+	ErrInvalidProject = errors.New("invalid project")
+	// ErrProjectNotFound Taskに指定したProjectが存在しない
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// Errors of reminder
+var (
+	// ErrInvalidReminder invalid reminder request error
+	ErrInvalidReminder = errors.New("invalid reminder")
+)
+
+// Errors of share
+var (
+	// ErrInvalidShare invalid share request error
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrInviteeNotFound 招待したEmailのユーザーが存在しない
+	ErrInviteeNotFound = errors.New("invitee not found")
+	// ErrForbidden 共有された権限やWorkspaceでの役割では操作できない
+	ErrForbidden = errors.New("forbidden")
 )
+
+// Errors of workspace
+var (
+	// ErrInvalidWorkspace invalid workspace request error
+	ErrInvalidWorkspace = errors.New("invalid workspace")
+	// ErrWorkspaceNotFound Taskに指定したWorkspaceが存在しないか，メンバーでない
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrMemberNotFound 指定したメンバーがWorkspaceに存在しない
+	ErrMemberNotFound = errors.New("member not found")
+)
+
+// Errors of comment
+var (
+	// ErrInvalidComment invalid comment request error
+	ErrInvalidComment = errors.New("invalid comment")
+	// ErrCommentNotFound 指定したCommentがTaskに存在しない
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
+// Errors of attachment
+var (
+	// ErrInvalidAttachment invalid attachment request error
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentNotFound 指定したAttachmentがTaskに存在しない
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge ファイルが大きさの上限を超える
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrUnsupportedMediaType ファイルの中身が添付できる種類ではない
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrQuotaExceeded ファイルを添付するとユーザーの容量の上限を超える
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Errors of version
+var (
+	// ErrPreconditionFailed If-Matchで指定したバージョンが現在のバージョンと異なる
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Errors of batch
+var (
+	// ErrInvalidBatch 操作がないか多すぎる
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrBatchAborted 同じバッチの他の操作が失敗したので取り消したか実行しなかった
+	ErrBatchAborted = errors.New("batch aborted")
+)
+
+// Errors of idempotency
+var (
+	// ErrInvalidIdempotencyKey Idempotency-Keyが空か長すぎる
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyMismatch 同じIdempotency-Keyで異なるリクエストが送られた
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key mismatch")
+	// ErrIdempotencyKeyInUse 同じIdempotency-Keyのリクエストを処理中である
+	ErrIdempotencyKeyInUse = errors.New("idempotency key in use")
+)
+
+// Errors of page
+var (
+	// ErrInvalidPage 一覧の件数や開始位置が範囲外である
+	ErrInvalidPage = errors.New("invalid page")
+)
+
+// Errors of tag
+var (
+	// ErrInvalidTag invalid tag request error
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+//Errors of auth
+var (
+	// ErrLoginFailed email or password is wrong error
+	ErrLoginFailed = errors.New("login failed")
+)
+
+// AccountLockedError はログインに連続して失敗したアカウントがロックされていることを示す
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked: retry after %s", e.RetryAfter)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// IdempotencyInteractor はIdempotency-Keyを指定したリクエストを一度だけ処理する
+// 処理したリクエストのレスポンスはTTLの間保存し，同じキーで再送されたリクエストにはそれを返す
+// 処理中の記録はLeaseの間だけ確保し，過ぎた場合はサーバーが停止したものとして同じキーのリクエストが引き継ぐ
+type IdempotencyInteractor struct {
+	Idempotency repository.IdempotencyRepository
+	TTL         time.Duration
+	Lease       time.Duration
+	Clock       Clock
+	Logger      *slog.Logger
+}
+
+func NewIdempotencyInteractor(idempotency repository.IdempotencyRepository, ttl, lease time.Duration, logger *slog.Logger) *IdempotencyInteractor {
+	return &IdempotencyInteractor{Idempotency: idempotency, TTL: ttl, Lease: lease, Clock: SystemClock{}, Logger: logger}
+}
+
+// Begin はuidがkeyを指定したリクエストの処理を始める
+// 初めてのキーの場合は処理中として保存した記録を，処理済みの同じリクエストの場合は保存したレスポンスをreplay=trueで返す
+func (interactor *IdempotencyInteractor) Begin(ctx context.Context, uid, key, fingerprint string) (record *entity.IdempotencyKey, replay bool, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyInteractor.Begin")
+	defer func() { endSpan(span, err) }()
+
+	if key == "" || len(key) > entity.MaxIdempotencyKeyLength {
+		return nil, false, ErrInvalidIdempotencyKey
+	}
+	now := interactor.Clock.Now()
+	// LockedUntilは記録を確保したリクエストの判定に使うのでdatabaseに保存できる精度に揃える
+	lockedUntil := now.Add(interactor.Lease).Truncate(time.Microsecond)
+	record = entity.NewIdempotencyKey(uid, key, fingerprint, lockedUntil, now.Add(interactor.TTL))
+	existing, err := interactor.Idempotency.Reserve(ctx, record, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		return record, false, nil
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, false, ErrIdempotencyKeyMismatch
+	}
+	if !existing.IsCompleted() {
+		return nil, false, ErrIdempotencyKeyInUse
+	}
+	interactor.Logger.InfoContext(ctx, "idempotent request replayed", slog.String("user_id", uid), slog.Int("status", existing.StatusCode))
+	return existing, true, nil
+}
+
+// Complete はBeginで保存した記録にレスポンスを保存する
+func (interactor *IdempotencyInteractor) Complete(ctx context.Context, record *entity.IdempotencyKey, status int, contentType, etag string, body []byte) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyInteractor.Complete")
+	defer func() { endSpan(span, err) }()
+
+	record.StatusCode = status
+	record.ContentType = contentType
+	record.ETag = etag
+	record.Body = body
+	return interactor.Idempotency.Complete(ctx, record)
+}
+
+// Release はレスポンスを保存せずにBeginで保存した記録を削除し，同じキーで再び処理できるようにする
+func (interactor *IdempotencyInteractor) Release(ctx context.Context, record *entity.IdempotencyKey) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyInteractor.Release")
+	defer func() { endSpan(span, err) }()
+
+	return interactor.Idempotency.Release(ctx, record)
+}
+
+// Clean は期限の切れた記録を削除する
+func (interactor *IdempotencyInteractor) Clean(ctx context.Context) (deleted int64, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyInteractor.Clean")
+	defer func() { endSpan(span, err) }()
+
+	deleted, err = interactor.Idempotency.DeleteExpired(ctx, interactor.Clock.Now())
+	if err != nil {
+		return
+	}
+	if deleted > 0 {
+		interactor.Logger.InfoContext(ctx, "idempotency keys deleted", slog.Int64("count", deleted))
+	}
+	return
+}
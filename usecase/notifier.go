@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// Notifier はユーザーに通知を送る
+type Notifier interface {
+	Notify(ctx context.Context, n *entity.Notification) error
+}
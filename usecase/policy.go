@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// Policy はユーザーがTaskとProjectにアクセスできるかを，所有者であるかと承諾された共有，Workspaceでの役割から判定する
+// 共有されたTaskのサブタスクと共有されたProjectのTaskにも共有した権限でアクセスできる
+// WorkspaceのTaskにはRBACがメンバーの役割から決める権限でアクセスできる
+type Policy struct {
+	Task    repository.TaskRepository
+	Project repository.ProjectRepository
+	Share   repository.ShareRepository
+	RBAC    *RBAC
+}
+
+func NewPolicy(task repository.TaskRepository, project repository.ProjectRepository, share repository.ShareRepository, membership repository.MembershipRepository) *Policy {
+	return &Policy{Task: task, Project: project, Share: share, RBAC: NewRBAC(membership)}
+}
+
+// AuthorizeTask はuidがtidのTaskをneedの権限で操作できる場合にTaskを返す
+// 共有されたTaskのRoleには共有された権限を設定する
+// アクセスできない場合はentity.ErrRecordNotFoundを，アクセスできるが権限が足りない場合はErrForbiddenを返す
+func (p *Policy) AuthorizeTask(ctx context.Context, tid, uid string, need entity.Role) (*entity.Task, error) {
+	task, err := p.Task.FindByID(ctx, tid, uid)
+	if err == nil {
+		return task, nil
+	}
+	if !errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	grants, err := p.Grants(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if grants.IsEmpty() {
+		return nil, entity.ErrRecordNotFound
+	}
+	task, err = p.Task.Find(ctx, tid)
+	if err != nil {
+		return nil, err
+	}
+	role, err := p.inherited(ctx, grants, task)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, entity.ErrRecordNotFound
+	}
+	if !role.Can(need) {
+		return nil, ErrForbidden
+	}
+	task.Role = role
+	return task, nil
+}
+
+// CanAccessTask はuidがtaskにneedの権限でアクセスできるかを返す．taskはまだ作成していないTaskでもよい
+func (p *Policy) CanAccessTask(ctx context.Context, task *entity.Task, uid string, need entity.Role) (bool, error) {
+	if task.UserID.String() == uid {
+		return true, nil
+	}
+	grants, err := p.Grants(ctx, uid)
+	if err != nil {
+		return false, err
+	}
+	role, err := p.inherited(ctx, grants, task)
+	if err != nil {
+		return false, err
+	}
+	return role != "" && role.Can(need), nil
+}
+
+// inherited はgrantsでtaskに与えられた権限を返す．サブタスクは親のTaskの共有とWorkspaceを引き継ぐ
+func (p *Policy) inherited(ctx context.Context, grants *Grants, task *entity.Task) (entity.Role, error) {
+	role := grants.Role(task)
+	var err error
+	for ancestor, depth := task, 1; role == "" && !ancestor.ParentID.IsNull() && depth < entity.MaxTaskDepth; depth++ {
+		ancestor, err = p.Task.Find(ctx, ancestor.ParentID.String())
+		if err != nil {
+			return "", err
+		}
+		role = grants.Role(ancestor)
+	}
+	return role, nil
+}
+
+// AuthorizeProject はuidがidのProjectをneedの権限で操作できる場合にProjectを返す
+// エラーはAuthorizeTaskと同じである
+func (p *Policy) AuthorizeProject(ctx context.Context, id, uid string, need entity.Role) (*entity.Project, error) {
+	project, err := p.Project.FindByID(ctx, id, uid)
+	if err == nil {
+		return project, nil
+	}
+	if !errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	grants, err := p.Grants(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	role, ok := grants.Projects[id]
+	if !ok {
+		return nil, entity.ErrRecordNotFound
+	}
+	if !role.Can(need) {
+		return nil, ErrForbidden
+	}
+	project, err = p.Project.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	project.Role = role
+	return project, nil
+}
+
+// Grants はuidに共有されて承諾したTaskとProjectの権限と，uidが参加しているWorkspaceのTaskの権限を返す
+func (p *Policy) Grants(ctx context.Context, uid string) (*Grants, error) {
+	shares, err := p.Share.FindByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	workspaces, err := p.RBAC.TaskRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	grants := &Grants{Tasks: map[string]entity.Role{}, Projects: map[string]entity.Role{}, Workspaces: workspaces}
+	for _, s := range shares {
+		if s.UserID.String() != uid || !s.IsAccepted() {
+			continue
+		}
+		switch s.ResourceType {
+		case entity.ShareTask:
+			grants.Tasks[s.ResourceID.String()] = s.Role
+		case entity.ShareProject:
+			grants.Projects[s.ResourceID.String()] = s.Role
+		}
+	}
+	return grants, nil
+}
+
+// Grants はユーザーに共有されたTaskとProjectのIDごとの権限と，参加しているWorkspaceのIDごとのTaskの権限である
+type Grants struct {
+	Tasks      map[string]entity.Role
+	Projects   map[string]entity.Role
+	Workspaces map[string]entity.Role
+}
+
+// IsEmpty は何も共有されておらず，Workspaceにも参加していないかを返す
+func (g *Grants) IsEmpty() bool {
+	return len(g.Tasks) == 0 && len(g.Projects) == 0 && len(g.Workspaces) == 0
+}
+
+// Role はtaskに直接，Projectを通して，またはWorkspaceを通して与えられた権限のうち最も強いものを返す
+// 権限がない場合は""を返す．親のTaskの権限は考慮しない
+func (g *Grants) Role(task *entity.Task) entity.Role {
+	role := g.Tasks[task.ID.String()]
+	if r, ok := g.Projects[task.ProjectID.String()]; ok && !task.ProjectID.IsNull() && !role.Can(r) {
+		role = r
+	}
+	if r, ok := g.Workspaces[task.WorkspaceID.String()]; ok && !task.WorkspaceID.IsNull() && !role.Can(r) {
+		role = r
+	}
+	return role
+}
+
+// TaskIDs は共有されたTaskのIDを昇順に返す．共有されたTaskがない場合はnilを返す
+func (g *Grants) TaskIDs() []string {
+	return sortedKeys(g.Tasks)
+}
+
+// ProjectIDs は共有されたProjectのIDを昇順に返す．共有されたProjectがない場合はnilを返す
+func (g *Grants) ProjectIDs() []string {
+	return sortedKeys(g.Projects)
+}
+
+// WorkspaceIDs は参加しているWorkspaceのIDを昇順に返す．参加していない場合はnilを返す
+func (g *Grants) WorkspaceIDs() []string {
+	return sortedKeys(g.Workspaces)
+}
+
+func sortedKeys(m map[string]entity.Role) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// ProjectInteractor はProjectを扱う
+// PolicyはProjectにアクセスできるかの判定に使う
+type ProjectInteractor struct {
+	Project    repository.ProjectRepository
+	Activity   repository.ActivityRepository
+	Transactor repository.Transactor
+	Policy     *Policy
+	Logger     *slog.Logger
+}
+
+func NewProjectInteractor(project repository.ProjectRepository, task repository.TaskRepository, share repository.ShareRepository, membership repository.MembershipRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *ProjectInteractor {
+	return &ProjectInteractor{Project: project, Activity: activity, Transactor: transactor, Policy: NewPolicy(task, project, share, membership), Logger: logger}
+}
+
+func (interactor *ProjectInteractor) Create(ctx context.Context, project *entity.Project) (err error) {
+	ctx, span := startSpan(ctx, "ProjectInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if project.Name.IsNull() || project.UserID.IsNull() || !project.ID.IsNull() {
+		return ErrInvalidProject
+	}
+
+	err = interactor.Project.Create(ctx, project)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "project created",
+		slog.String("project_id", project.ID.String()),
+		slog.String("user_id", project.UserID.String()),
+	)
+	return
+}
+
+func (interactor *ProjectInteractor) GetByID(ctx context.Context, id, uid string) (project *entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectInteractor.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	project, err = interactor.Policy.AuthorizeProject(ctx, id, uid, entity.RoleViewer)
+	return
+}
+
+func (interactor *ProjectInteractor) List(ctx context.Context, uid string) (projects []*entity.Project, err error) {
+	ctx, span := startSpan(ctx, "ProjectInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	projects, err = interactor.Project.FindByUser(ctx, uid)
+	if err != nil {
+		return
+	}
+
+	// 共有されたProjectは自分のProjectの後に並べる
+	grants, err := interactor.Policy.Grants(ctx, uid)
+	if err != nil || len(grants.Projects) == 0 {
+		return
+	}
+	shared, err := interactor.Project.FindByIDs(ctx, grants.ProjectIDs())
+	if err != nil {
+		return
+	}
+	for _, p := range shared {
+		p.Role = grants.Projects[p.ID.String()]
+	}
+	projects = append(projects, shared...)
+	return
+}
+
+func (interactor *ProjectInteractor) Update(ctx context.Context, project *entity.Project) (err error) {
+	ctx, span := startSpan(ctx, "ProjectInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if project.Name.IsNull() || project.UserID.IsNull() || project.ID.IsNull() {
+		return ErrInvalidProject
+	}
+
+	err = interactor.Project.Update(ctx, project)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "project updated",
+		slog.String("project_id", project.ID.String()),
+		slog.String("user_id", project.UserID.String()),
+	)
+	return
+}
+
+// Delete はProjectを削除する．deleteTasksがfalseの場合はProjectのTaskをインボックスに移動する
+// 削除や移動したTaskはTaskごとにActivityに記録する
+func (interactor *ProjectInteractor) Delete(ctx context.Context, id, uid string, deleteTasks bool) (err error) {
+	ctx, span := startSpan(ctx, "ProjectInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		tasks, err := interactor.Project.Delete(ctx, id, uid, deleteTasks)
+		if err != nil {
+			return err
+		}
+		if deleteTasks {
+			return recordTasks(ctx, interactor.Activity, uid, tasks, nil)
+		}
+		return recordTasks(ctx, interactor.Activity, uid, tasks, func(task *entity.Task) { task.ProjectID = entity.NewNullString("") })
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "project deleted",
+		slog.String("project_id", id),
+		slog.String("user_id", uid),
+		slog.Bool("delete_tasks", deleteTasks),
+	)
+	return
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// RBAC はWorkspaceでのメンバーの役割から，WorkspaceとそのTaskに対する操作を許可するかを判定する
+type RBAC struct {
+	Membership repository.MembershipRepository
+}
+
+func NewRBAC(membership repository.MembershipRepository) *RBAC {
+	return &RBAC{Membership: membership}
+}
+
+// Authorize はuidがwidのWorkspaceでneed以上の役割を持つ場合にMembershipを返す
+// メンバーでない場合はentity.ErrRecordNotFoundを，役割が足りない場合はErrForbiddenを返す
+func (r *RBAC) Authorize(ctx context.Context, wid, uid string, need entity.WorkspaceRole) (*entity.Membership, error) {
+	membership, err := r.Membership.Find(ctx, wid, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.Role.Can(need) {
+		return nil, ErrForbidden
+	}
+	return membership, nil
+}
+
+// TaskRoles はuidが参加しているWorkspaceのIDごとに，WorkspaceのTaskに対する権限を返す
+func (r *RBAC) TaskRoles(ctx context.Context, uid string) (map[string]entity.Role, error) {
+	memberships, err := r.Membership.FindByUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]entity.Role{}
+	for _, m := range memberships {
+		if role := m.Role.TaskRole(); role != "" {
+			roles[m.WorkspaceID.String()] = role
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// maxReminderBefore は期限の何前に通知するかの上限である
+const maxReminderBefore = 30 * 24 * time.Hour
+
+// ReminderInteractor はTaskのReminderを操作する
+type ReminderInteractor struct {
+	Reminder repository.ReminderRepository
+	Task     repository.TaskRepository
+	User     repository.UserRepository
+	Logger   *slog.Logger
+}
+
+func NewReminderInteractor(reminder repository.ReminderRepository, task repository.TaskRepository, user repository.UserRepository, logger *slog.Logger) *ReminderInteractor {
+	return &ReminderInteractor{Reminder: reminder, Task: task, User: user, Logger: logger}
+}
+
+// Create はTaskにReminderを付ける．RemindAtとBeforeのどちらか一方のみを指定する
+func (interactor *ReminderInteractor) Create(ctx context.Context, reminder *entity.Reminder) (err error) {
+	ctx, span := startSpan(ctx, "ReminderInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	if !reminder.ID.IsNull() || reminder.TaskID.IsNull() || reminder.UserID.IsNull() {
+		return ErrInvalidReminder
+	}
+	if reminder.Before.IsNull() == reminder.RemindAt.IsZero() {
+		return ErrInvalidReminder
+	}
+	if !reminder.Before.IsNull() {
+		d, ok := reminder.Offset()
+		if !ok || d < 0 || d > maxReminderBefore {
+			return ErrInvalidReminder
+		}
+		reminder.Before = entity.NewNullString(d.String())
+	}
+
+	task, err := interactor.Task.FindByID(ctx, reminder.TaskID.String(), reminder.UserID.String())
+	if err != nil {
+		return
+	}
+	loc, err := userLocation(ctx, interactor.User, reminder.UserID.String())
+	if err != nil {
+		return
+	}
+	reminder.Schedule(task, loc)
+	reminder.RemindAt = reminder.RemindAt.UTC()
+
+	err = interactor.Reminder.Create(ctx, reminder)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "reminder created",
+		slog.String("reminder_id", reminder.ID.String()),
+		slog.String("task_id", reminder.TaskID.String()),
+		slog.String("user_id", reminder.UserID.String()),
+	)
+	return
+}
+
+// List はTaskのReminderの一覧を取得する
+func (interactor *ReminderInteractor) List(ctx context.Context, tid, uid string) (reminders []*entity.Reminder, err error) {
+	ctx, span := startSpan(ctx, "ReminderInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	_, err = interactor.Task.FindByID(ctx, tid, uid)
+	if err != nil {
+		return
+	}
+	reminders, err = interactor.Reminder.FindByTask(ctx, tid, uid)
+	return
+}
+
+func (interactor *ReminderInteractor) Delete(ctx context.Context, id, tid, uid string) (err error) {
+	ctx, span := startSpan(ctx, "ReminderInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Reminder.Delete(ctx, id, tid, uid)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "reminder deleted",
+		slog.String("reminder_id", id),
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// ReminderDispatcher は通知する時刻になったReminderをNotifierで送る
+type ReminderDispatcher struct {
+	Reminder  repository.ReminderRepository
+	Task      repository.TaskRepository
+	User      repository.UserRepository
+	Notifier  Notifier
+	Clock     Clock
+	BatchSize int
+	Logger    *slog.Logger
+}
+
+func NewReminderDispatcher(reminder repository.ReminderRepository, task repository.TaskRepository, user repository.UserRepository, notifier Notifier, batchSize int, logger *slog.Logger) *ReminderDispatcher {
+	return &ReminderDispatcher{
+		Reminder:  reminder,
+		Task:      task,
+		User:      user,
+		Notifier:  notifier,
+		Clock:     SystemClock{},
+		BatchSize: batchSize,
+		Logger:    logger,
+	}
+}
+
+// Dispatch は現在時刻までに通知するReminderを最大BatchSize件送る
+func (dispatcher *ReminderDispatcher) Dispatch(ctx context.Context) (sent int, err error) {
+	ctx, span := startSpan(ctx, "ReminderDispatcher.Dispatch")
+	defer func() { endSpan(span, err) }()
+
+	now := dispatcher.Clock.Now()
+	sent, err = dispatcher.Reminder.Dispatch(ctx, now, dispatcher.BatchSize, dispatcher.send)
+	if err != nil {
+		return
+	}
+	if sent > 0 {
+		dispatcher.Logger.InfoContext(ctx, "reminders sent", slog.Int("count", sent))
+	}
+	return
+}
+
+// send はReminderを通知する．Taskが削除されているか完了している場合は通知せずに送信済みにする
+func (dispatcher *ReminderDispatcher) send(ctx context.Context, r *entity.Reminder) error {
+	task, err := dispatcher.Task.FindByID(ctx, r.TaskID.String(), r.UserID.String())
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if task.IsCompleted {
+		return nil
+	}
+	user, err := dispatcher.User.FindByID(ctx, r.UserID.String())
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	loc, err := user.Location()
+	if err != nil {
+		loc = time.UTC
+	}
+
+	return dispatcher.Notifier.Notify(ctx, &entity.Notification{
+		Kind:    entity.NotificationReminder,
+		UserID:  user.ID.String(),
+		Email:   user.Email.String(),
+		TaskID:  task.ID.String(),
+		Subject: "Reminder: " + task.Title.String(),
+		Body:    fmt.Sprintf("%q is due at %s.", task.Title.String(), task.DueAt(loc).Format("2006-01-02 15:04 MST")),
+		At:      r.RemindAt,
+	})
+}
+
+// userLocation はユーザーのタイムゾーンを返す．ユーザーが存在しないかタイムゾーンが読み込めない場合はUTCとする
+func userLocation(ctx context.Context, users repository.UserRepository, uid string) (*time.Location, error) {
+	user, err := users.FindByID(ctx, uid)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loc, err := user.Location()
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
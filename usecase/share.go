@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// ShareInteractor はTaskとProjectを他のユーザーに共有する招待を扱う
+type ShareInteractor struct {
+	Share  repository.ShareRepository
+	User   repository.UserRepository
+	Policy *Policy
+	Clock  Clock
+	Logger *slog.Logger
+}
+
+func NewShareInteractor(share repository.ShareRepository, task repository.TaskRepository, project repository.ProjectRepository, user repository.UserRepository, membership repository.MembershipRepository, logger *slog.Logger) *ShareInteractor {
+	return &ShareInteractor{Share: share, User: user, Policy: NewPolicy(task, project, share, membership), Clock: SystemClock{}, Logger: logger}
+}
+
+// Invite はOwnerIDのユーザーが所有するTaskまたはProjectをemailのユーザーに共有する招待を作成する
+// 共有できるのは親のないTaskのみであり，サブタスクは親のTaskの共有を引き継ぐ
+func (interactor *ShareInteractor) Invite(ctx context.Context, share *entity.Share, email string) (err error) {
+	ctx, span := startSpan(ctx, "ShareInteractor.Invite")
+	defer func() { endSpan(span, err) }()
+
+	if !share.ID.IsNull() || share.OwnerID.IsNull() || share.ResourceID.IsNull() || !share.Role.IsShareable() || email == "" {
+		return ErrInvalidShare
+	}
+	owner := share.OwnerID.String()
+	switch share.ResourceType {
+	case entity.ShareTask:
+		var task *entity.Task
+		task, err = interactor.Policy.AuthorizeTask(ctx, share.ResourceID.String(), owner, entity.RoleOwner)
+		if err != nil {
+			return
+		}
+		if !task.ParentID.IsNull() {
+			return ErrInvalidShare
+		}
+		// WorkspaceのadminはTaskを削除できるが，共有できるのは作成したユーザーのみである
+		if !task.UserID.Equal(share.OwnerID) {
+			return ErrForbidden
+		}
+	case entity.ShareProject:
+		_, err = interactor.Policy.AuthorizeProject(ctx, share.ResourceID.String(), owner, entity.RoleOwner)
+		if err != nil {
+			return
+		}
+	default:
+		return ErrInvalidShare
+	}
+
+	invitee, err := interactor.User.FindByEmail(ctx, email)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return ErrInviteeNotFound
+	}
+	if err != nil {
+		return
+	}
+	if invitee.ID.String() == owner {
+		return ErrInvalidShare
+	}
+	share.UserID = invitee.ID
+
+	err = interactor.Share.Create(ctx, share)
+	if err != nil {
+		return
+	}
+	err = interactor.fillEmails(ctx, share)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "share invited",
+		slog.String("share_id", share.ID.String()),
+		slog.String("resource_type", string(share.ResourceType)),
+		slog.String("resource_id", share.ResourceID.String()),
+		slog.String("user_id", owner),
+	)
+	return
+}
+
+// List はuidが所有者または共有されたユーザーである招待の一覧を取得する
+func (interactor *ShareInteractor) List(ctx context.Context, uid string) (shares []*entity.Share, err error) {
+	ctx, span := startSpan(ctx, "ShareInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	shares, err = interactor.Share.FindByUser(ctx, uid)
+	if err != nil {
+		return
+	}
+	err = interactor.fillEmails(ctx, shares...)
+	return
+}
+
+// Accept はuidに共有する招待を承諾する
+func (interactor *ShareInteractor) Accept(ctx context.Context, id, uid string) (share *entity.Share, err error) {
+	ctx, span := startSpan(ctx, "ShareInteractor.Accept")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Share.Accept(ctx, id, uid, interactor.Clock.Now())
+	if err != nil {
+		return
+	}
+	share, err = interactor.Share.FindByID(ctx, id)
+	if err != nil {
+		return
+	}
+	err = interactor.fillEmails(ctx, share)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "share accepted",
+		slog.String("share_id", id),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// Delete は招待を取り消す．所有者は共有をやめ，共有されたユーザーは招待を断るか共有から抜ける
+func (interactor *ShareInteractor) Delete(ctx context.Context, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "ShareInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Share.Delete(ctx, id, uid)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "share deleted",
+		slog.String("share_id", id),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// fillEmails はsharesのEmailとOwnerに共有されたユーザーと所有者のEmailを設定する
+// ユーザーのIDは外部に公開しないので，ユーザーはEmailで表す
+func (interactor *ShareInteractor) fillEmails(ctx context.Context, shares ...*entity.Share) error {
+	emails := map[string]string{}
+	email := func(uid string) (string, error) {
+		if e, ok := emails[uid]; ok {
+			return e, nil
+		}
+		user, err := interactor.User.FindByID(ctx, uid)
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		emails[uid] = user.Email.String()
+		return emails[uid], nil
+	}
+	for _, s := range shares {
+		var err error
+		s.Email, err = email(s.UserID.String())
+		if err != nil {
+			return err
+		}
+		s.Owner, err = email(s.OwnerID.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// TagInteractor はTagとTaskへの付け外しを扱う
+type TagInteractor struct {
+	Tag    repository.TagRepository
+	Logger *slog.Logger
+}
+
+func NewTagInteractor(tag repository.TagRepository, logger *slog.Logger) *TagInteractor {
+	return &TagInteractor{Tag: tag, Logger: logger}
+}
+
+func (interactor *TagInteractor) Create(ctx context.Context, tag *entity.Tag) (err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if tag.Name.IsNull() || tag.UserID.IsNull() || !tag.ID.IsNull() {
+		return ErrInvalidTag
+	}
+
+	err = interactor.Tag.Create(ctx, tag)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "tag created",
+		slog.String("tag_id", tag.ID.String()),
+		slog.String("user_id", tag.UserID.String()),
+	)
+	return
+}
+
+func (interactor *TagInteractor) GetByID(ctx context.Context, id, uid string) (tag *entity.Tag, err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	tag, err = interactor.Tag.FindByID(ctx, id, uid)
+	return
+}
+
+func (interactor *TagInteractor) List(ctx context.Context, uid string) (tags []*entity.Tag, err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	tags, err = interactor.Tag.FindByUser(ctx, uid)
+	return
+}
+
+func (interactor *TagInteractor) Update(ctx context.Context, tag *entity.Tag) (err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if tag.Name.IsNull() || tag.UserID.IsNull() || tag.ID.IsNull() {
+		return ErrInvalidTag
+	}
+
+	err = interactor.Tag.Update(ctx, tag)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "tag updated",
+		slog.String("tag_id", tag.ID.String()),
+		slog.String("user_id", tag.UserID.String()),
+	)
+	return
+}
+
+func (interactor *TagInteractor) Delete(ctx context.Context, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Tag.Delete(ctx, id, uid)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "tag deleted",
+		slog.String("tag_id", id),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// Attach はTaskにTagを付ける．TaskとTagはどちらもuidのものでなければならない
+func (interactor *TagInteractor) Attach(ctx context.Context, tid, tagID, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.Attach")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Tag.Attach(ctx, tid, tagID, uid)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "tag attached",
+		slog.String("task_id", tid),
+		slog.String("tag_id", tagID),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// Detach はTaskからTagを外す
+func (interactor *TagInteractor) Detach(ctx context.Context, tid, tagID, uid string) (err error) {
+	ctx, span := startSpan(ctx, "TagInteractor.Detach")
+	defer func() { endSpan(span, err) }()
+
+	err = interactor.Tag.Detach(ctx, tid, tagID, uid)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "tag detached",
+		slog.String("task_id", tid),
+		slog.String("tag_id", tagID),
+		slog.String("user_id", uid),
+	)
+	return
+}
@@ -1,43 +1,953 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+	"unicode/utf8"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 	"github.com/hiroyaonoe/todoapp-server/domain/repository"
 )
 
 // TaskInteractor は複数のエンティティを操作する際に活用できる
+// Clockは期限を過ぎたかの判定に，PolicyはTaskにアクセスできるかの判定に使う
+// 共有されたTaskやWorkspaceのTaskの操作はTaskの所有者のTaskとして行う
+// Taskの作成，更新，削除，完了は同じトランザクションでActivityに記録する
+// Transactorは更新や完了に伴う複数の変更と，Batchの複数の操作をひとつのトランザクションで実行するのに使う
 type TaskInteractor struct {
-	Task repository.TaskRepository
+	Task       repository.TaskRepository
+	Project    repository.ProjectRepository
+	User       repository.UserRepository
+	Reminder   repository.ReminderRepository
+	Assignment repository.AssignmentRepository
+	Activity   repository.ActivityRepository
+	Transactor repository.Transactor
+	Policy     *Policy
+	Clock      Clock
+	Logger     *slog.Logger
 }
 
-func NewTaskInteractor(task repository.TaskRepository) *TaskInteractor {
-	return &TaskInteractor{Task: task}
+func NewTaskInteractor(task repository.TaskRepository, project repository.ProjectRepository, user repository.UserRepository, reminder repository.ReminderRepository, share repository.ShareRepository, membership repository.MembershipRepository, assignment repository.AssignmentRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *TaskInteractor {
+	return &TaskInteractor{Task: task, Project: project, User: user, Reminder: reminder, Assignment: assignment, Activity: activity, Transactor: transactor, Policy: NewPolicy(task, project, share, membership), Clock: SystemClock{}, Logger: logger}
 }
 
-func (interactor *TaskInteractor) Create(task *entity.Task) (err error) {
+// Create はcondが指定された場合，親のTaskのバージョンが一致しなければErrPreconditionFailedを返す．親のないTaskでは条件を使わない
+func (interactor *TaskInteractor) Create(ctx context.Context, task *entity.Task, cond entity.Precondition) (err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別(TaskIDがnilでない場合)
+	if !task.ID.IsNull() || task.UserID.IsNull() {
+		return ErrInvalidTask
+	}
+	uid := task.UserID.String()
+	// サブタスクの日付とProjectは省略した場合に親から引き継ぎ，Workspaceは常に親から引き継ぐ
+	// 共有されたTaskのサブタスクや共有されたProjectのTaskは，親のTaskやProjectの所有者のTaskとして作成する
+	var parent *entity.Task
+	var version int
+	if !task.ParentID.IsNull() {
+		parent, err = interactor.authorizeParent(ctx, task, uid)
+		if err != nil {
+			return
+		}
+		version, err = checkVersion(cond, parent.Version)
+		if err != nil {
+			return
+		}
+		task.UserID = parent.UserID
+		err = interactor.checkParent(ctx, task, parent)
+		if err != nil {
+			return
+		}
+		if task.Deadline.IsNull() {
+			task.Deadline = parent.Deadline
+		}
+		if task.ProjectID.IsNull() {
+			task.ProjectID = parent.ProjectID
+		}
+		task.WorkspaceID = parent.WorkspaceID
+	} else if !task.ProjectID.IsNull() {
+		var project *entity.Project
+		project, err = interactor.authorizeProject(ctx, task, uid)
+		if err != nil {
+			return
+		}
+		task.UserID = project.UserID
+	}
 	// databaseのnot null制約と重複
 	// 不正なユーザーリクエストの判別(フィールドのうち少なくともひとつがnilの場合)
-	if task.Title.IsNull() || task.UserID.IsNull() || task.Deadline.IsNull() {
+	if task.Title.IsNull() || task.Deadline.IsNull() {
 		return ErrInvalidTask
 	}
-	// 不正なユーザーリクエストの判別(TaskIDがnilでない場合)
-	if !task.ID.IsNull() {
+	if !task.Priority.IsValid() || !normalizeRecurrence(task) || !validDueTime(task) {
 		return ErrInvalidTask
 	}
+	// Positionはrepositoryで同じ日の末尾に設定する
+	task.Position = ""
+	// TagはTagInteractor.Attachで付ける
+	task.Tags = nil
+	if !task.ParentID.IsNull() {
+		err = interactor.checkProject(ctx, task, uid)
+		if err != nil {
+			return
+		}
+	} else {
+		err = interactor.checkWorkspace(ctx, task, uid)
+		if err != nil {
+			return
+		}
+	}
 
-	// 新規Taskを作成
-	err = interactor.Task.Create(task)
-
+	// 新規Taskを作成し，同じトランザクションで記録する
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		if parent != nil {
+			err := interactor.lockVersion(ctx, parent, version)
+			if err != nil {
+				return err
+			}
+		}
+		err := interactor.Task.Create(ctx, task)
+		if err != nil {
+			return err
+		}
+		err = interactor.markOverdue(ctx, uid, task)
+		if err != nil {
+			return err
+		}
+		return interactor.record(ctx, uid, entity.ActionCreate, nil, task)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "task created",
+		slog.String("task_id", task.ID.String()),
+		slog.String("user_id", task.UserID.String()),
+	)
 	return
 }
 
-func (interactor *TaskInteractor) GetByID(tid, uid string) (task *entity.Task, err error) {
+func (interactor *TaskInteractor) GetByID(ctx context.Context, tid, uid string) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.GetByID")
+	defer func() { endSpan(span, err) }()
+
 	// Task の取得
-	task, err = interactor.Task.FindByID(tid, uid)
+	task, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return
+	}
+	err = interactor.markOverdue(ctx, uid, task)
+	return
+}
+
+// List はuidのTaskの一覧をqの条件で取得する
+func (interactor *TaskInteractor) List(ctx context.Context, uid string, q repository.TaskQuery) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	// 期間を指定する場合は繰り返しのTaskの回を展開する
+	ranged := !q.From.IsNull() || !q.To.IsNull()
+	if ranged {
+		if q.From.IsNull() || q.To.IsNull() || !q.Date.IsNull() ||
+			q.To.Time.Before(q.From.Time) || q.To.Time.After(q.From.Time.AddDate(0, 0, maxListDays)) {
+			return nil, ErrInvalidTask
+		}
+	}
+
+	tasks, err = interactor.findShared(ctx, uid, q)
+	if err != nil {
+		return
+	}
+	if ranged {
+		tasks = expandOccurrences(tasks, q)
+	}
+	err = interactor.markOverdue(ctx, uid, tasks...)
+	return
+}
+
+// findShared はuidのTaskと，uidに共有されたTaskとProjectのTask，uidが参加しているWorkspaceのTaskをqの条件で取得し，
+// 他のユーザーのTaskにRoleを設定する
+func (interactor *TaskInteractor) findShared(ctx context.Context, uid string, q repository.TaskQuery) ([]*entity.Task, error) {
+	grants, err := interactor.Policy.Grants(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	q.SharedTaskIDs, q.SharedProjectIDs, q.WorkspaceIDs = grants.TaskIDs(), grants.ProjectIDs(), grants.WorkspaceIDs()
+	tasks, err := interactor.Task.FindByUser(ctx, uid, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.UserID.String() != uid {
+			task.Role = grants.Role(task)
+		}
+	}
+	return tasks, nil
+}
+
+// Overdue はuidの期限を過ぎた未完了のTaskの一覧をユーザーのタイムゾーンでの今日より前の日付のものとして取得する
+func (interactor *TaskInteractor) Overdue(ctx context.Context, uid string) (view *entity.TaskView, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Overdue")
+	defer func() { endSpan(span, err) }()
+
+	today, err := interactor.today(ctx, uid)
+	if err != nil {
+		return
+	}
+	tasks, err := interactor.findShared(ctx, uid, repository.TaskQuery{Before: today, Incomplete: true})
+	if err != nil {
+		return
+	}
+	err = interactor.markOverdue(ctx, uid, tasks...)
+	if err != nil {
+		return
+	}
+	yesterday := entity.NewNullDateFromTime(today.Time.AddDate(0, 0, -1))
+	view = entity.NewTaskView(nil, &yesterday, tasks)
+	return
+}
+
+// Today はuidのユーザーのタイムゾーンでの今日のTaskの一覧を取得する
+func (interactor *TaskInteractor) Today(ctx context.Context, uid string) (view *entity.TaskView, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Today")
+	defer func() { endSpan(span, err) }()
+
+	today, err := interactor.today(ctx, uid)
+	if err != nil {
+		return
+	}
+	tasks, err := interactor.List(ctx, uid, repository.TaskQuery{From: today, To: today})
+	if err != nil {
+		return
+	}
+	view = entity.NewTaskView(&today, &today, tasks)
+	return
+}
+
+// Upcoming はuidのユーザーのタイムゾーンでの明日からdays日間のTaskの一覧を取得する
+func (interactor *TaskInteractor) Upcoming(ctx context.Context, uid string, days int) (view *entity.TaskView, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Upcoming")
+	defer func() { endSpan(span, err) }()
+
+	if days < 1 || days > maxListDays {
+		return nil, ErrInvalidTask
+	}
+	today, err := interactor.today(ctx, uid)
+	if err != nil {
+		return
+	}
+	from := entity.NewNullDateFromTime(today.Time.AddDate(0, 0, 1))
+	to := entity.NewNullDateFromTime(today.Time.AddDate(0, 0, days))
+	tasks, err := interactor.List(ctx, uid, repository.TaskQuery{From: from, To: to})
+	if err != nil {
+		return
+	}
+	view = entity.NewTaskView(&from, &to, tasks)
+	return
+}
+
+// today はuidのユーザーのタイムゾーンでの今日の日付を返す
+func (interactor *TaskInteractor) today(ctx context.Context, uid string) (entity.NullDate, error) {
+	loc, err := userLocation(ctx, interactor.User, uid)
+	if err != nil {
+		return entity.NullDate{}, err
+	}
+	return entity.Today(interactor.Clock.Now(), loc), nil
+}
+
+// Search はuidのTaskからqに一致するものを一致度の高い順に取得し，一致した部分を強調する
+func (interactor *TaskInteractor) Search(ctx context.Context, uid, q string) (results []*entity.SearchResult, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Search")
+	defer func() { endSpan(span, err) }()
+
+	terms := entity.ParseSearchQuery(q)
+	if len(terms) == 0 || len(terms) > maxSearchTerms || utf8.RuneCountInString(q) > maxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	results, err = interactor.Task.Search(ctx, uid, terms, maxSearchResults)
+	if err != nil {
+		return
+	}
+	tasks := make([]*entity.Task, len(results))
+	for i, r := range results {
+		tasks[i] = r.Task
+		r.Title = entity.Highlight(r.Task.Title.String(), terms, 0)
+		r.Snippet = entity.Highlight(r.Task.Content.String(), terms, searchSnippetWidth)
+	}
+	err = interactor.markOverdue(ctx, uid, tasks...)
 	return
 }
 
-func (interactor *TaskInteractor) Update(task *entity.Task) (err error) {
+const (
+	// maxSearchQueryLength は検索文字列の文字数の上限である
+	maxSearchQueryLength = 256
+	// maxSearchTerms は検索文字列に含められる語の数の上限である
+	maxSearchTerms = 10
+	// maxSearchResults は検索結果の件数の上限である
+	maxSearchResults = 50
+	// searchSnippetWidth は検索結果のSnippetの文字数である
+	searchSnippetWidth = 120
+)
+
+// maxListDays は期間を指定してTaskの一覧を取得するときの期間の日数の上限である
+const maxListDays = 366
+
+// expandOccurrences は未完了の繰り返しのTaskをqの期間内の回に展開する
+// 展開した回のうちTask自身の日付以外の回はVirtualとし，日付順に並べ直す
+func expandOccurrences(tasks []*entity.Task, q repository.TaskQuery) []*entity.Task {
+	expanded := make([]*entity.Task, 0, len(tasks))
+	for _, task := range tasks {
+		r, err := task.Recurrence()
+		if err != nil || r == nil || task.IsCompleted {
+			if !task.Deadline.Time.Before(q.From.Time) {
+				expanded = append(expanded, task)
+			}
+			continue
+		}
+		for _, date := range r.Between(task.Deadline.Time, q.From.Time, q.To.Time) {
+			if date.Equal(task.Deadline.Time) {
+				expanded = append(expanded, task)
+				continue
+			}
+			occurrence := *task
+			occurrence.Deadline = entity.NewNullDateFromTime(date)
+			occurrence.Virtual = true
+			expanded = append(expanded, &occurrence)
+		}
+	}
+	sort.SliceStable(expanded, func(i, j int) bool {
+		a, b := expanded[i], expanded[j]
+		if q.Sort == repository.SortByPriority && a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Deadline.Time.Before(b.Deadline.Time)
+	})
+	return expanded
+}
+
+// normalizeRecurrence はTaskの繰り返しの規則を検証して正規化する．規則が不正な場合はfalseを返す
+func normalizeRecurrence(task *entity.Task) bool {
+	r, err := task.Recurrence()
+	if err != nil {
+		return false
+	}
+	if r != nil {
+		task.RRule = entity.NewNullString(r.String())
+	}
+	return true
+}
+
+// validDueTime はTaskの期限の時刻が正しいかを返す
+func validDueTime(task *entity.Task) bool {
+	return task.DueTime.IsNull() || entity.ValidTimeOfDay(task.DueTime.String())
+}
+
+// Reorder はTaskを同じ日のTaskのうちafterの直後，またはbeforeの直前に移動する
+// 移動するTaskのPositionのみを更新するが，Positionが重複していたり長くなりすぎた場合はその日のTaskの順番を振り直す
+// condが指定された場合，移動するTaskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Reorder(ctx context.Context, tid, uid, after, before string, cond entity.Precondition) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Reorder")
+	defer func() { endSpan(span, err) }()
+
+	// afterとbeforeのどちらか一方のみを指定する
+	if (after == "") == (before == "") || after == tid || before == tid {
+		return nil, ErrInvalidTask
+	}
+
+	task, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	version, err := checkVersion(cond, task.Version)
+	if err != nil {
+		return nil, err
+	}
+	original := *task
+	// サブタスクは同じ親のTaskの中で並べる
+	// 共有されたTaskは所有者のTaskの中で並べる
+	owner := task.UserID.String()
+	q := repository.TaskQuery{Date: task.Deadline, Sort: repository.SortByPosition}
+	if !task.ParentID.IsNull() {
+		q = repository.TaskQuery{ParentID: task.ParentID.String(), Sort: repository.SortByPosition}
+	}
+	tasks, err := interactor.Task.FindByUser(ctx, owner, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// 移動するTaskを除いた並びの中で挿入する位置を探す
+	others := make([]*entity.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.ID.String() != tid {
+			others = append(others, t)
+		}
+	}
+	index := -1
+	for i, t := range others {
+		if t.ID.String() == after {
+			index = i + 1
+		}
+		if t.ID.String() == before {
+			index = i
+		}
+	}
+	// 基準のTaskが同じ並びに存在しない
+	if index < 0 {
+		return nil, ErrInvalidTask
+	}
+
+	var prev, next string
+	if index > 0 {
+		prev = others[index-1].Position
+	}
+	if index < len(others) {
+		next = others[index].Position
+	}
+	updated := []*entity.Task{task}
+	task.Position, err = entity.RankBetween(prev, next)
+	// RankBetweenはErrInvalidRankかErrRankTooLongのみを返すので，どちらの場合も順番を振り直す
+	if err != nil || !isOrdered(others) {
+		updated = append(append(append([]*entity.Task{}, others[:index]...), task), others[index:]...)
+		for i, rank := range entity.EvenRanks(len(updated)) {
+			updated[i].Position = rank
+		}
+	}
+
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.lockVersion(ctx, task, version)
+		if err != nil {
+			return err
+		}
+		err = interactor.Task.UpdatePositions(ctx, owner, updated)
+		if err != nil {
+			return err
+		}
+		// 振り直した他のTaskの順番は記録しない
+		return interactor.record(ctx, uid, entity.ActionUpdate, &original, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	interactor.Logger.InfoContext(ctx, "task reordered",
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+		slog.Int("updated", len(updated)),
+	)
+	return task, nil
+}
+
+// checkProject はuidがTaskのProjectにTaskを追加でき，ProjectがTaskと同じユーザーのものであることを確認する
+func (interactor *TaskInteractor) checkProject(ctx context.Context, task *entity.Task, uid string) error {
+	if task.ProjectID.IsNull() {
+		return nil
+	}
+	project, err := interactor.authorizeProject(ctx, task, uid)
+	if err != nil {
+		return err
+	}
+	if !project.UserID.Equal(task.UserID) {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// checkWorkspace はuidがTaskのWorkspaceにTaskを追加できるメンバーであることを確認する
+func (interactor *TaskInteractor) checkWorkspace(ctx context.Context, task *entity.Task, uid string) error {
+	if task.WorkspaceID.IsNull() {
+		return nil
+	}
+	_, err := interactor.Policy.RBAC.Authorize(ctx, task.WorkspaceID.String(), uid, entity.WorkspaceMember)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return ErrWorkspaceNotFound
+	}
+	return err
+}
+
+// authorizeProject はuidがTaskのProjectにTaskを追加できることを確認し，Projectを返す
+func (interactor *TaskInteractor) authorizeProject(ctx context.Context, task *entity.Task, uid string) (*entity.Project, error) {
+	project, err := interactor.Policy.AuthorizeProject(ctx, task.ProjectID.String(), uid, entity.RoleEditor)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// ListSubtasks はTaskのサブタスクの一覧を並び順に取得する
+func (interactor *TaskInteractor) ListSubtasks(ctx context.Context, tid, uid string) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.ListSubtasks")
+	defer func() { endSpan(span, err) }()
+
+	parent, err := interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return
+	}
+	tasks, err = interactor.Task.FindByUser(ctx, parent.UserID.String(), repository.TaskQuery{ParentID: tid, Sort: repository.SortByPosition})
+	if err != nil {
+		return
+	}
+	// サブタスクは親のTaskの共有を引き継ぐ
+	for _, task := range tasks {
+		task.Role = parent.Role
+	}
+	err = interactor.markOverdue(ctx, uid, tasks...)
+	return
+}
+
+// reschedule はTaskの未送信のReminderのうち期限の何前に通知するものの時刻をTaskの期限から計算し直す
+func (interactor *TaskInteractor) reschedule(ctx context.Context, task *entity.Task) error {
+	uid := task.UserID.String()
+	reminders, err := interactor.Reminder.FindByTask(ctx, task.ID.String(), uid)
+	if err != nil {
+		return err
+	}
+	changed := []*entity.Reminder{}
+	for _, r := range reminders {
+		if _, ok := r.Offset(); ok && r.SentAt == nil {
+			changed = append(changed, r)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	loc, err := userLocation(ctx, interactor.User, uid)
+	if err != nil {
+		return err
+	}
+	for _, r := range changed {
+		r.Schedule(task, loc)
+	}
+	return interactor.Reminder.Reschedule(ctx, uid, changed)
+}
+
+// copyReminders はdoneの期限の何前に通知するReminderをnextに付ける
+func (interactor *TaskInteractor) copyReminders(ctx context.Context, done, next *entity.Task) error {
+	uid := done.UserID.String()
+	reminders, err := interactor.Reminder.FindByTask(ctx, done.ID.String(), uid)
+	if err != nil {
+		return err
+	}
+	var loc *time.Location
+	for _, r := range reminders {
+		if _, ok := r.Offset(); !ok {
+			continue
+		}
+		if loc == nil {
+			loc, err = userLocation(ctx, interactor.User, uid)
+			if err != nil {
+				return err
+			}
+		}
+		copied := &entity.Reminder{TaskID: next.ID, UserID: next.UserID, Before: r.Before}
+		copied.Schedule(next, loc)
+		err = interactor.Reminder.Create(ctx, copied)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markOverdue はtasksが期限を過ぎているかをユーザーのタイムゾーンで判定してOverdueに設定する
+func (interactor *TaskInteractor) markOverdue(ctx context.Context, uid string, tasks ...*entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	loc, err := userLocation(ctx, interactor.User, uid)
+	if err != nil {
+		return err
+	}
+	now := interactor.Clock.Now()
+	for _, task := range tasks {
+		task.Overdue = task.IsOverdue(now, loc)
+	}
+	return nil
+}
+
+// lockVersion はversionが0でない場合，Taskの行をロックして確かめてから他で変更されていないことを確認する
+// ロックはトランザクションをコミットするまで保持する
+func (interactor *TaskInteractor) lockVersion(ctx context.Context, task *entity.Task, version int) error {
+	if version == 0 {
+		return nil
+	}
+	return versionError(interactor.Task.CheckVersion(ctx, task.ID.String(), task.UserID.String(), version))
+}
+
+// Move はTaskをparentIDのTaskのサブタスクにする．parentIDが""の場合は親のないTaskにする
+// 移動したTaskは移動先の並びの末尾に置く
+// condが指定された場合，移動するTaskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Move(ctx context.Context, tid, uid, parentID string, cond entity.Precondition) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Move")
+	defer func() { endSpan(span, err) }()
+
+	task, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	version, err := checkVersion(cond, task.Version)
+	if err != nil {
+		return nil, err
+	}
+	before := *task
+	task.ParentID.Set(parentID)
+	if !task.ParentID.IsNull() {
+		var parent *entity.Task
+		parent, err = interactor.authorizeParent(ctx, task, uid)
+		if err != nil {
+			return nil, err
+		}
+		// 他のユーザーのTaskの間では移動できない
+		if !parent.UserID.Equal(task.UserID) {
+			return nil, ErrParentNotFound
+		}
+		err = interactor.checkParent(ctx, task, parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.lockVersion(ctx, task, version)
+		if err != nil {
+			return err
+		}
+		err = interactor.Task.UpdateParent(ctx, task)
+		if err != nil {
+			return err
+		}
+		return interactor.record(ctx, uid, entity.ActionUpdate, &before, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	interactor.Logger.InfoContext(ctx, "task moved",
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+		slog.String("parent_id", parentID),
+	)
+	return task, nil
+}
+
+// Complete はTaskの完了状態を変更し，親の完了状態に反映する
+// condが指定された場合，Taskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Complete(ctx context.Context, tid, uid string, comp bool, cond entity.Precondition) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Complete")
+	defer func() { endSpan(span, err) }()
+
+	task, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	version, err := checkVersion(cond, task.Version)
+	if err != nil {
+		return nil, err
+	}
+	before := *task
+	task.SetComp(comp)
+	// 繰り返しのTaskは次の回を作成するので親には反映しない
+	// 完了と次の回の作成，Reminderの引き継ぎはまとめて反映する
+	if comp && !task.RRule.IsNull() {
+		err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+			err := interactor.lockVersion(ctx, task, version)
+			if err != nil {
+				return err
+			}
+			next, err := interactor.recur(ctx, task)
+			if err != nil {
+				return err
+			}
+			err = interactor.record(ctx, uid, entity.ActionComplete, &before, task)
+			if err != nil {
+				return err
+			}
+			return interactor.recordNext(ctx, uid, next)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+	// Taskと親の完了状態はまとめて反映する
+	var parents []*entity.Task
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.lockVersion(ctx, task, version)
+		if err != nil {
+			return err
+		}
+		parents, err = interactor.rollup(ctx, task)
+		if err != nil {
+			return err
+		}
+		err = interactor.Task.UpdateCompletions(ctx, task.UserID.String(), append([]*entity.Task{task}, parents...))
+		if err != nil {
+			return err
+		}
+		err = interactor.record(ctx, uid, entity.ActionComplete, &before, task)
+		if err != nil {
+			return err
+		}
+		return interactor.recordParents(ctx, uid, parents)
+	})
+	if err != nil {
+		return nil, err
+	}
+	interactor.Logger.InfoContext(ctx, "task completed",
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+		slog.Bool("completed", comp),
+		slog.Int("rolled_up", len(parents)),
+	)
+	return task, nil
+}
+
+// Assign はTaskの担当者をemailのユーザーに変更し，担当者に通知するために変更を記録する．emailが""の場合は担当者を外す
+// Taskを編集できるユーザーのみが変更でき，担当者はTaskにアクセスできるユーザーでなければならない
+// condが指定された場合，Taskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Assign(ctx context.Context, tid, uid, email string, cond entity.Precondition) (task *entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Assign")
+	defer func() { endSpan(span, err) }()
+
+	task, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	version, err := checkVersion(cond, task.Version)
+	if err != nil {
+		return nil, err
+	}
+	before := *task
+	var assignee *entity.User
+	if email != "" {
+		assignee, err = interactor.User.FindByEmail(ctx, email)
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			return nil, ErrInvalidAssignee
+		}
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		ok, err = interactor.Policy.CanAccessTask(ctx, task, assignee.ID.String(), entity.RoleViewer)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidAssignee
+		}
+	}
+
+	a := &entity.Assignment{TaskID: task.ID, AssignerID: entity.NewNullString(uid)}
+	if assignee != nil {
+		a.AssigneeID = assignee.ID
+	}
+	// 担当者が変わらない場合は記録せず，再度通知しない
+	if a.AssigneeID.Equal(task.AssigneeID) {
+		return task, interactor.markOverdue(ctx, uid, task)
+	}
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.lockVersion(ctx, task, version)
+		if err != nil {
+			return err
+		}
+		err = interactor.Assignment.Create(ctx, a)
+		if err != nil {
+			return err
+		}
+		task.AssigneeID, task.Assignee = a.AssigneeID, assignee
+		err = interactor.markOverdue(ctx, uid, task)
+		if err != nil {
+			return err
+		}
+		return interactor.record(ctx, uid, entity.ActionUpdate, &before, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	interactor.Logger.InfoContext(ctx, "task assigned",
+		slog.String("task_id", tid),
+		slog.String("assignment_id", a.ID.String()),
+		slog.String("user_id", uid),
+	)
+	return task, nil
+}
+
+// AssignedToMe はuidが担当するTaskの一覧をサブタスクも含めて取得する
+func (interactor *TaskInteractor) AssignedToMe(ctx context.Context, uid string) (tasks []*entity.Task, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.AssignedToMe")
+	defer func() { endSpan(span, err) }()
+
+	tasks, err = interactor.findShared(ctx, uid, repository.TaskQuery{AssigneeID: uid})
+	if err != nil {
+		return
+	}
+	err = interactor.markOverdue(ctx, uid, tasks...)
+	return
+}
+
+// recur は完了した繰り返しのTaskから繰り返しを外し，次の回のTaskを作成する
+// 次の回はタイトルなどと担当者，期限の何前に通知するReminderを引き継ぎ，COUNTを1減らした規則で繰り返す．Tagは引き継がない
+// 繰り返しが終わった場合はnextをnilとする
+func (interactor *TaskInteractor) recur(ctx context.Context, task *entity.Task) (next *entity.Task, err error) {
+	r, err := task.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+	if date, ok := r.Next(task.Deadline.Time); ok {
+		next = &entity.Task{
+			Title:       task.Title,
+			Content:     task.Content,
+			UserID:      task.UserID,
+			ProjectID:   task.ProjectID,
+			WorkspaceID: task.WorkspaceID,
+			ParentID:    task.ParentID,
+			AssigneeID:  task.AssigneeID,
+			Deadline:    entity.NewNullDateFromTime(date),
+			DueTime:     task.DueTime,
+			Priority:    task.Priority,
+			RRule:       entity.NewNullString(r.After().String()),
+		}
+	}
+
+	err = interactor.Task.Recur(ctx, task, next)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		err = interactor.copyReminders(ctx, task, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	attrs := []any{
+		slog.String("task_id", task.ID.String()),
+		slog.String("user_id", task.UserID.String()),
+	}
+	if next != nil {
+		attrs = append(attrs, slog.String("next_task_id", next.ID.String()))
+	}
+	interactor.Logger.InfoContext(ctx, "recurring task completed", attrs...)
+	return next, nil
+}
+
+// updateParents はTaskの完了状態を親に反映し，完了状態が変わった親を返す
+func (interactor *TaskInteractor) updateParents(ctx context.Context, task *entity.Task) (parents []*entity.Task, err error) {
+	parents, err = interactor.rollup(ctx, task)
+	if err != nil || len(parents) == 0 {
+		return nil, err
+	}
+	return parents, interactor.Task.UpdateCompletions(ctx, task.UserID.String(), parents)
+}
+
+// rollup はTaskの完了状態を親に反映した結果，完了状態が変わる親を返す
+// すべてのサブタスクが完了した親は完了にし，完了済みの親のサブタスクが未完了に戻った場合は親も未完了に戻す
+func (interactor *TaskInteractor) rollup(ctx context.Context, task *entity.Task) (parents []*entity.Task, err error) {
+	uid := task.UserID.String()
+	for child := task; !child.ParentID.IsNull() && len(parents) < entity.MaxTaskDepth; {
+		parent, err := interactor.Task.FindByID(ctx, child.ParentID.String(), uid)
+		if err != nil {
+			return nil, err
+		}
+		siblings, err := interactor.Task.FindByUser(ctx, uid, repository.TaskQuery{ParentID: parent.ID.String()})
+		if err != nil {
+			return nil, err
+		}
+		done := child.IsCompleted
+		for _, s := range siblings {
+			if !s.ID.Equal(child.ID) {
+				done = done && s.IsCompleted
+			}
+		}
+		// 完了したサブタスクで親を未完了に戻したり，未完了のサブタスクで親を完了にしたりはしない
+		if done != child.IsCompleted || done == parent.IsCompleted {
+			break
+		}
+		parent.SetComp(done)
+		parents = append(parents, parent)
+		child = parent
+	}
+	return parents, nil
+}
+
+// authorizeParent はuidがTaskのParentIDのTaskにサブタスクを追加できることを確認し，親のTaskを返す
+func (interactor *TaskInteractor) authorizeParent(ctx context.Context, task *entity.Task, uid string) (*entity.Task, error) {
+	parent, err := interactor.Policy.AuthorizeTask(ctx, task.ParentID.String(), uid, entity.RoleEditor)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, ErrParentNotFound
+	}
+	return parent, err
+}
+
+// checkParent はTaskをparentのサブタスクにできることを確認する
+// 親は同じユーザーのTaskでなければならず，Task自身やそのサブタスクを親にすることや，深さがentity.MaxTaskDepthを超えることはできない
+func (interactor *TaskInteractor) checkParent(ctx context.Context, task, parent *entity.Task) (err error) {
+	uid := task.UserID.String()
+
+	// 親の深さを数えながら，祖先にTask自身が含まれていないことを確認する
+	depth := 1
+	for ancestor := parent; ; depth++ {
+		if !task.ID.IsNull() && ancestor.ID.Equal(task.ID) {
+			return ErrTaskCycle
+		}
+		if ancestor.ParentID.IsNull() || depth > entity.MaxTaskDepth {
+			break
+		}
+		ancestor, err = interactor.Task.FindByID(ctx, ancestor.ParentID.String(), uid)
+		if err != nil {
+			return err
+		}
+	}
+
+	height := 1
+	if !task.ID.IsNull() {
+		height, err = interactor.height(ctx, task.ID.String(), uid, entity.MaxTaskDepth)
+		if err != nil {
+			return err
+		}
+	}
+	if depth+height > entity.MaxTaskDepth {
+		return ErrTaskTooDeep
+	}
+	return nil
+}
+
+// height はTaskとそのサブタスクからなる木の高さを返す．limitを超える分は数えない
+func (interactor *TaskInteractor) height(ctx context.Context, tid, uid string, limit int) (int, error) {
+	if limit <= 1 {
+		return 1, nil
+	}
+	children, err := interactor.Task.FindByUser(ctx, uid, repository.TaskQuery{ParentID: tid})
+	if err != nil {
+		return 0, err
+	}
+	max := 0
+	for _, child := range children {
+		h, err := interactor.height(ctx, child.ID.String(), uid, limit-1)
+		if err != nil {
+			return 0, err
+		}
+		if h > max {
+			max = h
+		}
+	}
+	return max + 1, nil
+}
+
+// isOrdered はtasksのPositionが重複なく昇順に並んでいるかを返す
+func isOrdered(tasks []*entity.Task) bool {
+	for i, t := range tasks {
+		if t.Position == "" || (i > 0 && tasks[i-1].Position >= t.Position) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update はcondが指定された場合，Taskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Update(ctx context.Context, task *entity.Task, cond entity.Precondition) (err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
 	// databaseのnot null制約と重複
 	// 不正なユーザーリクエストの判別(フィールドのうち少なくともひとつがnilの場合)
 	if task.Title.IsNull() ||
@@ -46,15 +956,165 @@ func (interactor *TaskInteractor) Update(task *entity.Task) (err error) {
 		task.ID.IsNull() {
 		return ErrInvalidTask
 	}
+	if !task.Priority.IsValid() || !normalizeRecurrence(task) || !validDueTime(task) {
+		return ErrInvalidTask
+	}
+	uid := task.UserID.String()
+	current, err := interactor.Policy.AuthorizeTask(ctx, task.ID.String(), uid, entity.RoleEditor)
+	if err != nil {
+		return
+	}
+	task.Version, err = checkVersion(cond, current.Version)
+	if err != nil {
+		return
+	}
+	// 共有されたTaskは所有者のTaskとして更新する．担当者はAssignでのみ変更する
+	task.UserID, task.Role = current.UserID, current.Role
+	task.AssigneeID, task.Assignee = current.AssigneeID, current.Assignee
+	err = interactor.checkProject(ctx, task, uid)
+	if err != nil {
+		return
+	}
+	// サブタスクのWorkspaceは親から引き継いだまま変えない
+	// Workspaceを変えられるのはTaskを削除できるユーザーのみである
+	if !current.ParentID.IsNull() {
+		task.WorkspaceID = current.WorkspaceID
+	} else if !task.WorkspaceID.Equal(current.WorkspaceID) {
+		if current.Role != "" && !current.Role.Can(entity.RoleOwner) {
+			return ErrForbidden
+		}
+		err = interactor.checkWorkspace(ctx, task, uid)
+		if err != nil {
+			return
+		}
+	}
 
-	// Taskデータを更新
-	err = interactor.Task.Update(task)
-
+	// 更新と，それに伴うReminderの時刻，繰り返しの次の回，親の完了状態の変更はまとめて反映する
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		// Taskデータを更新
+		err := interactor.Task.Update(ctx, task)
+		if err != nil {
+			return versionError(err)
+		}
+		// 期限が変わった場合に備えて期限の何前に通知するReminderの時刻を計算し直す
+		err = interactor.reschedule(ctx, task)
+		if err != nil {
+			return err
+		}
+		var next *entity.Task
+		var parents []*entity.Task
+		if task.IsCompleted && !task.RRule.IsNull() {
+			// 完了した繰り返しのTaskは次の回を作成する
+			next, err = interactor.recur(ctx, task)
+		} else {
+			// 完了状態を親に反映する
+			parents, err = interactor.updateParents(ctx, task)
+		}
+		if err != nil {
+			return err
+		}
+		err = interactor.markOverdue(ctx, uid, task)
+		if err != nil {
+			return err
+		}
+		err = interactor.record(ctx, uid, entity.ActionUpdate, current, task)
+		if err != nil {
+			return err
+		}
+		err = interactor.recordParents(ctx, uid, parents)
+		if err != nil {
+			return err
+		}
+		return interactor.recordNext(ctx, uid, next)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "task updated",
+		slog.String("task_id", task.ID.String()),
+		slog.String("user_id", uid),
+	)
 	return
 }
 
-func (interactor *TaskInteractor) Delete(tid, uid string) (err error) {
+// Delete はcondが指定された場合，Taskのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *TaskInteractor) Delete(ctx context.Context, tid, uid string, cond entity.Precondition) (err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	// 共有されたTaskは所有者のみが，WorkspaceのTaskは作成したユーザーとWorkspaceのadmin以上が削除できる
+	task, err := interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleOwner)
+	if err != nil {
+		return
+	}
+	version, err := checkVersion(cond, task.Version)
+	if err != nil {
+		return
+	}
+
 	// Taskの削除
-	err = interactor.Task.Delete(tid, uid)
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.Task.Delete(ctx, tid, task.UserID.String(), version)
+		if err != nil {
+			return versionError(err)
+		}
+		return interactor.record(ctx, uid, entity.ActionDelete, task, nil)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "task deleted",
+		slog.String("task_id", tid),
+		slog.String("user_id", uid),
+	)
 	return
 }
+
+// History はTaskへのActivityを新しい順にpageの範囲だけ取得する．Taskを閲覧できるユーザーのみが取得できる
+func (interactor *TaskInteractor) History(ctx context.Context, tid, uid string, page entity.Page) (history *entity.ActivityPage, err error) {
+	ctx, span := startSpan(ctx, "TaskInteractor.History")
+	defer func() { endSpan(span, err) }()
+
+	if !page.IsValid() {
+		return nil, ErrInvalidPage
+	}
+	_, err = interactor.Policy.AuthorizeTask(ctx, tid, uid, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	activities, total, err := interactor.Activity.FindByEntity(ctx, entity.ActivityTask, tid, page)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ActivityPage{Total: total, Limit: page.Limit, Offset: page.Offset, Activities: activities}, nil
+}
+
+// record はuidがTaskに行った操作をbeforeからafterへの変更として記録する
+func (interactor *TaskInteractor) record(ctx context.Context, uid string, action entity.ActivityAction, before, after *entity.Task) error {
+	task := after
+	if task == nil {
+		task = before
+	}
+	return recordActivity(ctx, interactor.Activity, uid, entity.ActivityTask, task.ID.String(), action, entity.TaskChanges(before, after))
+}
+
+// recordParents はサブタスクの完了状態を反映して完了状態が変わった親を記録する
+func (interactor *TaskInteractor) recordParents(ctx context.Context, uid string, parents []*entity.Task) error {
+	for _, parent := range parents {
+		before := *parent
+		before.IsCompleted = !parent.IsCompleted
+		err := interactor.record(ctx, uid, entity.ActionComplete, &before, parent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordNext は繰り返しのTaskを完了して作成した次の回を記録する
+func (interactor *TaskInteractor) recordNext(ctx context.Context, uid string, next *entity.Task) error {
+	if next == nil {
+		return nil
+	}
+	return interactor.record(ctx, uid, entity.ActionCreate, nil, next)
+}
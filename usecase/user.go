@@ -6,26 +6,39 @@ domainにのみ依存
 package usecase
 
 import (
+	"context"
+	"log/slog"
+
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 	"github.com/hiroyaonoe/todoapp-server/domain/repository"
 )
 
 // UserInteractor は複数のエンティティを操作する際に活用できる
+// Userの作成，更新，削除はTransactorで同じトランザクションでActivityに記録する
 type UserInteractor struct {
-	User repository.UserRepository
+	User       repository.UserRepository
+	Activity   repository.ActivityRepository
+	Transactor repository.Transactor
+	Logger     *slog.Logger
 }
 
-func NewUserInteractor(user repository.UserRepository) *UserInteractor {
-	return &UserInteractor{User: user}
+func NewUserInteractor(user repository.UserRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *UserInteractor {
+	return &UserInteractor{User: user, Activity: activity, Transactor: transactor, Logger: logger}
 }
 
-func (interactor *UserInteractor) Get(id string) (user *entity.User, err error) {
+func (interactor *UserInteractor) Get(ctx context.Context, id string) (user *entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserInteractor.Get")
+	defer func() { endSpan(span, err) }()
+
 	// User の取得
-	user, err = interactor.User.FindByID(id)
+	user, err = interactor.User.FindByID(ctx, id)
 	return
 }
 
-func (interactor *UserInteractor) Create(user *entity.User) (err error) {
+func (interactor *UserInteractor) Create(ctx context.Context, user *entity.User) (err error) {
+	ctx, span := startSpan(ctx, "UserInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
 	// databaseのnot null制約があるので不要？
 	// 不正なユーザーリクエストの判別(フィールドのうち少なくともひとつがnilの場合)
 	if user.Name.IsNull() || user.Password.IsNull() || user.Email.IsNull() {
@@ -35,14 +48,30 @@ func (interactor *UserInteractor) Create(user *entity.User) (err error) {
 	if !user.ID.IsNull() {
 		return ErrInvalidUser
 	}
+	if !validTimeZone(user) {
+		return ErrInvalidUser
+	}
 
 	// 新規Userを作成
-	err = interactor.User.Create(user)
-
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.User.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+		return interactor.record(ctx, user.ID.String(), entity.ActionCreate, nil, user)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "user created", slog.String("user_id", user.ID.String()))
 	return
 }
 
-func (interactor *UserInteractor) Update(user *entity.User) (err error) {
+// Update はcondが指定された場合，Userのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *UserInteractor) Update(ctx context.Context, user *entity.User, cond entity.Precondition) (err error) {
+	ctx, span := startSpan(ctx, "UserInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
 	// 不正なユーザーリクエストの判別(フィールドのうち少なくともひとつがnilの場合)
 	if user.Name.IsNull() ||
 		user.Password.IsNull() ||
@@ -50,15 +79,93 @@ func (interactor *UserInteractor) Update(user *entity.User) (err error) {
 		user.ID.IsNull() {
 		return ErrInvalidUser
 	}
+	if !validTimeZone(user) {
+		return ErrInvalidUser
+	}
+	current, err := interactor.User.FindByID(ctx, user.ID.String())
+	if err != nil {
+		return
+	}
+	user.Version, err = checkVersion(cond, current.Version)
+	if err != nil {
+		return
+	}
+	// 保存するとパスワードをハッシュ化するので，変わったかどうかは保存する前に比べる
+	changes := entity.UserChanges(current, user)
 
 	// Userデータを更新
-	err = interactor.User.Update(user)
-
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.User.Update(ctx, user)
+		if err != nil {
+			return versionError(err)
+		}
+		return recordActivity(ctx, interactor.Activity, user.ID.String(), entity.ActivityUser, user.ID.String(), entity.ActionUpdate, changes)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "user updated", slog.String("user_id", user.ID.String()))
 	return
 }
 
-func (interactor *UserInteractor) Delete(id string) (err error) {
+// Delete はcondが指定された場合，Userのバージョンが一致しなければErrPreconditionFailedを返す
+func (interactor *UserInteractor) Delete(ctx context.Context, id string, cond entity.Precondition) (err error) {
+	ctx, span := startSpan(ctx, "UserInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	current, err := interactor.User.FindByID(ctx, id)
+	if err != nil {
+		return
+	}
+	version, err := checkVersion(cond, current.Version)
+	if err != nil {
+		return
+	}
+
 	// Userデータを削除
-	err = interactor.User.Delete(id)
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		err := interactor.User.Delete(ctx, id, version)
+		if err != nil {
+			return versionError(err)
+		}
+		return interactor.record(ctx, id, entity.ActionDelete, current, nil)
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "user deleted", slog.String("user_id", id))
 	return
 }
+
+// Activities はuidのユーザーが行ったActivityを新しい順にpageの範囲だけ取得する
+func (interactor *UserInteractor) Activities(ctx context.Context, uid string, page entity.Page) (activities *entity.ActivityPage, err error) {
+	ctx, span := startSpan(ctx, "UserInteractor.Activities")
+	defer func() { endSpan(span, err) }()
+
+	if !page.IsValid() {
+		return nil, ErrInvalidPage
+	}
+	found, total, err := interactor.Activity.FindByActor(ctx, uid, page)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ActivityPage{Total: total, Limit: page.Limit, Offset: page.Offset, Activities: found}, nil
+}
+
+// record はUserに行った操作をbeforeからafterへの変更として記録する．操作したのはUser自身である
+func (interactor *UserInteractor) record(ctx context.Context, uid string, action entity.ActivityAction, before, after *entity.User) error {
+	return recordActivity(ctx, interactor.Activity, uid, entity.ActivityUser, uid, action, entity.UserChanges(before, after))
+}
+
+// validTimeZone はUserのタイムゾーンがIANAのタイムゾーン名として正しいかを返す
+func validTimeZone(user *entity.User) bool {
+	if user.TimeZone.IsNull() {
+		return true
+	}
+	// time.LoadLocationは""と"Local"をサーバーのタイムゾーンとして受け付けるので除く
+	if user.TimeZone.String() == "Local" {
+		return false
+	}
+	_, err := user.Location()
+	return err == nil
+}
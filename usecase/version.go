@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// checkVersion はIf-Matchで指定されたcondに現在のversionが一致するかを確かめ，repositoryで改めて比べるバージョンを返す
+// 条件がない場合と*の場合はバージョンを比べないので0を返す
+func checkVersion(cond entity.Precondition, version int) (int, error) {
+	if cond.IsEmpty() || cond.IsAny() {
+		return 0, nil
+	}
+	if !cond.Match(version) {
+		return 0, ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+// versionError は確かめてから保存するまでに他で変更された場合もErrPreconditionFailedとして返す
+func versionError(err error) error {
+	if errors.Is(err, entity.ErrVersionConflict) {
+		return ErrPreconditionFailed
+	}
+	return err
+}
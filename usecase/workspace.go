@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+)
+
+// WorkspaceInteractor はWorkspaceとそのメンバーを扱う
+// RBACはWorkspaceを操作できるかの判定に使う
+type WorkspaceInteractor struct {
+	Workspace  repository.WorkspaceRepository
+	Membership repository.MembershipRepository
+	User       repository.UserRepository
+	Activity   repository.ActivityRepository
+	Transactor repository.Transactor
+	RBAC       *RBAC
+	Logger     *slog.Logger
+}
+
+func NewWorkspaceInteractor(workspace repository.WorkspaceRepository, membership repository.MembershipRepository, user repository.UserRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *WorkspaceInteractor {
+	return &WorkspaceInteractor{Workspace: workspace, Membership: membership, User: user, Activity: activity, Transactor: transactor, RBAC: NewRBAC(membership), Logger: logger}
+}
+
+// Create はWorkspaceを作成し，uidのユーザーをownerにする
+func (interactor *WorkspaceInteractor) Create(ctx context.Context, workspace *entity.Workspace, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.Create")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if workspace.Name.IsNull() || !workspace.ID.IsNull() {
+		return ErrInvalidWorkspace
+	}
+
+	err = interactor.Workspace.Create(ctx, workspace, uid)
+	if err != nil {
+		return
+	}
+	workspace.Role = entity.WorkspaceOwner
+	interactor.Logger.InfoContext(ctx, "workspace created",
+		slog.String("workspace_id", workspace.ID.String()),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// List はuidが参加しているWorkspaceの一覧を参加した順に取得する
+func (interactor *WorkspaceInteractor) List(ctx context.Context, uid string) (workspaces []*entity.Workspace, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.List")
+	defer func() { endSpan(span, err) }()
+
+	memberships, err := interactor.Membership.FindByUser(ctx, uid)
+	if err != nil {
+		return
+	}
+	ids := make([]string, len(memberships))
+	roles := map[string]entity.WorkspaceRole{}
+	for i, m := range memberships {
+		ids[i] = m.WorkspaceID.String()
+		roles[ids[i]] = m.Role
+	}
+	found, err := interactor.Workspace.FindByIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	byID := map[string]*entity.Workspace{}
+	for _, w := range found {
+		w.Role = roles[w.ID.String()]
+		byID[w.ID.String()] = w
+	}
+	workspaces = make([]*entity.Workspace, 0, len(found))
+	for _, id := range ids {
+		if w, ok := byID[id]; ok {
+			workspaces = append(workspaces, w)
+		}
+	}
+	return
+}
+
+// GetByID はuidが参加しているWorkspaceを取得する
+func (interactor *WorkspaceInteractor) GetByID(ctx context.Context, id, uid string) (workspace *entity.Workspace, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	membership, err := interactor.RBAC.Authorize(ctx, id, uid, entity.WorkspaceGuest)
+	if err != nil {
+		return
+	}
+	workspace, err = interactor.Workspace.FindByID(ctx, id)
+	if err != nil {
+		return
+	}
+	workspace.Role = membership.Role
+	return
+}
+
+// Update はWorkspaceの名前を変更する．admin以上のメンバーのみができる
+func (interactor *WorkspaceInteractor) Update(ctx context.Context, workspace *entity.Workspace, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.Update")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if workspace.Name.IsNull() || workspace.ID.IsNull() {
+		return ErrInvalidWorkspace
+	}
+	membership, err := interactor.RBAC.Authorize(ctx, workspace.ID.String(), uid, entity.WorkspaceAdmin)
+	if err != nil {
+		return
+	}
+
+	err = interactor.Workspace.Update(ctx, workspace)
+	if err != nil {
+		return
+	}
+	workspace.Role = membership.Role
+	interactor.Logger.InfoContext(ctx, "workspace updated",
+		slog.String("workspace_id", workspace.ID.String()),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// Delete はWorkspaceを削除する．ownerのみができ，WorkspaceのTaskは作成したユーザーのTaskとして残す
+func (interactor *WorkspaceInteractor) Delete(ctx context.Context, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.Delete")
+	defer func() { endSpan(span, err) }()
+
+	_, err = interactor.RBAC.Authorize(ctx, id, uid, entity.WorkspaceOwner)
+	if err != nil {
+		return
+	}
+
+	// Workspaceから外したTaskはTaskごとにActivityに記録する
+	err = interactor.Transactor.Transaction(ctx, func(ctx context.Context) error {
+		tasks, err := interactor.Workspace.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordTasks(ctx, interactor.Activity, uid, tasks, func(task *entity.Task) { task.WorkspaceID = entity.NewNullString("") })
+	})
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "workspace deleted",
+		slog.String("workspace_id", id),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// Members はuidが参加しているWorkspaceのメンバーの一覧を追加した順に取得する
+func (interactor *WorkspaceInteractor) Members(ctx context.Context, wid, uid string) (memberships []*entity.Membership, err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.Members")
+	defer func() { endSpan(span, err) }()
+
+	_, err = interactor.RBAC.Authorize(ctx, wid, uid, entity.WorkspaceGuest)
+	if err != nil {
+		return
+	}
+	memberships, err = interactor.Membership.FindByWorkspace(ctx, wid)
+	if err != nil {
+		return
+	}
+	err = interactor.fillEmails(ctx, memberships...)
+	return
+}
+
+// AddMember はemailのユーザーをmembershipのWorkspaceにRoleの役割で追加する
+// admin以上のメンバーのみができ，adminを追加できるのはownerのみである
+func (interactor *WorkspaceInteractor) AddMember(ctx context.Context, membership *entity.Membership, email, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.AddMember")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別(ownerは作成したユーザーのみである)
+	if !membership.ID.IsNull() || membership.WorkspaceID.IsNull() ||
+		!membership.Role.IsValid() || membership.Role == entity.WorkspaceOwner || email == "" {
+		return ErrInvalidWorkspace
+	}
+	wid := membership.WorkspaceID.String()
+	actor, err := interactor.RBAC.Authorize(ctx, wid, uid, entity.WorkspaceAdmin)
+	if err != nil {
+		return
+	}
+	if !canManage(actor, membership.Role) {
+		return ErrForbidden
+	}
+
+	user, err := interactor.User.FindByEmail(ctx, email)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return ErrInviteeNotFound
+	}
+	if err != nil {
+		return
+	}
+	membership.UserID = user.ID
+
+	err = interactor.Membership.Create(ctx, membership)
+	if err != nil {
+		return
+	}
+	membership.Email = user.Email.String()
+	interactor.Logger.InfoContext(ctx, "workspace member added",
+		slog.String("workspace_id", wid),
+		slog.String("membership_id", membership.ID.String()),
+		slog.String("role", string(membership.Role)),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// UpdateMember はメンバーの役割を変更する．変更前と変更後の役割をどちらも管理できるメンバーのみができる
+func (interactor *WorkspaceInteractor) UpdateMember(ctx context.Context, membership *entity.Membership, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.UpdateMember")
+	defer func() { endSpan(span, err) }()
+
+	// 不正なユーザーリクエストの判別
+	if membership.ID.IsNull() || membership.WorkspaceID.IsNull() ||
+		!membership.Role.IsValid() || membership.Role == entity.WorkspaceOwner {
+		return ErrInvalidWorkspace
+	}
+	wid := membership.WorkspaceID.String()
+	actor, err := interactor.RBAC.Authorize(ctx, wid, uid, entity.WorkspaceAdmin)
+	if err != nil {
+		return
+	}
+	target, err := interactor.findMember(ctx, wid, membership.ID.String())
+	if err != nil {
+		return
+	}
+	if !canManage(actor, target.Role) || !canManage(actor, membership.Role) {
+		return ErrForbidden
+	}
+
+	err = interactor.Membership.Update(ctx, membership)
+	if err != nil {
+		return
+	}
+	membership.UserID = target.UserID
+	err = interactor.fillEmails(ctx, membership)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "workspace member updated",
+		slog.String("workspace_id", wid),
+		slog.String("membership_id", membership.ID.String()),
+		slog.String("role", string(membership.Role)),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// RemoveMember はメンバーをWorkspaceから外す．メンバーは自分で抜けることもできるが，ownerは抜けられない
+func (interactor *WorkspaceInteractor) RemoveMember(ctx context.Context, wid, id, uid string) (err error) {
+	ctx, span := startSpan(ctx, "WorkspaceInteractor.RemoveMember")
+	defer func() { endSpan(span, err) }()
+
+	actor, err := interactor.RBAC.Authorize(ctx, wid, uid, entity.WorkspaceGuest)
+	if err != nil {
+		return
+	}
+	target, err := interactor.findMember(ctx, wid, id)
+	if err != nil {
+		return
+	}
+	if target.UserID.Equal(actor.UserID) {
+		if target.Role == entity.WorkspaceOwner {
+			return ErrForbidden
+		}
+	} else if !actor.Role.Can(entity.WorkspaceAdmin) || !canManage(actor, target.Role) {
+		return ErrForbidden
+	}
+
+	err = interactor.Membership.Delete(ctx, wid, id)
+	if err != nil {
+		return
+	}
+	interactor.Logger.InfoContext(ctx, "workspace member removed",
+		slog.String("workspace_id", wid),
+		slog.String("membership_id", id),
+		slog.String("user_id", uid),
+	)
+	return
+}
+
+// findMember はWorkspaceのidのメンバーを取得する．存在しない場合はErrMemberNotFoundを返す
+func (interactor *WorkspaceInteractor) findMember(ctx context.Context, wid, id string) (*entity.Membership, error) {
+	membership, err := interactor.Membership.FindByID(ctx, wid, id)
+	if errors.Is(err, entity.ErrRecordNotFound) {
+		return nil, ErrMemberNotFound
+	}
+	return membership, err
+}
+
+// canManage はactorがroleの役割のメンバーを追加，変更，削除できるかを返す
+// ownerはadmin以下を，adminはmember以下を管理でき，ownerは誰も管理できない
+func canManage(actor *entity.Membership, role entity.WorkspaceRole) bool {
+	switch role {
+	case entity.WorkspaceOwner:
+		return false
+	case entity.WorkspaceAdmin:
+		return actor.Role == entity.WorkspaceOwner
+	}
+	return actor.Role.Can(entity.WorkspaceAdmin)
+}
+
+// fillEmails はmembershipsのEmailにメンバーのEmailを設定する
+// ユーザーのIDは外部に公開しないので，メンバーはEmailで表す
+func (interactor *WorkspaceInteractor) fillEmails(ctx context.Context, memberships ...*entity.Membership) error {
+	for _, m := range memberships {
+		user, err := interactor.User.FindByID(ctx, m.UserID.String())
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		m.Email = user.Email.String()
+	}
+	return nil
+}
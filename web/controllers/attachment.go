@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+// attachmentFormField はファイルを送るmultipart/form-dataのフィールド名である
+const attachmentFormField = "file"
+
+type AttachmentController struct {
+	Interactor *usecase.AttachmentInteractor
+	Logger     *slog.Logger
+}
+
+func NewAttachmentController(attachment repository.AttachmentRepository, blob usecase.BlobStore, task repository.TaskRepository, project repository.ProjectRepository, share repository.ShareRepository, membership repository.MembershipRepository, maxSize, quota int64, logger *slog.Logger) *AttachmentController {
+	return &AttachmentController{
+		Interactor: usecase.NewAttachmentInteractor(attachment, blob, task, project, share, membership, maxSize, quota, logger),
+		Logger:     logger,
+	}
+}
+
+// Upload is the Handler for POST /task/:id/attachments
+// multipart/form-dataのfileフィールドでファイルを受け取る
+func (controller *AttachmentController) Upload(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	header, err := c.FormFile(attachmentFormField)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			errorToJSON(c, http.StatusRequestEntityTooLarge, ErrAttachmentTooLarge)
+			return
+		}
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	defer file.Close()
+
+	attachment := &entity.Attachment{
+		TaskID: entity.NewNullString(tid),
+		UserID: entity.NewNullString(uid),
+		Name:   entity.NewNullString(header.Filename),
+		Size:   header.Size,
+	}
+	err = controller.Interactor.Upload(c, attachment, file)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attachment)
+}
+
+// List is the Handler for GET /task/:id/attachments
+func (controller *AttachmentController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	attachments, err := controller.Interactor.List(c, tid, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Download is the Handler for GET /task/:id/attachments/:attachmentid
+// ファイルの中身をメモリに読み込まずにそのままレスポンスに書き込む
+func (controller *AttachmentController) Download(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("attachmentid")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	attachment, body, err := controller.Interactor.Download(c, tid, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	defer body.Close()
+	// ブラウザで開かずに保存させる
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name.String()})
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType.String(), body, map[string]string{
+		"Content-Disposition": disposition,
+	})
+}
+
+// Delete is the Handler for DELETE /task/:id/attachments/:attachmentid
+func (controller *AttachmentController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("attachmentid")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, tid, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+// errorHandling はAttachmentの操作で共通のエラーをレスポンスにする
+func (controller *AttachmentController) errorHandling(c Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidAttachment):
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+	case errors.Is(err, usecase.ErrAttachmentTooLarge):
+		errorToJSON(c, http.StatusRequestEntityTooLarge, ErrAttachmentTooLarge)
+	case errors.Is(err, usecase.ErrUnsupportedMediaType):
+		errorToJSON(c, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType)
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		errorToJSON(c, http.StatusForbidden, ErrQuotaExceeded)
+	case errors.Is(err, usecase.ErrForbidden):
+		errorToJSON(c, http.StatusForbidden, ErrForbidden)
+	case errors.Is(err, usecase.ErrAttachmentNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrAttachmentNotFound)
+	case errors.Is(err, entity.ErrRecordNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+	default:
+		unexpectedErrorHandling(c, controller.Logger, err)
+	}
+}
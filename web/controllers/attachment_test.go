@@ -0,0 +1,477 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidAA = "a1b2c3d4-5e6f-4a7b-8c9d-0e1f2a3b4c5d"
+	uuidAB = "b2c3d4e5-6f7a-4b8c-9d0e-1f2a3b4c5d6e"
+	// テストのコントローラーのファイルサイズと容量の上限
+	testAttachmentMaxSize = 1 << 10
+	testAttachmentQuota   = 4 << 10
+	pngHeader             = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+)
+
+func TestAttachmentController_Upload(t *testing.T) {
+
+	findOwnTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+	}
+	create := func(attachment *mock_repository.MockAttachmentRepository) {
+		attachment.EXPECT().Usage(gomock.Any(), uuidUA).Return(int64(0), nil)
+		attachment.EXPECT().Create(gomock.Any(), gomock.Any(), int64(testAttachmentQuota)).
+			DoAndReturn(func(_ context.Context, a *entity.Attachment, _ int64) error {
+				a.ID = entity.NewNullString(uuidAA)
+				return nil
+			})
+	}
+
+	tests := []testInfo{
+		{
+			name:                      "自分のTaskに画像を添付できる",
+			userid:                    uuidUA,
+			params:                    map[string]string{"id": uuidTA},
+			body:                      pngHeader,
+			prepareMockTaskRepo:       findOwnTask,
+			prepareMockAttachmentRepo: create,
+			wantErr:                   false,
+			wantCode:                  http.StatusOK,
+			wantData:                  newAttachment(uuidAA, uuidUA, "image/png", int64(len(pngHeader))),
+		},
+		{
+			name:   "viewerとして共有されたTaskには添付できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   pngHeader,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleViewer)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:                "許可されていない種類のファイルならStatusUnsupportedMediaType",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			body:                "PK\x03\x04\x14\x00\x00\x00",
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().Usage(gomock.Any(), uuidUA).Return(int64(0), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnsupportedMediaType,
+			wantData: ErrUnsupportedMediaType.Error(),
+		},
+		{
+			name:     "ファイルサイズの上限を超えるならStatusRequestEntityTooLarge",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidTA},
+			body:     pngHeader + strings.Repeat("a", testAttachmentMaxSize),
+			wantErr:  true,
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantData: ErrAttachmentTooLarge.Error(),
+		},
+		{
+			name:                "容量の上限を超えるならErrQuotaExceeded",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			body:                pngHeader,
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().Usage(gomock.Any(), uuidUA).Return(int64(testAttachmentQuota), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrQuotaExceeded.Error(),
+		},
+		{
+			name:                "同時に添付して容量の上限を超えたならErrQuotaExceeded",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			body:                pngHeader,
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().Usage(gomock.Any(), uuidUA).Return(int64(0), nil)
+				attachment.EXPECT().Create(gomock.Any(), gomock.Any(), int64(testAttachmentQuota)).Return(entity.ErrQuotaExceeded)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrQuotaExceeded.Error(),
+		},
+		{
+			name:     "fileフィールドがないならStatusBadRequest",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidTA},
+			body:     "",
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareAttachmentTT(t)
+
+			// httpRequest
+			body, contentType := multipartBody(t, "photo.png", tt.body)
+			context.Request, _ = http.NewRequest("POST", "/task/"+uuidTA+"/attachments", body)
+			context.Request.Header.Set("Content-Type", contentType)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			blob := newMemoryBlobStore()
+			ctrl, attachmentController := prepareMockAttachmentCtrl(t, tt, blob)
+			defer ctrl.Finish()
+
+			attachmentController.Upload(context)
+
+			compareResult(t, w, tt)
+			// 記録できなかったファイルはBlobStoreに残さない
+			if n := blob.len(); tt.wantErr && n != 0 {
+				t.Errorf("blobs (-want +got) =\n- 0\n+ %d", n)
+			}
+		})
+	}
+}
+
+func TestAttachmentController_List(t *testing.T) {
+
+	attachments := []*entity.Attachment{newAttachment(uuidAA, uuidUA, "image/png", 16)}
+
+	tests := []testInfo{
+		{
+			name:   "TaskのAttachmentを取得できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByTask(gomock.Any(), uuidTA).Return(attachments, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: attachments,
+		},
+		{
+			name:   "アクセスできないTaskならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareAttachmentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/"+uuidTA+"/attachments", nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, attachmentController := prepareMockAttachmentCtrl(t, tt, newMemoryBlobStore())
+			defer ctrl.Finish()
+
+			attachmentController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestAttachmentController_Download(t *testing.T) {
+
+	findOwnTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+	}
+
+	tests := []testInfo{
+		{
+			name:                "ファイルの中身をダウンロードできる",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "attachmentid": uuidAA},
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAA).Return(newAttachment(uuidAA, uuidUA, "image/png", int64(len(pngHeader))), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: pngHeader,
+		},
+		{
+			name:                "Attachmentが存在しないならErrAttachmentNotFound",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "attachmentid": uuidAB},
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAB).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrAttachmentNotFound.Error(),
+		},
+		{
+			name:                "BlobStoreにファイルがないならErrAttachmentNotFound",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "attachmentid": uuidAB},
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAB).Return(newAttachment(uuidAB, uuidUA, "image/png", 16), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrAttachmentNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareAttachmentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/"+uuidTA+"/attachments/"+tt.params["attachmentid"], nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			blob := newMemoryBlobStore()
+			blob.Put(context, "attachments/"+uuidAA, strings.NewReader(pngHeader), int64(len(pngHeader)), "image/png")
+			ctrl, attachmentController := prepareMockAttachmentCtrl(t, tt, blob)
+			defer ctrl.Finish()
+
+			attachmentController.Download(context)
+
+			if tt.wantErr {
+				compareResult(t, w, tt)
+				return
+			}
+			if w.Code != tt.wantCode {
+				t.Errorf("Code (-want +got) =\n- %d\n+ %d", tt.wantCode, w.Code)
+			}
+			if got := w.Body.String(); got != tt.wantData {
+				t.Errorf("Body (-want +got) =\n- %q\n+ %q", tt.wantData, got)
+			}
+			want := map[string]string{
+				"Content-Type":        "image/png",
+				"Content-Disposition": `attachment; filename=photo.png`,
+			}
+			for k, v := range want {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s (-want +got) =\n- %s\n+ %s", k, v, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAttachmentController_Delete(t *testing.T) {
+
+	// editorTask はuuidUBのTaskをuuidUAにeditorとして共有する
+	editorTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+		task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+	}
+	editorShare := func(share *mock_repository.MockShareRepository) {
+		share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)}, nil)
+	}
+
+	tests := []testInfo{
+		{
+			name:   "Taskの所有者は他のユーザーが添付したファイルを削除できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "attachmentid": uuidAB},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAB).Return(newAttachment(uuidAB, uuidUB, "image/png", 16), nil)
+				attachment.EXPECT().Delete(gomock.Any(), uuidTA, uuidAB).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:                 "共有されたユーザーは自分が添付したファイルを削除できる",
+			userid:               uuidUA,
+			params:               map[string]string{"id": uuidTA, "attachmentid": uuidAA},
+			prepareMockTaskRepo:  editorTask,
+			prepareMockShareRepo: editorShare,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAA).Return(newAttachment(uuidAA, uuidUA, "image/png", 16), nil)
+				attachment.EXPECT().Delete(gomock.Any(), uuidTA, uuidAA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:                 "共有されたユーザーは他のユーザーが添付したファイルを削除できないのでStatusForbidden",
+			userid:               uuidUA,
+			params:               map[string]string{"id": uuidTA, "attachmentid": uuidAB},
+			prepareMockTaskRepo:  editorTask,
+			prepareMockShareRepo: editorShare,
+			prepareMockAttachmentRepo: func(attachment *mock_repository.MockAttachmentRepository) {
+				attachment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidAB).Return(newAttachment(uuidAB, uuidUB, "image/png", 16), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareAttachmentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/task/"+uuidTA+"/attachments/"+tt.params["attachmentid"], nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, attachmentController := prepareMockAttachmentCtrl(t, tt, newMemoryBlobStore())
+			defer ctrl.Finish()
+
+			attachmentController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+// newAttachment はuidのユーザーがuuidTAのTaskに添付したphoto.pngを作る
+func newAttachment(id, uid, contentType string, size int64) *entity.Attachment {
+	return &entity.Attachment{
+		ID:          entity.NewNullString(id),
+		TaskID:      entity.NewNullString(uuidTA),
+		UserID:      entity.NewNullString(uid),
+		Name:        entity.NewNullString("photo.png"),
+		ContentType: entity.NewNullString(contentType),
+		Size:        size,
+		BlobKey:     entity.NewNullString("attachments/" + id),
+	}
+}
+
+// multipartBody はfileフィールドにcontentを入れたmultipart/form-dataを作る．contentが空の場合はfileフィールドを含めない
+func multipartBody(t *testing.T, filename, content string) (io.Reader, string) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	if content != "" {
+		fw, err := mw.CreateFormFile(attachmentFormField, filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, mw.FormDataContentType()
+}
+
+// memoryBlobStore はメモリにファイルを保存するusecase.BlobStore
+type memoryBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: map[string][]byte{}}
+}
+
+func (s *memoryBlobStore) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = b
+	return nil
+}
+
+func (s *memoryBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *memoryBlobStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func (s *memoryBlobStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.blobs)
+}
+
+func prepareAttachmentTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockAttachmentCtrl(t *testing.T, tt testInfo, blob *memoryBlobStore) (ctrl *gomock.Controller, attachmentController *AttachmentController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	attachmentRepo := mock_repository.NewMockAttachmentRepository(ctrl)
+	if tt.prepareMockAttachmentRepo != nil {
+		tt.prepareMockAttachmentRepo(attachmentRepo)
+	}
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	if tt.prepareMockTaskRepo != nil {
+		tt.prepareMockTaskRepo(taskRepo)
+	}
+	projectRepo := mock_repository.NewMockProjectRepository(ctrl)
+	shareRepo := prepareMockShareRepo(ctrl, tt)
+	membershipRepo := prepareMockMembershipRepo(ctrl, tt)
+
+	attachmentController = NewAttachmentController(attachmentRepo, blob, taskRepo, projectRepo, shareRepo, membershipRepo, testAttachmentMaxSize, testAttachmentQuota, logging.Discard())
+	return
+}
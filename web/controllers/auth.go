@@ -1 +1,73 @@
 package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+// cookieMaxAge はログイン時に発行するcookieの有効期限(秒)
+const cookieMaxAge = 60 * 60 * 24 * 7
+
+type AuthController struct {
+	Interactor *usecase.AuthInteractor
+	Cookie     CookieConfig
+	Logger     *slog.Logger
+}
+
+func NewAuthController(user repository.UserRepository, attempt repository.LoginAttemptRepository, cookie CookieConfig, logger *slog.Logger) *AuthController {
+	return &AuthController{
+		Interactor: usecase.NewAuthInteractor(user, attempt, logger),
+		Cookie:     cookie,
+		Logger:     logger,
+	}
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Login is the Handler for POST /login
+func (controller *AuthController) Login(c Context) {
+	var req loginRequest
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	user, err := controller.Interactor.Login(c, req.Email, req.Password)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidUser) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, usecase.ErrLoginFailed) {
+			errorToJSON(c, http.StatusUnauthorized, ErrLoginFailed)
+			return
+		}
+		var locked *usecase.AccountLockedError
+		if errors.As(err, &locked) {
+			setRetryAfter(c, locked.RetryAfter)
+			errorToJSON(c, http.StatusTooManyRequests, ErrTooManyRequests)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	setCookie(c, controller.Cookie, "id", user.ID.String(), cookieMaxAge)
+	c.JSON(http.StatusOK, user)
+}
+
+// setRetryAfter はRetry-Afterヘッダーに秒数(切り上げ)を設定する
+func setRetryAfter(c Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestAuthController_Login(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name: "正しくログインできる",
+			body: `{
+				"email":"example@example.com",
+				"password":"password"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "example@example.com").Return(encryptedUser(t), nil)
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				attempt.EXPECT().FindByAccount(gomock.Any(), "example@example.com").Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewUser(uuidUA, "username", "", "example@example.com"),
+		},
+		{
+			name: "以前に失敗していてもログインできれば記録を削除する",
+			body: `{
+				"email":"example@example.com",
+				"password":"password"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "example@example.com").Return(encryptedUser(t), nil)
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				attempt.EXPECT().FindByAccount(gomock.Any(), "example@example.com").
+					Return(&entity.LoginAttempt{Account: "example@example.com", Failures: 2}, nil)
+				attempt.EXPECT().Delete(gomock.Any(), "example@example.com").Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewUser(uuidUA, "username", "", "example@example.com"),
+		},
+		{
+			name: "passwordが違うならStatusUnauthorized",
+			body: `{
+				"email":"example@example.com",
+				"password":"wrong"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "example@example.com").Return(encryptedUser(t), nil)
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				attempt.EXPECT().FindByAccount(gomock.Any(), "example@example.com").Return(nil, entity.ErrRecordNotFound)
+				attempt.EXPECT().Fail(gomock.Any(), "example@example.com", gomock.Any(), gomock.Any()).DoAndReturn(failAttempt(1))
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrLoginFailed.Error(),
+		},
+		{
+			name: "存在しないemailならStatusUnauthorized",
+			body: `{
+				"email":"unknown@example.com",
+				"password":"password"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "unknown@example.com").Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				attempt.EXPECT().FindByAccount(gomock.Any(), "unknown@example.com").Return(nil, entity.ErrRecordNotFound)
+				attempt.EXPECT().Fail(gomock.Any(), "unknown@example.com", gomock.Any(), gomock.Any()).DoAndReturn(failAttempt(1))
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrLoginFailed.Error(),
+		},
+		{
+			name: "失敗回数が閾値に達したらStatusTooManyRequests",
+			body: `{
+				"email":"example@example.com",
+				"password":"wrong"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "example@example.com").Return(encryptedUser(t), nil)
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				attempt.EXPECT().FindByAccount(gomock.Any(), "example@example.com").
+					Return(&entity.LoginAttempt{Account: "example@example.com", Failures: 4}, nil)
+				attempt.EXPECT().Fail(gomock.Any(), "example@example.com", gomock.Any(), gomock.Any()).DoAndReturn(failAttempt(5))
+			},
+			wantErr:  true,
+			wantCode: http.StatusTooManyRequests,
+			wantData: ErrTooManyRequests.Error(),
+		},
+		{
+			name: "ロック中ならpasswordを確認せずにStatusTooManyRequests",
+			body: `{
+				"email":"example@example.com",
+				"password":"password"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+				lockedUntil := time.Now().Add(time.Minute)
+				attempt.EXPECT().FindByAccount(gomock.Any(), "example@example.com").
+					Return(&entity.LoginAttempt{Account: "example@example.com", Failures: 5, LockedUntil: &lockedUntil}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusTooManyRequests,
+			wantData: ErrTooManyRequests.Error(),
+		},
+		{
+			name: "Requestにpasswordが含まれていないならStatusBadRequest",
+			body: `{
+				"email":"example@example.com"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "RequestBodyがJSONでないならStatusBadRequest",
+			body: `aaaaa`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			prepareMockLoginAttemptRepo: func(attempt *mock_repository.MockLoginAttemptRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareAuthTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(tt.body))
+
+			// モック,コントローラーの準備
+			ctrl, authController := prepareMockAuthCtrl(t, tt)
+			defer ctrl.Finish()
+
+			authController.Login(context)
+
+			compareResult(t, w, tt)
+
+			// ロックされた場合はRetry-Afterを返す
+			if tt.wantCode == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Errorf("Retry-After header is empty")
+			}
+			// ログインできた場合はcookieを発行する
+			if tt.wantCode == http.StatusOK {
+				cookies := w.Result().Cookies()
+				if len(cookies) != 1 || cookies[0].Value != uuidUA {
+					t.Fatalf("Cookie (-want +got) =\n- %s\n+ %v", uuidUA, cookies)
+				}
+				if !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+					t.Errorf("Cookie attributes = HttpOnly:%v SameSite:%v", cookies[0].HttpOnly, cookies[0].SameSite)
+				}
+			}
+		})
+	}
+}
+
+// encryptedUser はpasswordがハッシュ化されたUserを返す
+func encryptedUser(t *testing.T) *entity.User {
+	t.Helper()
+
+	user := entity.NewUser(uuidUA, "username", "password", "example@example.com")
+	err := user.EncryptPassword()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// failAttempt はfailures回目の失敗としてLoginAttemptRepository.Failの結果を返す
+func failAttempt(failures int) func(context.Context, string, time.Time, func(int) time.Duration) (*entity.LoginAttempt, error) {
+	return func(_ context.Context, account string, now time.Time, lock func(int) time.Duration) (*entity.LoginAttempt, error) {
+		attempt := &entity.LoginAttempt{Account: account, Failures: failures}
+		attempt.Lock(now, lock(failures))
+		return attempt, nil
+	}
+}
+
+func prepareAuthTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockAuthCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, authController *AuthController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	tt.prepareMockUserRepo(userRepo)
+	attemptRepo := mock_repository.NewMockLoginAttemptRepository(ctrl)
+	tt.prepareMockLoginAttemptRepo(attemptRepo)
+
+	authController = NewAuthController(userRepo, attemptRepo, DefaultCookieConfig, logging.Discard())
+	return
+}
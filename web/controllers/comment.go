@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type CommentController struct {
+	Interactor *usecase.CommentInteractor
+	Logger     *slog.Logger
+}
+
+func NewCommentController(comment repository.CommentRepository, task repository.TaskRepository, project repository.ProjectRepository, share repository.ShareRepository, membership repository.MembershipRepository, logger *slog.Logger) *CommentController {
+	return &CommentController{
+		Interactor: usecase.NewCommentInteractor(comment, task, project, share, membership, logger),
+		Logger:     logger,
+	}
+}
+
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// Create is the Handler for POST /task/:id/comments
+func (controller *CommentController) Create(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req commentRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	comment := &entity.Comment{
+		TaskID: entity.NewNullString(tid),
+		UserID: entity.NewNullString(uid),
+		Body:   entity.NewNullString(req.Body),
+	}
+	err = controller.Interactor.Create(c, comment)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// List is the Handler for GET /task/:id/comments
+// クエリパラメータlimit(デフォルトは20件)とoffsetで取得する範囲を指定する
+func (controller *CommentController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	page, err := getPageFromQuery(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	comments, err := controller.Interactor.List(c, tid, uid, page)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// Update is the Handler for PUT /task/:id/comments/:commentid
+func (controller *CommentController) Update(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("commentid")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req commentRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	comment := &entity.Comment{
+		ID:     entity.NewNullString(id),
+		TaskID: entity.NewNullString(tid),
+		Body:   entity.NewNullString(req.Body),
+	}
+	err = controller.Interactor.Update(c, comment, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// Delete is the Handler for DELETE /task/:id/comments/:commentid
+func (controller *CommentController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("commentid")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, tid, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+// errorHandling はCommentの操作で共通のエラーをレスポンスにする
+func (controller *CommentController) errorHandling(c Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidComment), errors.Is(err, usecase.ErrInvalidPage):
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+	case errors.Is(err, usecase.ErrForbidden):
+		errorToJSON(c, http.StatusForbidden, ErrForbidden)
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrCommentNotFound)
+	case errors.Is(err, entity.ErrRecordNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+	default:
+		unexpectedErrorHandling(c, controller.Logger, err)
+	}
+}
@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidCA = "8c3f1a2b-6d4e-4b7a-9e1f-0a2b3c4d5e6f"
+	uuidCB = "9d4a2b3c-7e5f-4c8b-8f2a-1b3c4d5e6f70"
+)
+
+func TestCommentController_Create(t *testing.T) {
+
+	findOwnTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+	}
+	// sharedTask はuuidUBのTaskをuuidUAにroleの権限で共有する
+	sharedTask := func(role entity.Role) (func(*mock_repository.MockTaskRepository), func(*mock_repository.MockShareRepository)) {
+		return func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+			}, func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, role)}, nil)
+			}
+	}
+	create := func(comment *mock_repository.MockCommentRepository) {
+		comment.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, c *entity.Comment) error {
+				c.ID = entity.NewNullString(uuidCA)
+				return nil
+			})
+		comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCA).Return(newComment(uuidCA, uuidUA, "hello"), nil)
+	}
+	editorTask, editorShare := sharedTask(entity.RoleEditor)
+	viewerTask, viewerShare := sharedTask(entity.RoleViewer)
+
+	tests := []testInfo{
+		{
+			name:                   "自分のTaskにCommentを書ける",
+			userid:                 uuidUA,
+			params:                 map[string]string{"id": uuidTA},
+			body:                   `{"body":"hello"}`,
+			prepareMockTaskRepo:    findOwnTask,
+			prepareMockCommentRepo: create,
+			wantErr:                false,
+			wantCode:               http.StatusOK,
+			wantData:               newComment(uuidCA, uuidUA, "hello"),
+		},
+		{
+			name:                   "editorとして共有されたTaskにCommentを書ける",
+			userid:                 uuidUA,
+			params:                 map[string]string{"id": uuidTA},
+			body:                   `{"body":"hello"}`,
+			prepareMockTaskRepo:    editorTask,
+			prepareMockShareRepo:   editorShare,
+			prepareMockCommentRepo: create,
+			wantErr:                false,
+			wantCode:               http.StatusOK,
+			wantData:               newComment(uuidCA, uuidUA, "hello"),
+		},
+		{
+			name:                 "viewerとして共有されたTaskにはCommentを書けないのでStatusForbidden",
+			userid:               uuidUA,
+			params:               map[string]string{"id": uuidTA},
+			body:                 `{"body":"hello"}`,
+			prepareMockTaskRepo:  viewerTask,
+			prepareMockShareRepo: viewerShare,
+			wantErr:              true,
+			wantCode:             http.StatusForbidden,
+			wantData:             ErrForbidden.Error(),
+		},
+		{
+			name:     "本文が空白のみならStatusBadRequest",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidTA},
+			body:     `{"body":"  "}`,
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "Taskが存在しないならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"body":"hello"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareCommentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/task/"+uuidTA+"/comments", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, commentController := prepareMockCommentCtrl(t, tt)
+			defer ctrl.Finish()
+
+			commentController.Create(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestCommentController_List(t *testing.T) {
+
+	findOwnTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+	}
+	comments := []*entity.Comment{newComment(uuidCB, uuidUB, "second")}
+
+	tests := []testInfo{
+		{
+			name:                "limitとoffsetで取得する範囲を指定できる",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			query:               "limit=1&offset=1",
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByTask(gomock.Any(), uuidTA, entity.Page{Limit: 1, Offset: 1}).Return(comments, int64(2), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.CommentPage{Total: 2, Limit: 1, Offset: 1, Comments: comments},
+		},
+		{
+			name:                "limitを省略した場合はentity.DefaultPageLimit件取得する",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByTask(gomock.Any(), uuidTA, entity.Page{Limit: entity.DefaultPageLimit}).Return([]*entity.Comment{}, int64(0), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.CommentPage{Limit: entity.DefaultPageLimit, Comments: []*entity.Comment{}},
+		},
+		{
+			name:     "limitが上限を超えるならStatusBadRequest",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidTA},
+			query:    "limit=101",
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:     "offsetが数でないならStatusBadRequest",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidTA},
+			query:    "offset=a",
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "アクセスできないTaskならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareCommentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/"+uuidTA+"/comments?"+tt.query, nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, commentController := prepareMockCommentCtrl(t, tt)
+			defer ctrl.Finish()
+
+			commentController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestCommentController_Update(t *testing.T) {
+
+	findOwnTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+	}
+	edited := newComment(uuidCA, uuidUA, "edited")
+	edited.Edited = true
+
+	tests := []testInfo{
+		{
+			name:                "自分のCommentを編集できる",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "commentid": uuidCA},
+			body:                `{"body":"edited"}`,
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				gomock.InOrder(
+					comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCA).Return(newComment(uuidCA, uuidUA, "hello"), nil),
+					comment.EXPECT().Update(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, c *entity.Comment) error {
+							c.Edited = true
+							return nil
+						}),
+					comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCA).Return(edited, nil),
+				)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: edited,
+		},
+		{
+			name:                "Taskの所有者でも他のユーザーのCommentは編集できないのでStatusForbidden",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "commentid": uuidCB},
+			body:                `{"body":"edited"}`,
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCB).Return(newComment(uuidCB, uuidUB, "hello"), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:                "Commentが存在しないならErrCommentNotFound",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA, "commentid": uuidCA},
+			body:                `{"body":"edited"}`,
+			prepareMockTaskRepo: findOwnTask,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrCommentNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareCommentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task/"+uuidTA+"/comments/"+tt.params["commentid"], bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, commentController := prepareMockCommentCtrl(t, tt)
+			defer ctrl.Finish()
+
+			commentController.Update(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestCommentController_Delete(t *testing.T) {
+
+	// editorTask はuuidUBのTaskをuuidUAにeditorとして共有する
+	editorTask := func(task *mock_repository.MockTaskRepository) {
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+		task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+	}
+	editorShare := func(share *mock_repository.MockShareRepository) {
+		share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)}, nil)
+	}
+
+	tests := []testInfo{
+		{
+			name:   "Taskの所有者は他のユーザーのCommentを削除できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "commentid": uuidCB},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCB).Return(newComment(uuidCB, uuidUB, "spam"), nil)
+				comment.EXPECT().Delete(gomock.Any(), uuidTA, uuidCB).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:                 "共有されたユーザーは自分のCommentを削除できる",
+			userid:               uuidUA,
+			params:               map[string]string{"id": uuidTA, "commentid": uuidCA},
+			prepareMockTaskRepo:  editorTask,
+			prepareMockShareRepo: editorShare,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCA).Return(newComment(uuidCA, uuidUA, "hello"), nil)
+				comment.EXPECT().Delete(gomock.Any(), uuidTA, uuidCA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:                 "共有されたユーザーは他のユーザーのCommentを削除できないのでStatusForbidden",
+			userid:               uuidUA,
+			params:               map[string]string{"id": uuidTA, "commentid": uuidCB},
+			prepareMockTaskRepo:  editorTask,
+			prepareMockShareRepo: editorShare,
+			prepareMockCommentRepo: func(comment *mock_repository.MockCommentRepository) {
+				comment.EXPECT().FindByID(gomock.Any(), uuidTA, uuidCB).Return(newComment(uuidCB, uuidUB, "hello"), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareCommentTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/task/"+uuidTA+"/comments/"+tt.params["commentid"], nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, commentController := prepareMockCommentCtrl(t, tt)
+			defer ctrl.Finish()
+
+			commentController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+// newComment はuidのユーザーが書いたCommentを作る．Authorのemailはユーザーごとに異なる
+func newComment(id, uid, body string) *entity.Comment {
+	return &entity.Comment{
+		ID:     entity.NewNullString(id),
+		TaskID: entity.NewNullString(uuidTA),
+		UserID: entity.NewNullString(uid),
+		Author: entity.NewUser(uid, "username", "", uid+"@example.com"),
+		Body:   entity.NewNullString(body),
+	}
+}
+
+func prepareCommentTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockCommentCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, commentController *CommentController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	commentRepo := mock_repository.NewMockCommentRepository(ctrl)
+	if tt.prepareMockCommentRepo != nil {
+		tt.prepareMockCommentRepo(commentRepo)
+	}
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	if tt.prepareMockTaskRepo != nil {
+		tt.prepareMockTaskRepo(taskRepo)
+	}
+	projectRepo := mock_repository.NewMockProjectRepository(ctrl)
+	shareRepo := prepareMockShareRepo(ctrl, tt)
+	membershipRepo := prepareMockMembershipRepo(ctrl, tt)
+
+	commentController = NewCommentController(commentRepo, taskRepo, projectRepo, shareRepo, membershipRepo, logging.Discard())
+	return
+}
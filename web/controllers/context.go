@@ -1,10 +1,27 @@
 package controllers
 
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
 // Context is a interface for gin.Context
+// context.Context としてusecaseに渡し，トレースなどを伝搬させる
 type Context interface {
+	context.Context
 	Param(key string) string
+	Query(key string) string
+	QueryArray(key string) []string
 	JSON(code int, obj interface{})
+	Header(key, value string)
+	GetHeader(key string) string
+	AbortWithStatus(code int)
 	Cookie(name string) (string, error)
 	SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool)
+	SetSameSite(samesite http.SameSite)
 	ShouldBindJSON(obj interface{}) error
+	FormFile(name string) (*multipart.FileHeader, error)
+	DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string)
 }
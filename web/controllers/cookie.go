@@ -0,0 +1,25 @@
+package controllers
+
+import "net/http"
+
+// CookieConfig はcookieを発行するときの属性である
+type CookieConfig struct {
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// DefaultCookieConfig はJavaScriptから読めず，クロスサイトのPOSTなどでは送信されないcookieを発行する
+var DefaultCookieConfig = CookieConfig{
+	Path:     "/",
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// setCookie はcfgの属性でcookieを発行する
+func setCookie(c Context, cfg CookieConfig, name, value string, maxAge int) {
+	c.SetSameSite(cfg.SameSite)
+	c.SetCookie(name, value, maxAge, cfg.Path, cfg.Domain, cfg.Secure, cfg.HttpOnly)
+}
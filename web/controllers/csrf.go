@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	// CSRFTokenCookie はCSRFトークンを保持するcookieの名前
+	CSRFTokenCookie = "csrf_token"
+	// CSRFTokenHeader はクライアントがCSRFトークンを送るヘッダーの名前
+	CSRFTokenHeader = "X-CSRF-Token"
+)
+
+type CSRFController struct {
+	Cookie CookieConfig
+	Logger *slog.Logger
+}
+
+func NewCSRFController(cookie CookieConfig, logger *slog.Logger) *CSRFController {
+	// 同一オリジンのSPAがcookieから読めるようにする
+	cookie.HttpOnly = false
+	return &CSRFController{
+		Cookie: cookie,
+		Logger: logger,
+	}
+}
+
+type csrfResponse struct {
+	Token string `json:"token"`
+}
+
+// Get is the Handler for GET /csrf
+// 新しいCSRFトークンをcookieに設定し，別オリジンのSPAでも読めるようにbodyでも返す
+func (controller *CSRFController) Get(c Context) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	setCookie(c, controller.Cookie, CSRFTokenCookie, token, cookieMaxAge)
+	c.JSON(http.StatusOK, csrfResponse{Token: token})
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestCSRFController_Get(t *testing.T) {
+	context, w := prepareUserTT(t)
+	context.Request, _ = http.NewRequest("GET", "/csrf", nil)
+
+	csrfController := NewCSRFController(DefaultCookieConfig, logging.Discard())
+	csrfController.Get(context)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Code (-want +got) =\n- %d\n+ %d", http.StatusOK, w.Code)
+	}
+	var res csrfResponse
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != CSRFTokenCookie || cookies[0].Value != res.Token || res.Token == "" {
+		t.Fatalf("Cookie = %v, token = %q", cookies, res.Token)
+	}
+	// SPAがcookieから読めるようにする
+	if cookies[0].HttpOnly {
+		t.Errorf("csrf cookie must not be HttpOnly")
+	}
+}
@@ -10,6 +10,22 @@ var (
 	ErrInternalServerError = errors.New("internal server error")
 	// ErrUnauthorized is http.StatusUnauthorized
 	ErrUnauthorized = errors.New("unauthorized")
+	// ErrTimeout is http.StatusGatewayTimeout
+	ErrTimeout = errors.New("timeout")
+	// ErrRequestCanceled is statusClientClosedRequest
+	ErrRequestCanceled = errors.New("request canceled")
+	// ErrTooManyRequests is http.StatusTooManyRequests
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrForbidden is http.StatusForbidden
+	ErrForbidden = errors.New("forbidden")
+	// ErrPreconditionFailed is http.StatusPreconditionFailed
+	ErrPreconditionFailed = errors.New("resource has been modified")
+)
+
+//Errors of auth
+var (
+	// ErrLoginFailed email or password is wrong error
+	ErrLoginFailed = errors.New("invalid email or password")
 )
 
 //Errors of user
@@ -28,4 +44,74 @@ var (
 	ErrTaskNotFound = errors.New("task not found")
 	// ErrInvalidTask invalid task request error(private)
 	ErrInvalidTask = errors.New("invalid task")
+	// ErrParentTaskNotFound parent task not found error
+	ErrParentTaskNotFound = errors.New("parent task not found")
+	// ErrTaskCycle task cannot be a subtask of itself or its subtasks error
+	ErrTaskCycle = errors.New("task cannot be a subtask of itself or its subtasks")
+	// ErrTaskTooDeep subtasks are nested too deeply error
+	ErrTaskTooDeep = errors.New("subtasks are nested too deeply")
+	// ErrInvalidAssignee assignee does not exist or cannot access the task error
+	ErrInvalidAssignee = errors.New("assignee must be a user who can access the task")
+	// ErrBatchAborted is http.StatusFailedDependency
+	ErrBatchAborted = errors.New("operation was not applied because another operation failed")
+)
+
+//Errors of reminder
+var (
+	// ErrReminderNotFound reminder not found error
+	ErrReminderNotFound = errors.New("reminder not found")
+)
+
+//Errors of share
+var (
+	// ErrShareNotFound share not found error
+	ErrShareNotFound = errors.New("share not found")
+	// ErrDuplicatedShare already shared with the user error
+	ErrDuplicatedShare = errors.New("share already exists")
+	// ErrInviteeNotFound invited user not found error
+	ErrInviteeNotFound = errors.New("invitee not found")
+)
+
+//Errors of workspace
+var (
+	// ErrWorkspaceNotFound workspace not found error
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrMemberNotFound workspace member not found error
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrDuplicatedMember already a member of the workspace error
+	ErrDuplicatedMember = errors.New("member already exists")
+)
+
+//Errors of comment
+var (
+	// ErrCommentNotFound comment not found error
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
+//Errors of attachment
+var (
+	// ErrAttachmentNotFound attachment not found error
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge is http.StatusRequestEntityTooLarge
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	// ErrUnsupportedMediaType is http.StatusUnsupportedMediaType
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrQuotaExceeded storage quota exceeded error
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+)
+
+//Errors of project
+var (
+	// ErrProjectNotFound project not found error
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+//Errors of tag
+var (
+	// ErrTagNotFound tag not found error
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrDuplicatedTag tag name already exists error
+	ErrDuplicatedTag = errors.New("tag already exists")
+	// ErrTaskOrTagNotFound task or tag not found error
+	ErrTaskOrTagNotFound = errors.New("task or tag not found")
 )
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type ProjectController struct {
+	Interactor *usecase.ProjectInteractor
+	Logger     *slog.Logger
+}
+
+func NewProjectController(project repository.ProjectRepository, task repository.TaskRepository, share repository.ShareRepository, membership repository.MembershipRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *ProjectController {
+	return &ProjectController{
+		Interactor: usecase.NewProjectInteractor(project, task, share, membership, activity, transactor, logger),
+		Logger:     logger,
+	}
+}
+
+// Create is the Handler for POST /project
+func (controller *ProjectController) Create(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	project, err := getProjectFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	project.UserID.Set(uid)
+
+	err = controller.Interactor.Create(c, project)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidProject) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// List is the Handler for GET /project
+func (controller *ProjectController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	projects, err := controller.Interactor.List(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetByID is the Handler for GET /project/:id
+func (controller *ProjectController) GetByID(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	project, err := controller.Interactor.GetByID(c, id, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrProjectNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// Update is the Handler for PUT /project/:id
+func (controller *ProjectController) Update(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	project, err := getProjectFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	project.ID.Set(id)
+	project.UserID.Set(uid)
+
+	err = controller.Interactor.Update(c, project)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidProject) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrProjectNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+// Delete is the Handler for DELETE /project/:id
+// クエリパラメータtasksがmove(デフォルト)ならTaskをインボックスに移動し，deleteならTaskも削除する
+func (controller *ProjectController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var deleteTasks bool
+	switch c.Query("tasks") {
+	case "", "move":
+		deleteTasks = false
+	case "delete":
+		deleteTasks = true
+	default:
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, id, uid, deleteTasks)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrProjectNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+func getProjectFromBody(c Context) (project *entity.Project, err error) {
+	err = c.ShouldBindJSON(&project)
+	return
+}
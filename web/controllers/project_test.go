@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidPA = "0c4b8a6e-2f1d-4c3b-9e8a-7d6c5b4a3f2e"
+)
+
+func TestProjectController_Create(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "正しくプロジェクトを作成できる",
+			userid: uuidUA,
+			body: `{
+				"name":"work"
+			}`,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().Create(gomock.Any(), entity.NewProject("", "work", uuidUA)).
+					DoAndReturn(func(_ context.Context, project *entity.Project) error {
+						project.ID.Set(uuidPA)
+						project.CreatedAt = time.Unix(100, 0)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewProject(uuidPA, "work", uuidUA),
+		},
+		{
+			name:   "Requestにnameが含まれていないならStatusBadRequest",
+			userid: uuidUA,
+			body:   `{}`,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareProjectTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/project", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, projectController := prepareMockProjectCtrl(t, tt)
+			defer ctrl.Finish()
+
+			projectController.Create(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestProjectController_List(t *testing.T) {
+
+	projectA := entity.NewProject(uuidPA, "work", uuidUA)
+	projectA.TaskCount = 3
+	projectA.CompletedCount = 1
+
+	tests := []testInfo{
+		{
+			name:   "完了数を含めてプロジェクトの一覧を取得できる",
+			userid: uuidUA,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Project{projectA}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Project{projectA},
+		},
+		{
+			name:   "共有されたプロジェクトを権限とともに自分のプロジェクトの後に取得する",
+			userid: uuidUA,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Project{}, nil)
+				project.EXPECT().FindByIDs(gomock.Any(), []string{uuidPA}).Return([]*entity.Project{entity.NewProject(uuidPA, "shared", uuidUB)}, nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareProject, uuidPA, entity.RoleEditor)}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Project{{ID: entity.NewNullString(uuidPA), Name: entity.NewNullString("shared"), Role: entity.RoleEditor}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareProjectTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/project", nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, projectController := prepareMockProjectCtrl(t, tt)
+			defer ctrl.Finish()
+
+			projectController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestProjectController_Delete(t *testing.T) {
+
+	inProject := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+	inProject.ProjectID = entity.NewNullString(uuidPA)
+	subtask := entity.NewTask(uuidTB, "sub", "", uuidUA, "2020-12-27")
+	subtask.ParentID = entity.NewNullString(uuidTA)
+
+	tests := []testInfo{
+		{
+			name:   "デフォルトではタスクをインボックスに移動してActivityに記録する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().Delete(gomock.Any(), uuidPA, uuidUA, false).Return([]*entity.Task{inProject}, nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				want := entity.Changes{{Field: "project_id", Before: uuidPA, After: nil}}
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *entity.Activity) error {
+						if a.EntityID.String() != uuidTA || a.Action != entity.ActionUpdate || cmp.Diff(want, a.Changes) != "" {
+							t.Errorf("Activity got = %v", a)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "tasks=deleteならサブタスクも含めてタスクを削除してActivityに記録する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			query:  "tasks=delete",
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().Delete(gomock.Any(), uuidPA, uuidUA, true).Return([]*entity.Task{inProject, subtask}, nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				for _, id := range []string{uuidTA, uuidTB} {
+					activity.EXPECT().Create(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, a *entity.Activity) error {
+							if a.EntityID.String() != id || a.Action != entity.ActionDelete {
+								t.Errorf("Activity got = %v", a)
+							}
+							return nil
+						})
+				}
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "Activityの記録に失敗すれば削除を取り消してStatusInternalServerError",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			query:  "tasks=delete",
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().Delete(gomock.Any(), uuidPA, uuidUA, true).Return([]*entity.Task{inProject}, nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("activity unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 削除もロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name:   "tasksが不正ならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			query:  "tasks=archive",
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "DBにプロジェクトがないときはErrProjectNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().Delete(gomock.Any(), uuidPA, uuidUA, false).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrProjectNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareProjectTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/project/"+tt.params["id"]+"?"+tt.query, nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, projectController := prepareMockProjectCtrl(t, tt)
+			defer ctrl.Finish()
+
+			projectController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func prepareProjectTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockProjectCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, projectController *ProjectController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	projectRepo := mock_repository.NewMockProjectRepository(ctrl)
+	tt.prepareMockProjectRepo(projectRepo)
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	if tt.prepareMockTaskRepo != nil {
+		tt.prepareMockTaskRepo(taskRepo)
+	}
+	shareRepo := prepareMockShareRepo(ctrl, tt)
+	membershipRepo := prepareMockMembershipRepo(ctrl, tt)
+
+	activityRepo := prepareMockActivityRepo(ctrl, tt)
+	transactor := prepareMockTransactor(ctrl, tt)
+
+	projectController = NewProjectController(projectRepo, taskRepo, shareRepo, membershipRepo, activityRepo, transactor, logging.Discard())
+	return
+}
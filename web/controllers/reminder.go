@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type ReminderController struct {
+	Interactor *usecase.ReminderInteractor
+	Logger     *slog.Logger
+}
+
+func NewReminderController(reminder repository.ReminderRepository, task repository.TaskRepository, user repository.UserRepository, logger *slog.Logger) *ReminderController {
+	return &ReminderController{
+		Interactor: usecase.NewReminderInteractor(reminder, task, user, logger),
+		Logger:     logger,
+	}
+}
+
+// Create is the Handler for POST /task/:id/reminder
+// remind_at(RFC 3339)で通知する時刻を，before(30m, 1hなど)で期限の何前に通知するかを指定する
+func (controller *ReminderController) Create(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	reminder, err := getReminderFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	reminder.TaskID.Set(tid)
+	reminder.UserID.Set(uid)
+
+	err = controller.Interactor.Create(c, reminder)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidReminder) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminder)
+}
+
+// List is the Handler for GET /task/:id/reminder
+func (controller *ReminderController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	reminders, err := controller.Interactor.List(c, tid, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, reminders)
+}
+
+// Delete is the Handler for DELETE /task/:id/reminder/:reminderid
+func (controller *ReminderController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("reminderid")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, id, tid, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrReminderNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+func getReminderFromBody(c Context) (reminder *entity.Reminder, err error) {
+	err = c.ShouldBindJSON(&reminder)
+	return
+}
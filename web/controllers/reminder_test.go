@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidRA = "5a1e2b3c-4d5e-4f60-8a7b-9c0d1e2f3a4b"
+)
+
+func TestReminderController_Create(t *testing.T) {
+
+	tokyo := func(user *mock_repository.MockUserRepository) {
+		u := entity.NewUser(uuidUA, "username", "", "user@example.com")
+		u.TimeZone = entity.NewNullString("Asia/Tokyo")
+		user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+	}
+	findTask := func(task *mock_repository.MockTaskRepository) {
+		t := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-06")
+		t.DueTime = entity.NewNullString("09:00")
+		task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(t, nil)
+	}
+	create := func(reminder *mock_repository.MockReminderRepository) {
+		reminder.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, r *entity.Reminder) error {
+				r.ID = entity.NewNullString(uuidRA)
+				return nil
+			})
+	}
+
+	tests := []testInfo{
+		{
+			name:                    "期限の何前に通知するかを指定するとユーザーのタイムゾーンで通知する時刻を計算する",
+			userid:                  uuidUA,
+			params:                  map[string]string{"id": uuidTA},
+			body:                    `{"before":"90m"}`,
+			prepareMockUserRepo:     tokyo,
+			prepareMockTaskRepo:     findTask,
+			prepareMockReminderRepo: create,
+			wantErr:                 false,
+			wantCode:                http.StatusOK,
+			wantData: &entity.Reminder{
+				ID:       entity.NewNullString(uuidRA),
+				TaskID:   entity.NewNullString(uuidTA),
+				RemindAt: time.Date(2020, 12, 5, 22, 30, 0, 0, time.UTC),
+				Before:   entity.NewNullString("1h30m0s"),
+			},
+		},
+		{
+			name:                    "通知する時刻を指定できる",
+			userid:                  uuidUA,
+			params:                  map[string]string{"id": uuidTA},
+			body:                    `{"remind_at":"2020-12-05T20:00:00+09:00"}`,
+			prepareMockUserRepo:     tokyo,
+			prepareMockTaskRepo:     findTask,
+			prepareMockReminderRepo: create,
+			wantErr:                 false,
+			wantCode:                http.StatusOK,
+			wantData: &entity.Reminder{
+				ID:       entity.NewNullString(uuidRA),
+				TaskID:   entity.NewNullString(uuidTA),
+				RemindAt: time.Date(2020, 12, 5, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:   "通知する時刻と期限の何前かを両方指定したならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"remind_at":"2020-12-05T20:00:00+09:00","before":"1h"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "どちらも指定していないならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "期限の何前かが負ならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"before":"-1h"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "Taskが存在しないならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"before":"1h"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "Cookieが空ならStatusUnauthorized",
+			params: map[string]string{"id": uuidTA},
+			body:   `{"before":"1h"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareReminderTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/task/"+uuidTA+"/reminder", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, reminderController := prepareMockReminderCtrl(t, tt)
+			defer ctrl.Finish()
+
+			reminderController.Create(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestReminderController_List(t *testing.T) {
+
+	sentAt := time.Date(2020, 12, 5, 0, 0, 0, 0, time.UTC)
+	reminders := []*entity.Reminder{
+		{
+			ID:       entity.NewNullString(uuidRA),
+			TaskID:   entity.NewNullString(uuidTA),
+			UserID:   entity.NewNullString(uuidUA),
+			RemindAt: sentAt,
+			SentAt:   &sentAt,
+		},
+	}
+
+	tests := []testInfo{
+		{
+			name:   "TaskのReminderの一覧を取得できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-06"), nil)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().FindByTask(gomock.Any(), uuidTA, uuidUA).Return(reminders, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: reminders,
+		},
+		{
+			name:   "Taskが存在しないならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareReminderTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/"+uuidTA+"/reminder", nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, reminderController := prepareMockReminderCtrl(t, tt)
+			defer ctrl.Finish()
+
+			reminderController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestReminderController_Delete(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "正しくReminderを削除できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "reminderid": uuidRA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().Delete(gomock.Any(), uuidRA, uuidTA, uuidUA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "Reminderが存在しないならErrReminderNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "reminderid": uuidRA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().Delete(gomock.Any(), uuidRA, uuidTA, uuidUA).Return(entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrReminderNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareReminderTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/task/"+uuidTA+"/reminder/"+uuidRA, nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, reminderController := prepareMockReminderCtrl(t, tt)
+			defer ctrl.Finish()
+
+			reminderController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func prepareReminderTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockReminderCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, reminderController *ReminderController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	reminderRepo := mock_repository.NewMockReminderRepository(ctrl)
+	tt.prepareMockReminderRepo(reminderRepo)
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	tt.prepareMockTaskRepo(taskRepo)
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	if tt.prepareMockUserRepo != nil {
+		tt.prepareMockUserRepo(userRepo)
+	}
+
+	reminderController = NewReminderController(reminderRepo, taskRepo, userRepo, logging.Discard())
+	return
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type ShareController struct {
+	Interactor *usecase.ShareInteractor
+	Logger     *slog.Logger
+}
+
+func NewShareController(share repository.ShareRepository, task repository.TaskRepository, project repository.ProjectRepository, user repository.UserRepository, membership repository.MembershipRepository, logger *slog.Logger) *ShareController {
+	return &ShareController{
+		Interactor: usecase.NewShareInteractor(share, task, project, user, membership, logger),
+		Logger:     logger,
+	}
+}
+
+type inviteRequest struct {
+	Email string      `json:"email"`
+	Role  entity.Role `json:"role"`
+}
+
+// InviteTask is the Handler for POST /task/:id/share
+func (controller *ShareController) InviteTask(c Context) {
+	controller.invite(c, entity.ShareTask, ErrTaskNotFound)
+}
+
+// InviteProject is the Handler for POST /project/:id/share
+func (controller *ShareController) InviteProject(c Context) {
+	controller.invite(c, entity.ShareProject, ErrProjectNotFound)
+}
+
+// invite はemailのユーザーをroleの権限でURIのParamのidのものに招待する
+// notFoundは共有するものが存在しない場合のエラーである
+func (controller *ShareController) invite(c Context, resource entity.ShareResource, notFound error) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req inviteRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	share := &entity.Share{
+		ResourceType: resource,
+		ResourceID:   entity.NewNullString(id),
+		OwnerID:      entity.NewNullString(uid),
+		Role:         req.Role,
+	}
+	err = controller.Interactor.Invite(c, share, req.Email)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidShare) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, notFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		if errors.Is(err, usecase.ErrInviteeNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrInviteeNotFound)
+			return
+		}
+		if isDuplicateEntry(err) {
+			errorToJSON(c, http.StatusConflict, ErrDuplicatedShare)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
+
+// List is the Handler for GET /share
+// 自分が招待したものと自分が招待されたものの両方を取得する
+func (controller *ShareController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	shares, err := controller.Interactor.List(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// Accept is the Handler for POST /share/:id/accept
+func (controller *ShareController) Accept(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	share, err := controller.Interactor.Accept(c, id, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrShareNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, share)
+}
+
+// Delete is the Handler for DELETE /share/:id
+func (controller *ShareController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, id, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrShareNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
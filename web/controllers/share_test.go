@@ -0,0 +1,519 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidUB = "3f6d2c1b-9a8e-4b7c-8d6e-5f4a3b2c1d0e"
+	uuidSA = "7b2c4d6e-8f0a-4b1c-9d2e-3f4a5b6c7d8e"
+)
+
+// findUsers はuuidUAとuuidUBのユーザーを返すUserRepositoryを用意する
+func findUsers(user *mock_repository.MockUserRepository) {
+	user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(entity.NewUser(uuidUA, "owner", "", "owner@example.com"), nil).AnyTimes()
+	user.EXPECT().FindByID(gomock.Any(), uuidUB).Return(entity.NewUser(uuidUB, "guest", "", "guest@example.com"), nil).AnyTimes()
+}
+
+func TestShareController_InviteTask(t *testing.T) {
+
+	findGuest := func(user *mock_repository.MockUserRepository) {
+		user.EXPECT().FindByEmail(gomock.Any(), "guest@example.com").Return(entity.NewUser(uuidUB, "guest", "", "guest@example.com"), nil)
+		findUsers(user)
+	}
+
+	tests := []testInfo{
+		{
+			name:   "自分のTaskを共有する招待を作成できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"editor"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockUserRepo: findGuest,
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, s *entity.Share) error {
+						s.ID = entity.NewNullString(uuidSA)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Share{
+				ID:           entity.NewNullString(uuidSA),
+				ResourceType: entity.ShareTask,
+				ResourceID:   entity.NewNullString(uuidTA),
+				Role:         entity.RoleEditor,
+				Email:        "guest@example.com",
+				Owner:        "owner@example.com",
+			},
+		},
+		{
+			name:   "ownerの権限では共有できないのでStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"owner"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "サブタスクは共有できないのでStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				subtask := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				subtask.ParentID = entity.NewNullString(uuidTB)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(subtask, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "editorとして共有されたTaskは共有できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "Taskが存在しないならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "招待するユーザーが存在しないならErrInviteeNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"nobody@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "nobody@example.com").Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrInviteeNotFound.Error(),
+		},
+		{
+			name:   "自分自身は招待できないのでStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"owner@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "owner@example.com").Return(entity.NewUser(uuidUA, "owner", "", "owner@example.com"), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "既に招待しているならErrDuplicatedShare",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockUserRepo: findGuest,
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Create(gomock.Any(), gomock.Any()).Return(
+					entity.NewErrMySQL(0x426, "Duplicate entry for key 'shares.index_shares_on_resource_type_and_resource_id_and_user_id'"))
+			},
+			wantErr:  true,
+			wantCode: http.StatusConflict,
+			wantData: ErrDuplicatedShare.Error(),
+		},
+		{
+			name:   "Cookieが空ならStatusUnauthorized",
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareShareTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/task/"+uuidTA+"/share", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, shareController := prepareMockShareCtrl(t, tt)
+			defer ctrl.Finish()
+
+			shareController.InviteTask(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestShareController_InviteProject(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "自分のProjectを共有する招待を作成できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().FindByID(gomock.Any(), uuidPA, uuidUA).Return(entity.NewProject(uuidPA, "work", uuidUA), nil)
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "guest@example.com").Return(entity.NewUser(uuidUB, "guest", "", "guest@example.com"), nil)
+				findUsers(user)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, s *entity.Share) error {
+						s.ID = entity.NewNullString(uuidSA)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Share{
+				ID:           entity.NewNullString(uuidSA),
+				ResourceType: entity.ShareProject,
+				ResourceID:   entity.NewNullString(uuidPA),
+				Role:         entity.RoleViewer,
+				Email:        "guest@example.com",
+				Owner:        "owner@example.com",
+			},
+		},
+		{
+			name:   "Projectが存在しないならErrProjectNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidPA},
+			body:   `{"email":"guest@example.com","role":"viewer"}`,
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().FindByID(gomock.Any(), uuidPA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrProjectNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareShareTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/project/"+uuidPA+"/share", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, shareController := prepareMockShareCtrl(t, tt)
+			defer ctrl.Finish()
+
+			shareController.InviteProject(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestShareController_List(t *testing.T) {
+
+	pending := &entity.Share{
+		ID:           entity.NewNullString(uuidSA),
+		ResourceType: entity.ShareTask,
+		ResourceID:   entity.NewNullString(uuidTA),
+		OwnerID:      entity.NewNullString(uuidUB),
+		UserID:       entity.NewNullString(uuidUA),
+		Role:         entity.RoleViewer,
+	}
+
+	tests := []testInfo{
+		{
+			name:                "招待したものと招待されたものを所有者と共有されたユーザーのEmailとともに取得できる",
+			userid:              uuidUA,
+			prepareMockUserRepo: findUsers,
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{pending}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Share{{
+				ID:           entity.NewNullString(uuidSA),
+				ResourceType: entity.ShareTask,
+				ResourceID:   entity.NewNullString(uuidTA),
+				Role:         entity.RoleViewer,
+				Email:        "owner@example.com",
+				Owner:        "guest@example.com",
+			}},
+		},
+		{
+			name:     "Cookieが空ならStatusUnauthorized",
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareShareTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/share", nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, shareController := prepareMockShareCtrl(t, tt)
+			defer ctrl.Finish()
+
+			shareController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestShareController_Accept(t *testing.T) {
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []testInfo{
+		{
+			name:                "招待を承諾できる",
+			userid:              uuidUB,
+			params:              map[string]string{"id": uuidSA},
+			prepareMockUserRepo: findUsers,
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Accept(gomock.Any(), uuidSA, uuidUB, now).Return(nil)
+				share.EXPECT().FindByID(gomock.Any(), uuidSA).Return(&entity.Share{
+					ID:           entity.NewNullString(uuidSA),
+					ResourceType: entity.ShareProject,
+					ResourceID:   entity.NewNullString(uuidPA),
+					OwnerID:      entity.NewNullString(uuidUA),
+					UserID:       entity.NewNullString(uuidUB),
+					Role:         entity.RoleEditor,
+					AcceptedAt:   &now,
+				}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Share{
+				ID:           entity.NewNullString(uuidSA),
+				ResourceType: entity.ShareProject,
+				ResourceID:   entity.NewNullString(uuidPA),
+				Role:         entity.RoleEditor,
+				Email:        "guest@example.com",
+				Owner:        "owner@example.com",
+				AcceptedAt:   &now,
+			},
+		},
+		{
+			name:   "承諾していない自分への招待が存在しないならErrShareNotFound",
+			userid: uuidUB,
+			params: map[string]string{"id": uuidSA},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Accept(gomock.Any(), uuidSA, uuidUB, now).Return(entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrShareNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareShareTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/share/"+uuidSA+"/accept", nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, shareController := prepareMockShareCtrl(t, tt)
+			defer ctrl.Finish()
+			shareController.Interactor.Clock = fakeClock{now: now}
+
+			shareController.Accept(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestShareController_Delete(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "招待を取り消せる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidSA},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Delete(gomock.Any(), uuidSA, uuidUA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "Shareが存在しないならErrShareNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidSA},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().Delete(gomock.Any(), uuidSA, uuidUA).Return(entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrShareNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareShareTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/share/"+uuidSA, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, shareController := prepareMockShareCtrl(t, tt)
+			defer ctrl.Finish()
+
+			shareController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+// acceptedShare はuuidUBのユーザーがuuidUAのユーザーにroleの権限で共有して承諾されたShareである
+func acceptedShare(resource entity.ShareResource, id string, role entity.Role) *entity.Share {
+	at := time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+	return &entity.Share{
+		ID:           entity.NewNullString(uuidSA),
+		ResourceType: resource,
+		ResourceID:   entity.NewNullString(id),
+		OwnerID:      entity.NewNullString(uuidUB),
+		UserID:       entity.NewNullString(uuidUA),
+		Role:         role,
+		AcceptedAt:   &at,
+	}
+}
+
+func prepareShareTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockShareCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, shareController *ShareController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	shareRepo := mock_repository.NewMockShareRepository(ctrl)
+	if tt.prepareMockShareRepo != nil {
+		tt.prepareMockShareRepo(shareRepo)
+	}
+	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
+	if tt.prepareMockTaskRepo != nil {
+		tt.prepareMockTaskRepo(taskRepo)
+	}
+	projectRepo := mock_repository.NewMockProjectRepository(ctrl)
+	if tt.prepareMockProjectRepo != nil {
+		tt.prepareMockProjectRepo(projectRepo)
+	}
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	if tt.prepareMockUserRepo != nil {
+		tt.prepareMockUserRepo(userRepo)
+	}
+	membershipRepo := prepareMockMembershipRepo(ctrl, tt)
+
+	shareController = NewShareController(shareRepo, taskRepo, projectRepo, userRepo, membershipRepo, logging.Discard())
+	return
+}
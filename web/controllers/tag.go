@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type TagController struct {
+	Interactor *usecase.TagInteractor
+	Logger     *slog.Logger
+}
+
+func NewTagController(tag repository.TagRepository, logger *slog.Logger) *TagController {
+	return &TagController{
+		Interactor: usecase.NewTagInteractor(tag, logger),
+		Logger:     logger,
+	}
+}
+
+// Create is the Handler for POST /tag
+func (controller *TagController) Create(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tag, err := getTagFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	tag.UserID.Set(uid)
+
+	err = controller.Interactor.Create(c, tag)
+
+	if err != nil {
+		if isDuplicateEntry(err) {
+			errorToJSON(c, http.StatusBadRequest, ErrDuplicatedTag)
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidTag) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// List is the Handler for GET /tag
+func (controller *TagController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	tags, err := controller.Interactor.List(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetByID is the Handler for GET /tag/:id
+func (controller *TagController) GetByID(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	tag, err := controller.Interactor.GetByID(c, id, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTagNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// Update is the Handler for PUT /tag/:id
+func (controller *TagController) Update(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tag, err := getTagFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	tag.ID.Set(id)
+	tag.UserID.Set(uid)
+
+	err = controller.Interactor.Update(c, tag)
+
+	if err != nil {
+		if isDuplicateEntry(err) {
+			errorToJSON(c, http.StatusBadRequest, ErrDuplicatedTag)
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidTag) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTagNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete is the Handler for DELETE /tag/:id
+// 削除したTagはすべてのTaskから外れる
+func (controller *TagController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, id, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTagNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+// Attach is the Handler for PUT /task/:id/tag/:tagid
+func (controller *TagController) Attach(c Context) {
+	controller.attachOrDetach(c, controller.Interactor.Attach)
+}
+
+// Detach is the Handler for DELETE /task/:id/tag/:tagid
+func (controller *TagController) Detach(c Context) {
+	controller.attachOrDetach(c, controller.Interactor.Detach)
+}
+
+// attachOrDetach はAttachとDetachで共通のパラメータの取得とエラーハンドリングを行う
+func (controller *TagController) attachOrDetach(c Context, f func(ctx context.Context, tid, tagID, uid string) error) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	tagID := c.Param("tagid")
+	if tagID == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = f(c, tid, tagID, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskOrTagNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+func getTagFromBody(c Context) (tag *entity.Tag, err error) {
+	err = c.ShouldBindJSON(&tag)
+	return
+}
@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidGA = "5e1f3a2b-8c7d-4e6f-9a0b-1c2d3e4f5a6b"
+)
+
+func TestTagController_Create(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "正しくタグを作成できる",
+			userid: uuidUA,
+			body: `{
+				"name":"work",
+				"color":"#ff0000"
+			}`,
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Create(gomock.Any(), entity.NewTag("", "work", "#ff0000", uuidUA)).
+					DoAndReturn(func(_ context.Context, tag *entity.Tag) error {
+						tag.ID.Set(uuidGA)
+						tag.CreatedAt = time.Unix(100, 0)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewTag(uuidGA, "work", "#ff0000", uuidUA),
+		},
+		{
+			name:   "同じ名前のタグが既に存在するならErrDuplicatedTag",
+			userid: uuidUA,
+			body: `{
+				"name":"work"
+			}`,
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Create(gomock.Any(), gomock.Any()).Return(
+					entity.NewErrMySQL(0x426, "Duplicate entry 'work' for key 'tags.user_id'"))
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrDuplicatedTag.Error(),
+		},
+		{
+			name:   "Requestにnameが含まれていないならStatusBadRequest",
+			userid: uuidUA,
+			body: `{
+				"color":"#ff0000"
+			}`,
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "RequestにIDが含まれているならStatusBadRequest",
+			userid: uuidUA,
+			body: `{
+				"id":"` + uuidGA + `",
+				"name":"work"
+			}`,
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTagTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/tag", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, tagController := prepareMockTagCtrl(t, tt)
+			defer ctrl.Finish()
+
+			tagController.Create(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTagController_List(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "ユーザーのタグの一覧を取得できる",
+			userid: uuidUA,
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Tag{
+					entity.NewTag(uuidGA, "work", "", uuidUA),
+				}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Tag{entity.NewTag(uuidGA, "work", "", uuidUA)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTagTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/tag", nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, tagController := prepareMockTagCtrl(t, tt)
+			defer ctrl.Finish()
+
+			tagController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTagController_Delete(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "正しくタグを削除できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidGA},
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Delete(gomock.Any(), uuidGA, uuidUA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "DBにタグがないときはErrTagNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidGA},
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Delete(gomock.Any(), uuidGA, uuidUA).Return(entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTagNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTagTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/tag/"+tt.params["id"], nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, tagController := prepareMockTagCtrl(t, tt)
+			defer ctrl.Finish()
+
+			tagController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTagController_Attach(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "タスクにタグを付けられる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "tagid": uuidGA},
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Attach(gomock.Any(), uuidTA, uuidGA, uuidUA).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "他のユーザーのタスクやタグならErrTaskOrTagNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA, "tagid": uuidGA},
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+				tag.EXPECT().Attach(gomock.Any(), uuidTA, uuidGA, uuidUA).Return(entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskOrTagNotFound.Error(),
+		},
+		{
+			name:   "tagidが空ならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTagRepo: func(tag *mock_repository.MockTagRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTagTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task/"+tt.params["id"]+"/tag/"+tt.params["tagid"], nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, tagController := prepareMockTagCtrl(t, tt)
+			defer ctrl.Finish()
+
+			tagController.Attach(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func prepareTagTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockTagCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, tagController *TagController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	tagRepo := mock_repository.NewMockTagRepository(ctrl)
+	tt.prepareMockTagRepo(tagRepo)
+
+	tagController = NewTagController(tagRepo, logging.Discard())
+	return
+}
+
+// setCookieAndParams はcookieにuseridを入れ，URIのParamを設定する
+func setCookieAndParams(t *testing.T, tt testInfo, c *gin.Context) {
+	t.Helper()
+
+	if tt.userid != "" {
+		c.Request.AddCookie(&http.Cookie{
+			Name:  "id",
+			Value: tt.userid,
+		})
+	}
+	setParams(t, tt, c)
+}
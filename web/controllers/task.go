@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 	"github.com/hiroyaonoe/todoapp-server/domain/repository"
@@ -11,10 +15,14 @@ import (
 
 type TaskController struct {
 	Interactor *usecase.TaskInteractor
+	Logger     *slog.Logger
 }
 
-func NewTaskController(task repository.TaskRepository) *TaskController {
-	return &TaskController{Interactor: usecase.NewTaskInteractor(task)}
+func NewTaskController(task repository.TaskRepository, project repository.ProjectRepository, user repository.UserRepository, reminder repository.ReminderRepository, share repository.ShareRepository, membership repository.MembershipRepository, assignment repository.AssignmentRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *TaskController {
+	return &TaskController{
+		Interactor: usecase.NewTaskInteractor(task, project, user, reminder, share, membership, assignment, activity, transactor, logger),
+		Logger:     logger,
+	}
 }
 
 // Create is the Handler for POST /task
@@ -32,20 +40,298 @@ func (controller *TaskController) Create(c Context) {
 
 	task.UserID.Set(uid)
 
-	err = controller.Interactor.Create(task)
+	err = controller.Interactor.Create(c, task, getPreconditionFromHeader(c))
 
 	if err != nil {
 		if errors.Is(err, usecase.ErrInvalidTask) {
 			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
 			return
 		}
+		if errors.Is(err, usecase.ErrProjectNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrProjectNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrWorkspaceNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrWorkspaceNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrParentNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrParentTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrTaskTooDeep) {
+			errorToJSON(c, http.StatusBadRequest, ErrTaskTooDeep)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
 		// TODO:user not found
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
+	c.JSON(http.StatusOK, task)
+}
+
+// CreateSubtask is the Handler for POST /task/:id/subtask
+// リクエストはPOST /taskと同じで，dateとproject_idを省略した場合は親から引き継ぐ
+func (controller *TaskController) CreateSubtask(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	task, err := getTaskFromBody(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	task.UserID.Set(uid)
+	task.ParentID.Set(tid)
+
+	err = controller.Interactor.Create(c, task, getPreconditionFromHeader(c))
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTask) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, usecase.ErrProjectNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrProjectNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrParentNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrTaskTooDeep) {
+			errorToJSON(c, http.StatusBadRequest, ErrTaskTooDeep)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
+	c.JSON(http.StatusOK, task)
+}
+
+// ListSubtasks is the Handler for GET /task/:id/subtask
+func (controller *TaskController) ListSubtasks(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	tasks, err := controller.Interactor.ListSubtasks(c, tid, uid)
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+type moveRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+// Move is the Handler for PUT /task/:id/parent
+// parent_idがnullの場合は親のないTaskにする
+func (controller *TaskController) Move(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req moveRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	task, err := controller.Interactor.Move(c, tid, uid, req.ParentID, getPreconditionFromHeader(c))
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrParentNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrParentTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrTaskCycle) {
+			errorToJSON(c, http.StatusBadRequest, ErrTaskCycle)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrTaskTooDeep) {
+			errorToJSON(c, http.StatusBadRequest, ErrTaskTooDeep)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
+	c.JSON(http.StatusOK, task)
+}
+
+type completeRequest struct {
+	IsCompleted *bool `json:"iscomp"`
+}
+
+// Complete is the Handler for PUT /task/:id/comp
+func (controller *TaskController) Complete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req completeRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil || req.IsCompleted == nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	task, err := controller.Interactor.Complete(c, tid, uid, *req.IsCompleted, getPreconditionFromHeader(c))
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
+	c.JSON(http.StatusOK, task)
+}
+
+type assignRequest struct {
+	Email string `json:"email"`
+}
+
+// Assign is the Handler for PUT /task/:id/assignee
+// emailが空の場合は担当者を外す
+func (controller *TaskController) Assign(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
 		return
 	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req assignRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	task, err := controller.Interactor.Assign(c, tid, uid, req.Email, getPreconditionFromHeader(c))
+
+	if err != nil {
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidAssignee) {
+			errorToJSON(c, http.StatusBadRequest, ErrInvalidAssignee)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
 	c.JSON(http.StatusOK, task)
 }
 
+// AssignedToMe is the Handler for GET /task/assigned-to-me
+// 自分が担当するTaskをサブタスクも含めて取得する
+func (controller *TaskController) AssignedToMe(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	tasks, err := controller.Interactor.AssignedToMe(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
 // GetByID is the Handler for GET /task/:id
 func (controller *TaskController) GetByID(c Context) {
 	uid, err := getUserIDFromCookie(c)
@@ -59,7 +345,7 @@ func (controller *TaskController) GetByID(c Context) {
 		return
 	}
 
-	task, err := controller.Interactor.GetByID(tid, uid)
+	task, err := controller.Interactor.GetByID(c, tid, uid)
 
 	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
@@ -67,12 +353,184 @@ func (controller *TaskController) GetByID(c Context) {
 			return
 		}
 		// TODO:user not found
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	if notModified(c, task.ETag()) {
 		return
 	}
 	c.JSON(http.StatusOK, task)
 }
 
+// List is the Handler for GET /task
+// クエリパラメータdate(YYYY-MM-DD)で日付を，fromとtoで期間を，tag(複数指定可)でTagを，projectでProjectを，
+// sort(position, priority)で並び順を指定する
+func (controller *TaskController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	q, err := getTaskQuery(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	tasks, err := controller.Interactor.List(c, uid, q)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTask) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// Search is the Handler for GET /task/search
+// クエリパラメータqで検索文字列を指定する．"..."で囲むと句として，末尾に*を付けると前方一致として検索する
+func (controller *TaskController) Search(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	results, err := controller.Interactor.Search(c, uid, c.Query("q"))
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSearchQuery) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// defaultUpcomingDays はGET /task/upcomingで取得する日数のデフォルト値である
+const defaultUpcomingDays = 7
+
+// Overdue is the Handler for GET /task/overdue
+// ユーザーのタイムゾーンでの今日より前の日付の未完了のTaskを取得する
+func (controller *TaskController) Overdue(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	view, err := controller.Interactor.Overdue(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// Today is the Handler for GET /task/today
+// ユーザーのタイムゾーンでの今日のTaskを取得する
+func (controller *TaskController) Today(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	view, err := controller.Interactor.Today(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// Upcoming is the Handler for GET /task/upcoming
+// クエリパラメータdaysで明日から何日間のTaskを取得するかを指定する(デフォルトは7日間)
+func (controller *TaskController) Upcoming(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	days := defaultUpcomingDays
+	if d := c.Query("days"); d != "" {
+		days, err = strconv.Atoi(d)
+		if err != nil {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+	}
+
+	view, err := controller.Interactor.Upcoming(c, uid, days)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTask) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+type reorderRequest struct {
+	After  string `json:"after"`
+	Before string `json:"before"`
+}
+
+// Reorder is the Handler for PUT /task/:id/position
+func (controller *TaskController) Reorder(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req reorderRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	task, err := controller.Interactor.Reorder(c, tid, uid, req.After, req.Before, getPreconditionFromHeader(c))
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidTask) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.Header("ETag", task.ETag())
+	c.JSON(http.StatusOK, task)
+}
+
 // Update is the Handler for PUT /task/:id
 func (controller *TaskController) Update(c Context) {
 	uid, err := getUserIDFromCookie(c)
@@ -95,7 +553,7 @@ func (controller *TaskController) Update(c Context) {
 	task.ID.Set(tid)
 	task.UserID.Set(uid)
 
-	err = controller.Interactor.Update(task)
+	err = controller.Interactor.Update(c, task, getPreconditionFromHeader(c))
 
 	if err != nil {
 		if errors.Is(err, usecase.ErrInvalidTask) {
@@ -106,10 +564,23 @@ func (controller *TaskController) Update(c Context) {
 			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
 			return
 		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrWorkspaceNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrWorkspaceNotFound)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
 		// TODO:user not found
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
 		return
 	}
+	c.Header("ETag", task.ETag())
 	c.JSON(http.StatusOK, task)
 }
 
@@ -126,21 +597,253 @@ func (controller *TaskController) Delete(c Context) {
 		return
 	}
 
-	err = controller.Interactor.Delete(tid, uid)
+	err = controller.Interactor.Delete(c, tid, uid, getPreconditionFromHeader(c))
 
 	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
 			return
 		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		if errors.Is(err, usecase.ErrForbidden) {
+			errorToJSON(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
 		// TODO:user not found
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
 		return
 	}
 	c.JSON(http.StatusOK, nil)
 }
 
+// batchRequest はPOST /task/batchのリクエストである
+type batchRequest struct {
+	Operations      []*batchOperation `json:"operations"`
+	ContinueOnError bool              `json:"continueOnError"`
+}
+
+// batchOperation はPOST /task/batchのひとつの操作である
+// opはcreate, update, delete, completeのいずれかであり，
+// taskはcreateとupdateで，iscompはcompleteで指定する．if_matchはcreateでは親のTaskのETagと比べる
+type batchOperation struct {
+	Op          usecase.TaskOperationType `json:"op"`
+	ID          string                    `json:"id"`
+	Task        *entity.Task              `json:"task"`
+	IsCompleted *bool                     `json:"iscomp"`
+	IfMatch     string                    `json:"if_match"`
+}
+
+// toOperation はリクエストの操作をusecaseの操作に変換する．必要なフィールドがない場合はエラーを返す
+func (o *batchOperation) toOperation() (*usecase.TaskOperation, error) {
+	op := &usecase.TaskOperation{
+		Type:         o.Op,
+		ID:           o.ID,
+		Task:         o.Task,
+		Precondition: entity.NewPrecondition(o.IfMatch),
+	}
+	switch o.Op {
+	case usecase.TaskOpCreate:
+		if o.Task == nil || o.ID != "" {
+			return nil, ErrInvalidTask
+		}
+	case usecase.TaskOpUpdate:
+		if o.Task == nil || o.ID == "" {
+			return nil, ErrInvalidTask
+		}
+	case usecase.TaskOpDelete:
+		if o.ID == "" {
+			return nil, ErrInvalidTask
+		}
+	case usecase.TaskOpComplete:
+		if o.ID == "" || o.IsCompleted == nil {
+			return nil, ErrInvalidTask
+		}
+		op.IsCompleted = *o.IsCompleted
+	default:
+		return nil, ErrInvalidTask
+	}
+	return op, nil
+}
+
+// batchResult はPOST /task/batchのひとつの操作の結果である
+type batchResult struct {
+	Code  int          `json:"code"`
+	Task  *entity.Task `json:"task,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Results []*batchResult `json:"results"`
+}
+
+// Batch is the Handler for POST /task/batch
+// 操作ごとの結果とステータスコードを操作と同じ順に返す
+// continueOnErrorがfalseの場合はいずれかの操作が失敗するとすべてを取り消し，失敗した操作のステータスコードを返す
+func (controller *TaskController) Batch(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	var req batchRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	ops := make([]*usecase.TaskOperation, len(req.Operations))
+	for i, o := range req.Operations {
+		if o == nil {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		ops[i], err = o.toOperation()
+		if err != nil {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+	}
+
+	results, err := controller.Interactor.Batch(c, uid, ops, req.ContinueOnError)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidBatch) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	code := http.StatusOK
+	res := &batchResponse{Results: make([]*batchResult, len(results))}
+	for i, r := range results {
+		if r.Err == nil {
+			res.Results[i] = &batchResult{Code: http.StatusOK, Task: r.Task}
+			continue
+		}
+		status, e := controller.operationError(c, r.Err)
+		res.Results[i] = &batchResult{Code: status, Error: e.Error()}
+		if !req.ContinueOnError && !errors.Is(r.Err, usecase.ErrBatchAborted) {
+			code = status
+		}
+	}
+	c.JSON(code, res)
+}
+
+// operationError はPOST /task/batchの操作のエラーをステータスコードとレスポンスのエラーに変換する
+// 各操作を単独で行った場合と同じステータスコードにする
+func (controller *TaskController) operationError(c Context, err error) (int, error) {
+	switch {
+	case errors.Is(err, usecase.ErrBatchAborted):
+		return http.StatusFailedDependency, ErrBatchAborted
+	case errors.Is(err, usecase.ErrInvalidTask):
+		return http.StatusBadRequest, ErrBadRequest
+	case errors.Is(err, entity.ErrRecordNotFound):
+		return http.StatusNotFound, ErrTaskNotFound
+	case errors.Is(err, usecase.ErrProjectNotFound):
+		return http.StatusNotFound, ErrProjectNotFound
+	case errors.Is(err, usecase.ErrWorkspaceNotFound):
+		return http.StatusNotFound, ErrWorkspaceNotFound
+	case errors.Is(err, usecase.ErrParentNotFound):
+		return http.StatusNotFound, ErrParentTaskNotFound
+	case errors.Is(err, usecase.ErrTaskTooDeep):
+		return http.StatusBadRequest, ErrTaskTooDeep
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden, ErrForbidden
+	case errors.Is(err, usecase.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, ErrPreconditionFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		controller.Logger.WarnContext(c, "timeout", slog.Any("error", err))
+		return http.StatusGatewayTimeout, ErrTimeout
+	case errors.Is(err, context.Canceled):
+		controller.Logger.InfoContext(c, "request canceled", slog.Any("error", err))
+		return statusClientClosedRequest, ErrRequestCanceled
+	default:
+		controller.Logger.ErrorContext(c, "unexpected error", slog.Any("error", err))
+		return http.StatusInternalServerError, ErrInternalServerError
+	}
+}
+
+// History is the Handler for GET /task/:id/history
+// クエリパラメータlimit(デフォルトは20件)とoffsetで取得する範囲を指定する
+func (controller *TaskController) History(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	tid, err := getTaskIDFromParam(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	page, err := getPageFromQuery(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	history, err := controller.Interactor.History(c, tid, uid, page)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPage) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		if errors.Is(err, entity.ErrRecordNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrTaskNotFound)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
 func getTaskFromBody(c Context) (task *entity.Task, err error) {
 	err = c.ShouldBindJSON(&task)
 	return
 }
+
+// getTaskQuery はクエリパラメータからTaskの一覧を取得する条件を取得する
+func getTaskQuery(c Context) (q repository.TaskQuery, err error) {
+	if date := c.Query("date"); date != "" {
+		err = q.Date.Set(date)
+		if err != nil {
+			return
+		}
+	}
+	// 期間(from, to)を指定した場合は繰り返しのTaskの回を展開する
+	if from := c.Query("from"); from != "" {
+		err = q.From.Set(from)
+		if err != nil {
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		err = q.To.Set(to)
+		if err != nil {
+			return
+		}
+	}
+	q.TagIDs = c.QueryArray("tag")
+	// project=inboxの場合はProjectに属さないTaskを取得する
+	if project := c.Query("project"); project == "inbox" {
+		q.Inbox = true
+	} else {
+		q.ProjectID = project
+	}
+	q.WorkspaceID = c.Query("workspace")
+	switch c.Query("sort") {
+	case "", "position":
+		q.Sort = repository.SortByPosition
+	case "priority":
+		q.Sort = repository.SortByPriority
+	default:
+		err = fmt.Errorf("%s is invalid sort", c.Query("sort"))
+	}
+	return
+}
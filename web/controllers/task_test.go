@@ -2,15 +2,24 @@ package controllers
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
 )
 
 // user_test上にあるので不要
@@ -24,6 +33,8 @@ import (
 
 const (
 	uuidTA = "65b77c66-99f1-985a-74d1-caccf54cda73"
+	uuidTB = "0c9a1f4e-27d3-4b1e-9f0a-6d2b8e5c7a14"
+	uuidTC = "f3e8b2d1-5a6c-4d7e-8b9f-1a2c3d4e5f60"
 )
 
 func TestTaskController_Create(t *testing.T) {
@@ -39,8 +50,8 @@ func TestTaskController_Create(t *testing.T) {
 				"deadline":"2020-12-06"
 			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Create(gomock.Any()).
-					DoAndReturn(func(task *entity.Task) error {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
 						task.SetID("any id")
 						task.CreatedAt = time.Unix(100, 0)
 						task.UpdatedAt = time.Unix(100, 0)
@@ -51,6 +62,137 @@ func TestTaskController_Create(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantData: entity.NewTask("any id", "taskname", "I am content.", "", "2020-12-06"),
 		},
+		{
+			name:   "参加しているWorkspaceにタスクを作成できる",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"workspace_id":"` + uuidWA + `"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.SetID("any id")
+						return nil
+					})
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceMember), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:          entity.NewNullString("any id"),
+				Title:       entity.NewNullString("taskname"),
+				WorkspaceID: entity.NewNullString(uuidWA),
+				Deadline:    entity.NewNullDate("2020-12-06"),
+			},
+		},
+		{
+			name:   "guestはWorkspaceにタスクを作成できないのでStatusForbidden",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"workspace_id":"` + uuidWA + `"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceGuest), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "参加していないWorkspaceにはタスクを作成できないのでErrWorkspaceNotFound",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"workspace_id":"` + uuidWA + `"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrWorkspaceNotFound.Error(),
+		},
+		{
+			name:   "繰り返しの規則が不正ならStatusBadRequest",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"recurrence":"FREQ=YEARLY"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "期限の時刻を指定してタスクを作成できる",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"due_time":"18:00",
+				"overdue":true
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.SetID("any id")
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString("any id"),
+				Title:    entity.NewNullString("taskname"),
+				Deadline: entity.NewNullDate("2020-12-06"),
+				DueTime:  entity.NewNullString("18:00"),
+			},
+		},
+		{
+			name:   "期限の時刻が不正ならStatusBadRequest",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"due_time":"25:00"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "他のユーザーのプロジェクトを指定したならErrProjectNotFound",
+			userid: uuidUA,
+			body: `{
+				"title":"taskname",
+				"deadline":"2020-12-06",
+				"project_id":"` + uuidPA + `"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			prepareMockProjectRepo: func(project *mock_repository.MockProjectRepository) {
+				project.EXPECT().FindByID(gomock.Any(), uuidPA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrProjectNotFound.Error(),
+		},
 		{
 			name:   "RequestにtaskIDが含まれているならStatusBadRequest",
 			userid: uuidUA,
@@ -119,8 +261,8 @@ func TestTaskController_Create(t *testing.T) {
 				"deadline":"2020-12-06"
 			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Create(gomock.Any()).
-					DoAndReturn(func(task *entity.Task) error {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
 						task.SetID("any id")
 						task.CreatedAt = time.Unix(100, 0)
 						task.UpdatedAt = time.Unix(100, 0)
@@ -140,8 +282,8 @@ func TestTaskController_Create(t *testing.T) {
 				"deadline":"2020-12-06"
 			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Create(gomock.Any()).
-					DoAndReturn(func(task *entity.Task) error {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
 						task.SetID("any id")
 						task.CreatedAt = time.Unix(100, 0)
 						task.UpdatedAt = time.Unix(100, 0)
@@ -201,7 +343,7 @@ func TestTaskController_GetByID(t *testing.T) {
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().FindByID(uuidTA, uuidUA).Return(&entity.Task{
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(&entity.Task{
 					ID:          entity.NewNullString(uuidTA),
 					Title:       entity.NewNullString("title"),
 					Content:     entity.NewNullString("I am Content."),
@@ -217,168 +359,198 @@ func TestTaskController_GetByID(t *testing.T) {
 			wantData: entity.NewTask(uuidTA, "title", "I am Content.", uuidUA, "2020-12-27").SetComp(true),
 		},
 		{
-			name:   "DBにTaskがないときはErrTaskNotFound",
+			name:   "If-None-Matchが現在のETagに一致するならStatusNotModified",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-None-Match": `"3"`},
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().FindByID(uuidTA, uuidUA).Return(&entity.Task{}, entity.ErrRecordNotFound)
+				t := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				t.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(t, nil)
 			},
-			wantErr:  true,
-			wantCode: http.StatusNotFound,
-			wantData: ErrTaskNotFound.Error(),
+			wantCode:   http.StatusNotModified,
+			wantHeader: map[string]string{"ETag": `"3"`},
 		},
 		{
-			name: "Cookieが空ならStatusUnauthorized",
-			prepareMockTaskRepo: func(user *mock_repository.MockTaskRepository) {
+			name:   "If-None-Matchが古いETagならタスクと現在のETagを返す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-None-Match": `"2"`},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				t := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				t.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(t, nil)
 			},
-			wantErr:  true,
-			wantCode: http.StatusUnauthorized,
-			wantData: ErrUnauthorized.Error(),
+			wantErr:    false,
+			wantCode:   http.StatusOK,
+			wantData:   entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"),
+			wantHeader: map[string]string{"ETag": `"3"`},
 		},
 		{
-			name:   "paramが空ならStatusBadRequest",
+			name:   "期限を過ぎてoverdueが変わったならIf-None-Matchがバージョンに一致してもタスクを返す",
 			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-None-Match": `"3"`},
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				t := entity.NewTask(uuidTA, "title", "", uuidUA, "2019-12-31")
+				t.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(t, nil)
 			},
-			wantErr:  true,
-			wantCode: http.StatusBadRequest,
-			wantData: ErrBadRequest.Error(),
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("title"),
+				Deadline: entity.NewNullDate("2019-12-31"),
+				Overdue:  true,
+			},
+			wantHeader: map[string]string{"ETag": `"3-overdue"`},
 		},
-	}
-
-	for _, tt := range tests {
-		tt := tt
-		t.Run(tt.name, func(t *testing.T) {
-			context, w := prepareTaskTT(t)
-
-			// httpRequest
-			context.Request, _ = http.NewRequest("POST", "/task", bytes.NewBufferString(tt.body))
-			if tt.userid != "" {
-				context.Request.AddCookie(&http.Cookie{
-					Name:  "id",
-					Value: tt.userid,
-				})
-			}
-			setParams(t, tt, context)
-
-			// モック,コントローラーの準備
-			ctrl, taskController := prepareMockTaskCtrl(t, tt)
-			defer ctrl.Finish()
-
-			taskController.GetByID(context)
-
-			compareResult(t, w, tt)
-		})
-	}
-}
-
-func TestTaskController_Updeadline(t *testing.T) {
-
-	tests := []testInfo{
 		{
-			name:   "全フィールドを更新できる",
+			name:   "期限を過ぎたタスクはUTCでoverdueになる",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"title":"newtitle",
-				"content":"I am new content.",
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Update(gomock.Any()).
-					DoAndReturn(func(task *entity.Task) error {
-						task.CreatedAt = time.Unix(100, 0)
-						task.UpdatedAt = time.Unix(100, 0)
-						return nil
-					})
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2019-12-31"), nil)
 			},
 			wantErr:  false,
 			wantCode: http.StatusOK,
-			wantData: entity.NewTask(uuidTA, "newtitle", "I am new content.", "", "2020-01-05").SetComp(true),
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("title"),
+				Deadline: entity.NewNullDate("2019-12-31"),
+				Overdue:  true,
+			},
 		},
 		{
-			name:   "フィールドが足りないならStatusBadRequest",
+			name:   "ユーザーのタイムゾーンでまだ期限の日ならoverdueにならない",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := entity.NewUser(uuidUA, "username", "", "user@example.com")
+				u.TimeZone = entity.NewNullString("America/Los_Angeles")
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+			},
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2019-12-31"), nil)
 			},
-			wantErr:  true,
-			wantCode: http.StatusBadRequest,
-			wantData: ErrBadRequest.Error(),
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewTask(uuidTA, "title", "", uuidUA, "2019-12-31"),
 		},
 		{
-			name:   "RequestBodyが不正ならStatusBadRequest",
+			name:   "期限の時刻を過ぎたタスクはユーザーのタイムゾーンでoverdueになる",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"id":"taskid",
-				"name":"newname"
-			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := entity.NewUser(uuidUA, "username", "", "user@example.com")
+				u.TimeZone = entity.NewNullString("Asia/Tokyo")
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+			},
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				t := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-01-01")
+				t.DueTime = entity.NewNullString("08:30")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(t, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("title"),
+				Deadline: entity.NewNullDate("2020-01-01"),
+				DueTime:  entity.NewNullString("08:30"),
+				Overdue:  true,
 			},
-			wantErr:  true,
-			wantCode: http.StatusBadRequest,
-			wantData: ErrBadRequest.Error(),
 		},
 		{
-			name:   "RequestBodyがJSONでないならStatusBadRequest",
+			name:   "共有されたプロジェクトのタスクのサブタスクを共有された権限で取得できる",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body:   `aaaaa`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				subtask := entity.NewTask(uuidTA, "subtask", "", uuidUB, "2020-12-27")
+				subtask.ParentID = entity.NewNullString(uuidTB)
+				parent := entity.NewTask(uuidTB, "parent", "", uuidUB, "2020-12-27")
+				parent.ProjectID = entity.NewNullString(uuidPA)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(subtask, nil)
+				task.EXPECT().Find(gomock.Any(), uuidTB).Return(parent, nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareProject, uuidPA, entity.RoleViewer)}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("subtask"),
+				ParentID: entity.NewNullString(uuidTB),
+				Deadline: entity.NewNullDate("2020-12-27"),
+				Role:     entity.RoleViewer,
 			},
-			wantErr:  true,
-			wantCode: http.StatusBadRequest,
-			wantData: ErrBadRequest.Error(),
 		},
 		{
-			name:   "DBにTaskがないときはErrTaskNotFound",
+			name:   "参加しているWorkspaceのタスクを役割に応じた権限で取得できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				t := entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27")
+				t.WorkspaceID = entity.NewNullString(uuidWA)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(t, nil)
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Membership{member(uuidMA, uuidUA, entity.WorkspaceMember)}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:          entity.NewNullString(uuidTA),
+				Title:       entity.NewNullString("title"),
+				WorkspaceID: entity.NewNullString(uuidWA),
+				Deadline:    entity.NewNullDate("2020-12-27"),
+				Role:        entity.RoleEditor,
+			},
+		},
+		{
+			name:   "承諾していない招待のタスクはErrTaskNotFound",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"title":"newtitle",
-				"content":"I am new content.",
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Update(gomock.Any()).Return(entity.ErrRecordNotFound)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				pending := acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)
+				pending.AcceptedAt = nil
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{pending}, nil)
 			},
 			wantErr:  true,
 			wantCode: http.StatusNotFound,
 			wantData: ErrTaskNotFound.Error(),
 		},
 		{
-			name:   "DBにUserがないときはErrTaskNotFound",
+			name:   "DBにTaskがないときはErrTaskNotFound",
 			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"title":"newtitle",
-				"content":"I am new content.",
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Update(gomock.Any()).Return(entity.ErrRecordNotFound)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(&entity.Task{}, entity.ErrRecordNotFound)
 			},
 			wantErr:  true,
 			wantCode: http.StatusNotFound,
 			wantData: ErrTaskNotFound.Error(),
 		},
 		{
-			name:   "Cookieが空ならStatusUnauthorized",
+			name:   "DBへのクエリがタイムアウトしたらStatusGatewayTimeout",
+			userid: uuidUA,
 			params: map[string]string{"id": uuidTA},
-			body: `{
-				"title":"newtitle",
-				"content":"I am new content.",
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, context.DeadlineExceeded)
+			},
+			wantErr:  true,
+			wantCode: http.StatusGatewayTimeout,
+			wantData: ErrTimeout.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
 			prepareMockTaskRepo: func(user *mock_repository.MockTaskRepository) {
 			},
 			wantErr:  true,
@@ -386,15 +558,9 @@ func TestTaskController_Updeadline(t *testing.T) {
 			wantData: ErrUnauthorized.Error(),
 		},
 		{
-			name:   "TaskIDが空ならErrBadRequest",
+			name:   "paramが空ならStatusBadRequest",
 			userid: uuidUA,
-			body: `{
-				"title":"newtitle",
-				"content":"I am new content.",
-				"iscomp":true,
-				"deadline":"2020-01-05"
-			}`,
-			prepareMockTaskRepo: func(user *mock_repository.MockTaskRepository) {
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
 			},
 			wantErr:  true,
 			wantCode: http.StatusBadRequest,
@@ -408,7 +574,7 @@ func TestTaskController_Updeadline(t *testing.T) {
 			context, w := prepareTaskTT(t)
 
 			// httpRequest
-			context.Request, _ = http.NewRequest("PUT", "/task", bytes.NewBufferString(tt.body))
+			context.Request, _ = http.NewRequest("POST", "/task", bytes.NewBufferString(tt.body))
 			if tt.userid != "" {
 				context.Request.AddCookie(&http.Cookie{
 					Name:  "id",
@@ -416,61 +582,2004 @@ func TestTaskController_Updeadline(t *testing.T) {
 				})
 			}
 			setParams(t, tt, context)
+			setHeader(t, tt, context)
 
 			// モック,コントローラーの準備
 			ctrl, taskController := prepareMockTaskCtrl(t, tt)
 			defer ctrl.Finish()
 
-			taskController.Update(context)
+			taskController.GetByID(context)
 
 			compareResult(t, w, tt)
 		})
 	}
 }
 
-func TestTaskController_Delete(t *testing.T) {
+func TestTaskController_List(t *testing.T) {
+
+	taskA := entity.NewTask(uuidTA, "titleA", "", uuidUA, "2020-12-27")
+	taskA.Priority = entity.PriorityHigh
+	taskA.Position = "V"
+	taskB := entity.NewTask(uuidTB, "titleB", "", uuidUA, "2020-12-27")
+	taskB.Position = "k"
+
+	// 2020-11-30(月)から毎週繰り返すタスク
+	weekly := entity.NewTask(uuidTC, "weekly", "", uuidUA, "2020-11-30")
+	weekly.RRule = entity.NewNullString("FREQ=WEEKLY")
+	occurrences := []*entity.Task{}
+	for _, date := range []string{"2020-12-07", "2020-12-14"} {
+		occurrence := *weekly
+		occurrence.Deadline = entity.NewNullDate(date)
+		occurrence.Virtual = true
+		occurrences = append(occurrences, &occurrence)
+	}
+	taskD := entity.NewTask(uuidTB, "titleD", "", uuidUA, "2020-12-10")
 
 	tests := []testInfo{
 		{
-			name:   "正しくTaskを削除できる",
+			name:   "期間を指定すると繰り返しのタスクの回を展開する",
 			userid: uuidUA,
-			params: map[string]string{"id": uuidTA},
+			query:  "from=2020-12-01&to=2020-12-14",
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Delete(uuidTA, uuidUA).Return(nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					From: entity.NewNullDate("2020-12-01"),
+					To:   entity.NewNullDate("2020-12-14"),
+					Sort: repository.SortByPosition,
+				}).Return([]*entity.Task{weekly, taskD}, nil)
 			},
 			wantErr:  false,
 			wantCode: http.StatusOK,
-			wantData: nil,
+			wantData: []*entity.Task{occurrences[0], taskD, occurrences[1]},
 		},
 		{
-			name:   "DBにTaskがないときはErrTaskNotFound",
+			name:   "期間が1年より長いならStatusBadRequest",
 			userid: uuidUA,
-			params: map[string]string{"id": uuidTA},
+			query:  "from=2020-01-01&to=2021-12-31",
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
-				task.EXPECT().Delete(uuidTA, uuidUA).Return(entity.ErrRecordNotFound)
 			},
 			wantErr:  true,
-			wantCode: http.StatusNotFound,
-			wantData: ErrTaskNotFound.Error(),
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
 		},
 		{
-			name:   "Cookieが空ならStatusUnauthorized",
-			params: map[string]string{"id": uuidTA},
+			name:   "fromのみならStatusBadRequest",
+			userid: uuidUA,
+			query:  "from=2020-01-01",
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
 			},
 			wantErr:  true,
-			wantCode: http.StatusUnauthorized,
-			wantData: ErrUnauthorized.Error(),
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
 		},
 		{
-			name:   "TaskIDが空ならErrBadRequest",
+			name:   "日付を指定してタスクの一覧を取得できる",
 			userid: uuidUA,
+			query:  "date=2020-12-27",
 			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					Date: entity.NewNullDate("2020-12-27"),
+					Sort: repository.SortByPosition,
+				}).Return([]*entity.Task{taskA, taskB}, nil)
 			},
-			wantErr:  true,
-			wantCode: http.StatusBadRequest,
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{taskA, taskB},
+		},
+		{
+			name:   "優先度順に並べられる",
+			userid: uuidUA,
+			query:  "sort=priority",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					Sort: repository.SortByPriority,
+				}).Return([]*entity.Task{taskA, taskB}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{taskA, taskB},
+		},
+		{
+			name:   "タグで絞り込める",
+			userid: uuidUA,
+			query:  "tag=tagA&tag=tagB",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					TagIDs: []string{"tagA", "tagB"},
+				}).Return([]*entity.Task{taskA}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{taskA},
+		},
+		{
+			name:   "プロジェクトで絞り込める",
+			userid: uuidUA,
+			query:  "project=" + uuidPA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					ProjectID: uuidPA,
+				}).Return([]*entity.Task{taskA}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{taskA},
+		},
+		{
+			name:   "project=inboxならプロジェクトに属さないタスクを取得する",
+			userid: uuidUA,
+			query:  "project=inbox",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					Inbox: true,
+				}).Return([]*entity.Task{taskB}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{taskB},
+		},
+		{
+			name:   "共有されたタスクとプロジェクトのタスクを共有された権限とともに取得する",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					SharedTaskIDs:    []string{uuidTB},
+					SharedProjectIDs: []string{uuidPA},
+				}).Return([]*entity.Task{
+					entity.NewTask(uuidTA, "titleA", "", uuidUA, "2020-12-27"),
+					entity.NewTask(uuidTB, "titleB", "", uuidUB, "2020-12-27"),
+				}, nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{
+					acceptedShare(entity.ShareTask, uuidTB, entity.RoleEditor),
+					acceptedShare(entity.ShareProject, uuidPA, entity.RoleViewer),
+				}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{
+				entity.NewTask(uuidTA, "titleA", "", uuidUA, "2020-12-27"),
+				{ID: entity.NewNullString(uuidTB), Title: entity.NewNullString("titleB"), Deadline: entity.NewNullDate("2020-12-27"), Role: entity.RoleEditor},
+			},
+		},
+		{
+			name:   "タスクがなければ空の配列",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{}).Return([]*entity.Task{}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{},
+		},
+		{
+			name:   "sortが不正ならStatusBadRequest",
+			userid: uuidUA,
+			query:  "sort=title",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "dateが不正ならStatusBadRequest",
+			userid: uuidUA,
+			query:  "date=2020-13-01",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task?"+tt.query, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.List(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Search(t *testing.T) {
+
+	taskA := entity.NewTask(uuidTA, "Weekly meeting", "Prepare the <agenda> for the meeting.", uuidUA, "2020-01-02")
+	taskB := entity.NewTask(uuidTB, "会議室の予約", "", uuidUA, "2020-01-03")
+
+	tests := []testInfo{
+		{
+			name:   "一致したタスクを一致度の順に強調して取得できる",
+			userid: uuidUA,
+			query:  "q=meeting",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Search(gomock.Any(), uuidUA, []entity.SearchTerm{{Text: "meeting"}}, 50).
+					Return([]*entity.SearchResult{{Task: taskA, Score: 1.5}}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.SearchResult{{
+				Task:    taskA,
+				Score:   1.5,
+				Title:   "Weekly <mark>meeting</mark>",
+				Snippet: "Prepare the &lt;agenda&gt; for the <mark>meeting</mark>.",
+			}},
+		},
+		{
+			name:   "句と前方一致の語で検索できる",
+			userid: uuidUA,
+			query:  "q=" + url.QueryEscape(`"会議室" 予約*`),
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Search(gomock.Any(), uuidUA, []entity.SearchTerm{
+					{Text: "会議室", Phrase: true},
+					{Text: "予約", Prefix: true},
+				}, 50).Return([]*entity.SearchResult{{Task: taskB, Score: 1}}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.SearchResult{{
+				Task:  taskB,
+				Score: 1,
+				Title: "<mark>会議室</mark>の<mark>予約</mark>",
+			}},
+		},
+		{
+			name:   "一致するタスクがなければ空",
+			userid: uuidUA,
+			query:  "q=nothing",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Search(gomock.Any(), uuidUA, gomock.Any(), 50).Return([]*entity.SearchResult{}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.SearchResult{},
+		},
+		{
+			name:   "qが空ならStatusBadRequest",
+			userid: uuidUA,
+			query:  "q=" + url.QueryEscape(` "" `),
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "qが長すぎるならStatusBadRequest",
+			userid: uuidUA,
+			query:  "q=" + strings.Repeat("a", 257),
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "DBへのクエリがタイムアウトしたらStatusGatewayTimeout",
+			userid: uuidUA,
+			query:  "q=meeting",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Search(gomock.Any(), uuidUA, gomock.Any(), 50).Return(nil, context.DeadlineExceeded)
+			},
+			wantErr:  true,
+			wantCode: http.StatusGatewayTimeout,
+			wantData: ErrTimeout.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/search?"+tt.query, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Search(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Overdue(t *testing.T) {
+
+	date := func(s string) *entity.NullDate {
+		d := entity.NewNullDate(s)
+		return &d
+	}
+	overdue := entity.NewTask(uuidTA, "titleA", "", uuidUA, "2019-12-30")
+
+	tests := []testInfo{
+		{
+			name:   "UTCで今日より前の未完了のタスクを取得できる",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					Before:     entity.NewNullDate("2020-01-01"),
+					Incomplete: true,
+				}).Return([]*entity.Task{entity.NewTask(uuidTA, "titleA", "", uuidUA, "2019-12-30")}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				To:    date("2019-12-31"),
+				Count: 1,
+				Tasks: []*entity.Task{{
+					ID:       overdue.ID,
+					Title:    overdue.Title,
+					UserID:   overdue.UserID,
+					Deadline: overdue.Deadline,
+					Overdue:  true,
+				}},
+			},
+		},
+		{
+			name:   "ユーザーのタイムゾーンでの今日より前のタスクを取得する",
+			userid: uuidUA,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := entity.NewUser(uuidUA, "username", "", "user@example.com")
+				u.TimeZone = entity.NewNullString("America/Los_Angeles")
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil).AnyTimes()
+			},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					Before:     entity.NewNullDate("2019-12-31"),
+					Incomplete: true,
+				}).Return([]*entity.Task{}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				To:    date("2019-12-30"),
+				Count: 0,
+				Tasks: []*entity.Task{},
+			},
+		},
+		{
+			name:   "DBへのクエリがタイムアウトしたらStatusGatewayTimeout",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, gomock.Any()).Return(nil, context.DeadlineExceeded)
+			},
+			wantErr:  true,
+			wantCode: http.StatusGatewayTimeout,
+			wantData: ErrTimeout.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/overdue", nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Overdue(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Today(t *testing.T) {
+
+	date := func(s string) *entity.NullDate {
+		d := entity.NewNullDate(s)
+		return &d
+	}
+	done := entity.NewTask(uuidTA, "titleA", "", uuidUA, "2020-01-01").SetComp(true)
+	todo := entity.NewTask(uuidTB, "titleB", "", uuidUA, "2020-01-01")
+
+	// 2019-12-24から毎週繰り返すタスク
+	weekly := entity.NewTask(uuidTC, "weekly", "", uuidUA, "2019-12-24")
+	weekly.RRule = entity.NewNullString("FREQ=WEEKLY")
+	occurrence := *weekly
+	occurrence.Deadline = entity.NewNullDate("2019-12-31")
+	occurrence.Virtual = true
+
+	tests := []testInfo{
+		{
+			name:   "UTCで今日のタスクと未完了の件数を取得できる",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					From: entity.NewNullDate("2020-01-01"),
+					To:   entity.NewNullDate("2020-01-01"),
+				}).Return([]*entity.Task{done, todo}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				From:  date("2020-01-01"),
+				To:    date("2020-01-01"),
+				Count: 1,
+				Tasks: []*entity.Task{done, todo},
+			},
+		},
+		{
+			name:   "ユーザーのタイムゾーンでの今日の繰り返しのタスクの回を展開する",
+			userid: uuidUA,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := entity.NewUser(uuidUA, "username", "", "user@example.com")
+				u.TimeZone = entity.NewNullString("America/Los_Angeles")
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil).AnyTimes()
+			},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					From: entity.NewNullDate("2019-12-31"),
+					To:   entity.NewNullDate("2019-12-31"),
+				}).Return([]*entity.Task{weekly}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				From:  date("2019-12-31"),
+				To:    date("2019-12-31"),
+				Count: 1,
+				Tasks: []*entity.Task{&occurrence},
+			},
+		},
+		{
+			name:   "DBへのクエリがタイムアウトしたらStatusGatewayTimeout",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, gomock.Any()).Return(nil, context.DeadlineExceeded)
+			},
+			wantErr:  true,
+			wantCode: http.StatusGatewayTimeout,
+			wantData: ErrTimeout.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/today", nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Today(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Upcoming(t *testing.T) {
+
+	date := func(s string) *entity.NullDate {
+		d := entity.NewNullDate(s)
+		return &d
+	}
+	taskA := entity.NewTask(uuidTA, "titleA", "", uuidUA, "2020-01-03")
+
+	tests := []testInfo{
+		{
+			name:   "daysを省略すると明日から7日間のタスクを取得する",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					From: entity.NewNullDate("2020-01-02"),
+					To:   entity.NewNullDate("2020-01-08"),
+				}).Return([]*entity.Task{taskA}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				From:  date("2020-01-02"),
+				To:    date("2020-01-08"),
+				Count: 1,
+				Tasks: []*entity.Task{taskA},
+			},
+		},
+		{
+			name:   "daysで日数を指定できる",
+			userid: uuidUA,
+			query:  "days=3",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{
+					From: entity.NewNullDate("2020-01-02"),
+					To:   entity.NewNullDate("2020-01-04"),
+				}).Return([]*entity.Task{taskA}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.TaskView{
+				From:  date("2020-01-02"),
+				To:    date("2020-01-04"),
+				Count: 1,
+				Tasks: []*entity.Task{taskA},
+			},
+		},
+		{
+			name:   "daysが0ならStatusBadRequest",
+			userid: uuidUA,
+			query:  "days=0",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "daysが1年より長いならStatusBadRequest",
+			userid: uuidUA,
+			query:  "days=367",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "daysが数値でないならStatusBadRequest",
+			userid: uuidUA,
+			query:  "days=week",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/upcoming?"+tt.query, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Upcoming(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Reorder(t *testing.T) {
+
+	// 同じ日のタスクA, B, Cを用意する
+	prepareDay := func(positions ...string) []*entity.Task {
+		ids := []string{uuidTA, uuidTB, uuidTC}
+		tasks := []*entity.Task{}
+		for i, p := range positions {
+			task := entity.NewTask(ids[i], "title", "", uuidUA, "2020-12-27")
+			task.Position = p
+			tasks = append(tasks, task)
+		}
+		return tasks
+	}
+	query := repository.TaskQuery{Date: entity.NewNullDate("2020-12-27"), Sort: repository.SortByPosition}
+
+	tests := []testInfo{
+		{
+			name:   "指定したタスクの直後に移動する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTC},
+			body:   `{"after":"` + uuidTA + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				day := prepareDay("V", "k", "t")
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).Return(day[2], nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, query).Return(day, nil)
+				task.EXPECT().UpdatePositions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						// 移動するタスクのみを更新する
+						if len(tasks) != 1 || tasks[0].Position != "c" {
+							t.Errorf("UpdatePositions got %v", tasks)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: func() *entity.Task {
+				task := entity.NewTask(uuidTC, "title", "", uuidUA, "2020-12-27")
+				task.Position = "c"
+				return task
+			}(),
+		},
+		{
+			name:   "Positionが重複していればその日のタスクの順番を振り直す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"before":"` + uuidTC + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				day := prepareDay("", "", "")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(day[0], nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, query).Return(day, nil)
+				task.EXPECT().UpdatePositions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						// B, A, Cの順に振り直す
+						want := []string{uuidTB, uuidTA, uuidTC}
+						if len(tasks) != 3 {
+							t.Fatalf("UpdatePositions got %v", tasks)
+						}
+						for i, task := range tasks {
+							if task.ID.String() != want[i] || (i > 0 && tasks[i-1].Position >= task.Position) {
+								t.Errorf("UpdatePositions got %v", tasks)
+							}
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: func() *entity.Task {
+				task := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				task.Position = entity.EvenRanks(3)[1]
+				return task
+			}(),
+		},
+		{
+			name:   "基準のタスクが別の日ならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"after":"` + uuidTC + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				day := prepareDay("V", "k")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(day[0], nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, query).Return(day, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "afterとbeforeの両方を指定したらStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"after":"` + uuidTB + `","before":"` + uuidTC + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "DBにTaskがないときはErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"after":"` + uuidTB + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "If-Matchが現在のETagに一致すれば行をロックして確かめてから移動し，新しいETagを返す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTC},
+			header: map[string]string{"If-Match": `"3"`},
+			body:   `{"after":"` + uuidTA + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				day := prepareDay("V", "k", "t")
+				day[2].Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).Return(day[2], nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, query).Return(day, nil)
+				task.EXPECT().CheckVersion(gomock.Any(), uuidTC, uuidUA, 3).Return(nil)
+				task.EXPECT().UpdatePositions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						tasks[0].Version++
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: func() *entity.Task {
+				task := entity.NewTask(uuidTC, "title", "", uuidUA, "2020-12-27")
+				task.Position = "c"
+				return task
+			}(),
+			wantHeader: map[string]string{"ETag": `"4"`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task/"+tt.params["id"]+"/position", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Reorder(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Updeadline(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "全フィールドを更新できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"content":"I am new content.",
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.CreatedAt = time.Unix(100, 0)
+						task.UpdatedAt = time.Unix(100, 0)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: entity.NewTask(uuidTA, "newtitle", "I am new content.", "", "2020-01-05").SetComp(true),
+		},
+		{
+			name:   "If-Matchが現在のETagに一致すればバージョンを指定して更新し，新しいETagを返す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						if task.Version != 3 {
+							return entity.ErrVersionConflict
+						}
+						task.Version = 4
+						return nil
+					})
+			},
+			wantErr:    false,
+			wantCode:   http.StatusOK,
+			wantData:   entity.NewTask(uuidTA, "newtitle", "", "", "2020-01-05"),
+			wantHeader: map[string]string{"ETag": `"4"`},
+		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"2"`},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+		{
+			name:   "確かめてから保存するまでに他で更新されたならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).Return(entity.ErrVersionConflict)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+		{
+			name:   "Reminderの時刻の計算し直しに失敗すれば更新を取り消してStatusInternalServerError",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().FindByTask(gomock.Any(), uuidTA, uuidUA).Return(nil, errors.New("reminder unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 更新したTaskもロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name:   "期限を変更すると期限の何前に通知する未送信のReminderの時刻を計算し直す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05",
+				"due_time":"10:00"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				sentAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				reminder.EXPECT().FindByTask(gomock.Any(), uuidTA, uuidUA).Return([]*entity.Reminder{
+					{ID: entity.NewNullString("offset"), Before: entity.NewNullString("1h0m0s")},
+					{ID: entity.NewNullString("absolute"), RemindAt: sentAt},
+					{ID: entity.NewNullString("sent"), Before: entity.NewNullString("1h0m0s"), SentAt: &sentAt},
+				}, nil)
+				reminder.EXPECT().Reschedule(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, reminders []*entity.Reminder) error {
+						// 期待と異なる場合はエラーにしてレスポンスのcodeで検出する
+						want := time.Date(2020, 1, 5, 9, 0, 0, 0, time.UTC)
+						if len(reminders) != 1 || reminders[0].ID.String() != "offset" || !reminders[0].RemindAt.Equal(want) {
+							return fmt.Errorf("unexpected reminders: %v", reminders)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("newtitle"),
+				Deadline: entity.NewNullDate("2020-01-05"),
+				DueTime:  entity.NewNullString("10:00"),
+			},
+		},
+		{
+			name:   "editorとして共有されたタスクは所有者のタスクとして更新できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+				task.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						// 期待と異なる場合はエラーにしてレスポンスのcodeで検出する
+						if task.UserID.String() != uuidUB {
+							return fmt.Errorf("unexpected user: %s", task.UserID.String())
+						}
+						return nil
+					})
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().FindByTask(gomock.Any(), uuidTA, uuidUB).Return([]*entity.Reminder{}, nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Task{
+				ID:       entity.NewNullString(uuidTA),
+				Title:    entity.NewNullString("newtitle"),
+				Deadline: entity.NewNullDate("2020-01-05"),
+				Role:     entity.RoleEditor,
+			},
+		},
+		{
+			name:   "viewerとして共有されたタスクを更新しようとしたらStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleViewer)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "guestとして参加しているWorkspaceのタスクを更新しようとしたらStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				t := entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27")
+				t.WorkspaceID = entity.NewNullString(uuidWA)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(t, nil)
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Membership{member(uuidMA, uuidUA, entity.WorkspaceGuest)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "フィールドが足りないならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "RequestBodyが不正ならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"id":"taskid",
+				"name":"newname"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "RequestBodyがJSONでないならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `aaaaa`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "DBにTaskがないときはErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"content":"I am new content.",
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "DBにUserがないときはErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"content":"I am new content.",
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "Cookieが空ならStatusUnauthorized",
+			params: map[string]string{"id": uuidTA},
+			body: `{
+				"title":"newtitle",
+				"content":"I am new content.",
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(user *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+		{
+			name:   "TaskIDが空ならErrBadRequest",
+			userid: uuidUA,
+			body: `{
+				"title":"newtitle",
+				"content":"I am new content.",
+				"iscomp":true,
+				"deadline":"2020-01-05"
+			}`,
+			prepareMockTaskRepo: func(user *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Update(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Delete(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "正しくTaskを削除できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTA, uuidUA, 0).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "Activityの記録に失敗すれば削除を取り消してStatusInternalServerError",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTA, uuidUA, 0).Return(nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("activity unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 削除もロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name:   "If-Matchが現在のETagに一致すればバージョンを指定して削除する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTA, uuidUA, 3).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"2"`},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+		{
+			name:   "If-Matchが*ならバージョンを比べずに削除する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `*`},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTA, uuidUA, 0).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "DBにTaskがないときはErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "共有されたタスクは所有者しか削除できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27"), nil)
+			},
+			prepareMockShareRepo: func(share *mock_repository.MockShareRepository) {
+				share.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Share{acceptedShare(entity.ShareTask, uuidTA, entity.RoleEditor)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "Cookieが空ならStatusUnauthorized",
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+		{
+			name:   "TaskIDが空ならErrBadRequest",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Batch(t *testing.T) {
+
+	completed := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27").SetComp(true)
+	aborted := &batchResult{Code: http.StatusFailedDependency, Error: ErrBatchAborted.Error()}
+
+	tests := []testInfo{
+		{
+			name:   "すべての操作が成功すれば操作ごとの結果を返す",
+			userid: uuidUA,
+			body: `{"operations":[
+				{"op":"create","task":{"title":"taskname","deadline":"2020-12-06"}},
+				{"op":"complete","id":"` + uuidTA + `","iscomp":true},
+				{"op":"delete","id":"` + uuidTB + `"}
+			]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.SetID("any id")
+						return nil
+					})
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().UpdateCompletions(gomock.Any(), uuidUA, gomock.Any()).Return(nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(entity.NewTask(uuidTB, "B", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTB, uuidUA, 0).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &batchResponse{Results: []*batchResult{
+				{Code: http.StatusOK, Task: entity.NewTask("any id", "taskname", "", "", "2020-12-06")},
+				{Code: http.StatusOK, Task: completed},
+				{Code: http.StatusOK},
+			}},
+		},
+		{
+			name:   "ひとつでも失敗すればすべて取り消し，失敗した操作のステータスコードを返す",
+			userid: uuidUA,
+			body: `{"operations":[
+				{"op":"delete","id":"` + uuidTA + `"},
+				{"op":"delete","id":"` + uuidTB + `"},
+				{"op":"complete","id":"` + uuidTC + `","iscomp":true}
+			]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTA, uuidUA, 0).Return(nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 失敗した操作のエラーでロールバックさせる
+						err := fn(ctx)
+						if !errors.Is(err, entity.ErrRecordNotFound) {
+							t.Errorf("Transaction fn got %v", err)
+						}
+						return err
+					})
+				// 各操作のトランザクションは外側のトランザクションの中で実行する
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).AnyTimes()
+			},
+			wantErr:  false,
+			wantCode: http.StatusNotFound,
+			wantData: &batchResponse{Results: []*batchResult{
+				aborted,
+				{Code: http.StatusNotFound, Error: ErrTaskNotFound.Error()},
+				aborted,
+			}},
+		},
+		{
+			name:   "continueOnErrorなら操作ごとに実行し，失敗しても残りの操作を続ける",
+			userid: uuidUA,
+			body: `{"continueOnError":true,"operations":[
+				{"op":"delete","id":"` + uuidTB + `"},
+				{"op":"update","id":"` + uuidTA + `","if_match":"\"2\"","task":{"title":"A","deadline":"2020-12-27"}},
+				{"op":"delete","id":"` + uuidTC + `"}
+			]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				current := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).Return(entity.NewTask(uuidTC, "C", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Delete(gomock.Any(), uuidTC, uuidUA, 0).Return(nil)
+			},
+			// 全体のトランザクションは使わず，実行した削除のトランザクションのみを使う
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).Times(1)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &batchResponse{Results: []*batchResult{
+				{Code: http.StatusNotFound, Error: ErrTaskNotFound.Error()},
+				{Code: http.StatusPreconditionFailed, Error: ErrPreconditionFailed.Error()},
+				{Code: http.StatusOK},
+			}},
+		},
+		{
+			name:   "不明な操作が含まれていればStatusBadRequest",
+			userid: uuidUA,
+			body:   `{"operations":[{"op":"archive","id":"` + uuidTA + `"}]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "completeにiscompがなければStatusBadRequest",
+			userid: uuidUA,
+			body:   `{"operations":[{"op":"complete","id":"` + uuidTA + `"}]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "操作がなければStatusBadRequest",
+			userid: uuidUA,
+			body:   `{"operations":[]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "操作が上限を超えればStatusBadRequest",
+			userid: uuidUA,
+			body:   `{"operations":[` + strings.Repeat(`{"op":"delete","id":"`+uuidTA+`"},`, usecase.MaxBatchSize) + `{"op":"delete","id":"` + uuidTA + `"}]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			body: `{"operations":[{"op":"delete","id":"` + uuidTA + `"}]}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/task/batch", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Batch(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_CreateSubtask(t *testing.T) {
+
+	sub := entity.NewTask("any id", "subtask", "", uuidUA, "2020-12-27")
+	sub.ParentID = entity.NewNullString(uuidTA)
+
+	tests := []testInfo{
+		{
+			name:   "親の日付を引き継いでサブタスクを作成できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"title":"subtask"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).
+					Return(entity.NewTask(uuidTA, "parent", "", uuidUA, "2020-12-27"), nil)
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.SetID("any id")
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: sub,
+		},
+		{
+			name:   "親の深さが上限ならErrTaskTooDeep",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"title":"subtask"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				// C > B > A の順に入れ子になっている
+				a := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+				a.ParentID = entity.NewNullString(uuidTB)
+				b := entity.NewTask(uuidTB, "B", "", uuidUA, "2020-12-27")
+				b.ParentID = entity.NewNullString(uuidTC)
+				c := entity.NewTask(uuidTC, "C", "", uuidUA, "2020-12-27")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).Return(c, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrTaskTooDeep.Error(),
+		},
+		{
+			name:   "他のユーザーのタスクにはサブタスクを作成できない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"title":"subtask"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+		{
+			name:   "If-Matchが親の現在のETagに一致すれば親の行をロックして確かめてから作成し，作成したサブタスクのETagを返す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			body:   `{"title":"subtask"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				parent := entity.NewTask(uuidTA, "parent", "", uuidUA, "2020-12-27")
+				parent.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(parent, nil)
+				task.EXPECT().CheckVersion(gomock.Any(), uuidTA, uuidUA, 3).Return(nil)
+				task.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.SetID("any id")
+						task.Version = 1
+						return nil
+					})
+			},
+			wantErr:    false,
+			wantCode:   http.StatusOK,
+			wantData:   sub,
+			wantHeader: map[string]string{"ETag": `"1"`},
+		},
+		{
+			name:   "If-Matchが親の現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"2"`},
+			body:   `{"title":"subtask"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				parent := entity.NewTask(uuidTA, "parent", "", uuidUA, "2020-12-27")
+				parent.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(parent, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/task/"+tt.params["id"]+"/subtask", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.CreateSubtask(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Move(t *testing.T) {
+
+	// BはAのサブタスクである
+	prepareTasks := func() (a, b *entity.Task) {
+		a = entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+		b = entity.NewTask(uuidTB, "B", "", uuidUA, "2020-12-27")
+		b.ParentID = entity.NewNullString(uuidTA)
+		return
+	}
+	moved, _ := prepareTasks()
+	moved.ParentID = entity.NewNullString(uuidTC)
+	moved.Position = "V"
+	_, root := prepareTasks()
+	root.ParentID = entity.NullString{}
+	root.Position = "V"
+
+	tests := []testInfo{
+		{
+			name:   "他のタスクのサブタスクにできる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"parent_id":"` + uuidTC + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				a, b := prepareTasks()
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).
+					Return(entity.NewTask(uuidTC, "C", "", uuidUA, "2020-12-27"), nil)
+				// AにはサブタスクBがあるので高さは2
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{ParentID: uuidTA}).Return([]*entity.Task{b}, nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{ParentID: uuidTB}).Return([]*entity.Task{}, nil)
+				task.EXPECT().UpdateParent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.Position = "V"
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: moved,
+		},
+		{
+			name:   "parent_idがnullなら親のないタスクにする",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			body:   `{"parent_id":null}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				_, b := prepareTasks()
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+				task.EXPECT().UpdateParent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, task *entity.Task) error {
+						task.Position = "V"
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: root,
+		},
+		{
+			name:   "自分のサブタスクを親にはできない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"parent_id":"` + uuidTB + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).
+					DoAndReturn(func(context.Context, string, string) (*entity.Task, error) {
+						a, _ := prepareTasks()
+						return a, nil
+					}).Times(2)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).
+					DoAndReturn(func(context.Context, string, string) (*entity.Task, error) {
+						_, b := prepareTasks()
+						return b, nil
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrTaskCycle.Error(),
+		},
+		{
+			name:   "自分自身を親にはできない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"parent_id":"` + uuidTA + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).
+					DoAndReturn(func(context.Context, string, string) (*entity.Task, error) {
+						a, _ := prepareTasks()
+						return a, nil
+					}).Times(2)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrTaskCycle.Error(),
+		},
+		{
+			name:   "他のユーザーのタスクは親にできない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"parent_id":"` + uuidTC + `"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				a, _ := prepareTasks()
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTC, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrParentTaskNotFound.Error(),
+		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			header: map[string]string{"If-Match": `"2"`},
+			body:   `{"parent_id":null}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				_, b := prepareTasks()
+				b.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task/"+tt.params["id"]+"/parent", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Move(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Complete(t *testing.T) {
+
+	// BとCはAのサブタスクである
+	prepareTasks := func(compA, compB, compC bool) (a, b, c *entity.Task) {
+		a = entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27").SetComp(compA)
+		b = entity.NewTask(uuidTB, "B", "", uuidUA, "2020-12-27").SetComp(compB)
+		b.ParentID = entity.NewNullString(uuidTA)
+		c = entity.NewTask(uuidTC, "C", "", uuidUA, "2020-12-27").SetComp(compC)
+		c.ParentID = entity.NewNullString(uuidTA)
+		return
+	}
+	_, completed, _ := prepareTasks(true, true, true)
+	_, reopened, _ := prepareTasks(false, false, true)
+	children := repository.TaskQuery{ParentID: uuidTA}
+	recurred := entity.NewTask(uuidTA, "weekly", "", uuidUA, "2020-12-07").SetComp(true)
+
+	tests := []testInfo{
+		{
+			name:   "繰り返しのタスクを完了すると次の回を作成する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				weekly := entity.NewTask(uuidTA, "weekly", "", uuidUA, "2020-12-07")
+				weekly.RRule = entity.NewNullString("FREQ=WEEKLY;COUNT=3")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(weekly, nil)
+				task.EXPECT().Recur(gomock.Any(), weekly, gomock.Any()).
+					DoAndReturn(func(_ context.Context, done, next *entity.Task) error {
+						if next.Deadline.String() != "2020-12-14" || next.RRule.String() != "FREQ=WEEKLY;COUNT=2" || next.Title.String() != "weekly" {
+							t.Errorf("Recur next got %s, %s", next, next.RRule.String())
+						}
+						done.RRule = entity.NullString{}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: recurred,
+		},
+		{
+			name:   "次の回へのReminderの引き継ぎに失敗すれば完了を取り消してStatusInternalServerError",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				weekly := entity.NewTask(uuidTA, "weekly", "", uuidUA, "2020-12-07")
+				weekly.RRule = entity.NewNullString("FREQ=WEEKLY;COUNT=3")
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(weekly, nil)
+				task.EXPECT().Recur(gomock.Any(), weekly, gomock.Any()).Return(nil)
+			},
+			prepareMockReminderRepo: func(reminder *mock_repository.MockReminderRepository) {
+				reminder.EXPECT().FindByTask(gomock.Any(), uuidTA, uuidUA).Return([]*entity.Reminder{
+					{ID: entity.NewNullString("offset"), Before: entity.NewNullString("1h0m0s")},
+				}, nil)
+				reminder.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("reminder unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 完了したTaskと作成した次の回もロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name:   "すべてのサブタスクが完了すると親も完了する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				a, b, c := prepareTasks(false, false, true)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, children).Return([]*entity.Task{b, c}, nil)
+				task.EXPECT().UpdateCompletions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						if len(tasks) != 2 || !tasks[0].IsCompleted || !tasks[1].IsCompleted || tasks[1].ID.String() != uuidTA {
+							t.Errorf("UpdateCompletions got %v", tasks)
+						}
+						return nil
+					})
+			},
+			// 完了状態が変わった親もActivityに記録する
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				want := entity.Changes{{Field: "iscomp", Before: false, After: true}}
+				for _, id := range []string{uuidTB, uuidTA} {
+					activity.EXPECT().Create(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, a *entity.Activity) error {
+							if a.EntityID.String() != id || a.Action != entity.ActionComplete || cmp.Diff(want, a.Changes) != "" {
+								t.Errorf("Activity got = %v", a)
+							}
+							return nil
+						})
+				}
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: completed,
+		},
+		{
+			name:   "未完了のサブタスクが残っていれば親は完了しない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				a, b, c := prepareTasks(false, false, false)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, children).Return([]*entity.Task{b, c}, nil)
+				task.EXPECT().UpdateCompletions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						if len(tasks) != 1 {
+							t.Errorf("UpdateCompletions got %v", tasks)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: completed,
+		},
+		{
+			name:   "サブタスクを未完了に戻すと親も未完了に戻る",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			body:   `{"iscomp":false}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				a, b, c := prepareTasks(true, true, true)
+				task.EXPECT().FindByID(gomock.Any(), uuidTB, uuidUA).Return(b, nil)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(a, nil)
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, children).Return([]*entity.Task{b, c}, nil)
+				task.EXPECT().UpdateCompletions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						if len(tasks) != 2 || tasks[0].IsCompleted || tasks[1].IsCompleted {
+							t.Errorf("UpdateCompletions got %v", tasks)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: reopened,
+		},
+		{
+			name:   "Requestにiscompが含まれていないならStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTB},
+			body:   `{}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
 			wantData: ErrBadRequest.Error(),
 		},
+		{
+			name:   "If-Matchが現在のETagに一致すれば行をロックして確かめてから完了し，新しいETagを返す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().CheckVersion(gomock.Any(), uuidTA, uuidUA, 3).Return(nil)
+				task.EXPECT().UpdateCompletions(gomock.Any(), uuidUA, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, tasks []*entity.Task) error {
+						tasks[0].Version++
+						return nil
+					})
+			},
+			wantErr:    false,
+			wantCode:   http.StatusOK,
+			wantData:   entity.NewTask(uuidTA, "A", "", "", "2020-12-27").SetComp(true),
+			wantHeader: map[string]string{"ETag": `"4"`},
+		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"2"`},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+		{
+			name:   "確かめてから保存するまでに他で更新されたならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"3"`},
+			body:   `{"iscomp":true}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "A", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+				task.EXPECT().CheckVersion(gomock.Any(), uuidTA, uuidUA, 3).Return(entity.ErrVersionConflict)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -479,20 +2588,295 @@ func TestTaskController_Delete(t *testing.T) {
 			context, w := prepareTaskTT(t)
 
 			// httpRequest
-			context.Request, _ = http.NewRequest("PUT", "/task", bytes.NewBufferString(tt.body))
-			if tt.userid != "" {
-				context.Request.AddCookie(&http.Cookie{
-					Name:  "id",
-					Value: tt.userid,
-				})
-			}
-			setParams(t, tt, context)
+			context.Request, _ = http.NewRequest("PUT", "/task/"+tt.params["id"]+"/comp", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+			setHeader(t, tt, context)
 
 			// モック,コントローラーの準備
 			ctrl, taskController := prepareMockTaskCtrl(t, tt)
 			defer ctrl.Finish()
 
-			taskController.Delete(context)
+			taskController.Complete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_Assign(t *testing.T) {
+
+	// workspaceTask はuuidUAが作成したuuidWAのWorkspaceのTaskである
+	workspaceTask := func() *entity.Task {
+		t := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+		t.WorkspaceID = entity.NewNullString(uuidWA)
+		return t
+	}
+	findGuest := func(user *mock_repository.MockUserRepository) {
+		user.EXPECT().FindByEmail(gomock.Any(), "guest@example.com").Return(entity.NewUser(uuidUB, "guest", "", "guest@example.com"), nil)
+		findUsers(user)
+	}
+	assigned := workspaceTask()
+	assigned.AssigneeID = entity.NewNullString(uuidUB)
+	assigned.Assignee = entity.NewUser(uuidUB, "guest", "", "guest@example.com")
+
+	tests := []testInfo{
+		{
+			name:   "Workspaceのメンバーを担当者にでき，変更を記録する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(workspaceTask(), nil)
+			},
+			prepareMockUserRepo: findGuest,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().FindByUser(gomock.Any(), uuidUB).Return([]*entity.Membership{member(uuidMB, uuidUB, entity.WorkspaceMember)}, nil)
+			},
+			prepareMockAssignmentRepo: func(assignment *mock_repository.MockAssignmentRepository) {
+				assignment.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *entity.Assignment) error {
+						// 期待と異なる場合はエラーにしてレスポンスのcodeで検出する
+						if a.TaskID.String() != uuidTA || a.AssigneeID.String() != uuidUB || a.AssignerID.String() != uuidUA {
+							return fmt.Errorf("unexpected assignment: %v", a)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: assigned,
+		},
+		{
+			name:   "Taskにアクセスできないユーザーは担当者にできないのでStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(workspaceTask(), nil)
+			},
+			prepareMockUserRepo: findGuest,
+			wantErr:             true,
+			wantCode:            http.StatusBadRequest,
+			wantData:            ErrInvalidAssignee.Error(),
+		},
+		{
+			name:   "存在しないユーザーは担当者にできないのでStatusBadRequest",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"nobody@example.com"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(workspaceTask(), nil)
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "nobody@example.com").Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrInvalidAssignee.Error(),
+		},
+		{
+			name:   "emailが空なら担当者を外す",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":""}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := workspaceTask()
+				current.AssigneeID = entity.NewNullString(uuidUB)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+			},
+			prepareMockAssignmentRepo: func(assignment *mock_repository.MockAssignmentRepository) {
+				assignment.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *entity.Assignment) error {
+						if !a.AssigneeID.IsNull() {
+							return fmt.Errorf("unexpected assignee: %s", a.AssigneeID.String())
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: workspaceTask(),
+		},
+		{
+			name:   "担当者が変わらないなら記録しない",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":""}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(workspaceTask(), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: workspaceTask(),
+		},
+		{
+			name:   "guestとして参加しているWorkspaceのタスクの担当者は変更できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			body:   `{"email":"guest@example.com"}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := workspaceTask()
+				current.UserID = entity.NewNullString(uuidUB)
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+				task.EXPECT().Find(gomock.Any(), uuidTA).Return(current, nil)
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Membership{member(uuidMA, uuidUA, entity.WorkspaceGuest)}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			header: map[string]string{"If-Match": `"2"`},
+			body:   `{"email":""}`,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				current := entity.NewTask(uuidTA, "title", "", uuidUA, "2020-12-27")
+				current.Version = 3
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(current, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/task/"+uuidTA+"/assignee", bytes.NewBufferString(tt.body))
+			setCookieAndParams(t, tt, context)
+			setHeader(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.Assign(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_AssignedToMe(t *testing.T) {
+
+	subtask := entity.NewTask(uuidTA, "subtask", "", uuidUB, "2020-12-27")
+	subtask.ParentID = entity.NewNullString(uuidTB)
+	subtask.WorkspaceID = entity.NewNullString(uuidWA)
+	subtask.AssigneeID = entity.NewNullString(uuidUA)
+	subtask.Assignee = entity.NewUser(uuidUA, "username", "", "user@example.com")
+	want := *subtask
+	want.Role = entity.RoleEditor
+
+	tests := []testInfo{
+		{
+			name:   "自分が担当するWorkspaceのサブタスクを取得できる",
+			userid: uuidUA,
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByUser(gomock.Any(), uuidUA, repository.TaskQuery{AssigneeID: uuidUA, WorkspaceIDs: []string{uuidWA}}).
+					Return([]*entity.Task{subtask}, nil)
+			},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().FindByUser(gomock.Any(), uuidUA).Return([]*entity.Membership{member(uuidMA, uuidUA, entity.WorkspaceMember)}, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: []*entity.Task{&want},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/assigned-to-me", nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.AssignedToMe(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestTaskController_History(t *testing.T) {
+
+	activities := []*entity.Activity{{
+		ID:         entity.NewNullString(uuidSA),
+		ActorID:    entity.NewNullString(uuidUB),
+		Actor:      entity.NewUser(uuidUB, "username", "", "b@example.com"),
+		EntityType: entity.ActivityTask,
+		EntityID:   entity.NewNullString(uuidTA),
+		Action:     entity.ActionUpdate,
+		Changes:    entity.Changes{{Field: "title", Before: "title", After: "renamed"}},
+	}}
+
+	tests := []testInfo{
+		{
+			name:   "TaskのActivityを取得できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			query:  "limit=1",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(entity.NewTask(uuidTA, "renamed", "", uuidUA, "2020-12-27"), nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().FindByEntity(gomock.Any(), entity.ActivityTask, uuidTA, entity.Page{Limit: 1}).Return(activities, int64(3), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.ActivityPage{Total: 3, Limit: 1, Activities: activities},
+		},
+		{
+			name:                "limitが上限を超えるならStatusBadRequest",
+			userid:              uuidUA,
+			params:              map[string]string{"id": uuidTA},
+			query:               "limit=101",
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {},
+			wantErr:             true,
+			wantCode:            http.StatusBadRequest,
+			wantData:            ErrBadRequest.Error(),
+		},
+		{
+			name:   "アクセスできないTaskならErrTaskNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidTA},
+			prepareMockTaskRepo: func(task *mock_repository.MockTaskRepository) {
+				task.EXPECT().FindByID(gomock.Any(), uuidTA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrTaskNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareTaskTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/task/"+uuidTA+"/history?"+tt.query, nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, taskController := prepareMockTaskCtrl(t, tt)
+			defer ctrl.Finish()
+
+			taskController.History(context)
 
 			compareResult(t, w, tt)
 		})
@@ -515,11 +2899,95 @@ func prepareMockTaskCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, ta
 	ctrl = gomock.NewController(t)
 	taskRepo := mock_repository.NewMockTaskRepository(ctrl)
 	tt.prepareMockTaskRepo(taskRepo)
+	projectRepo := mock_repository.NewMockProjectRepository(ctrl)
+	if tt.prepareMockProjectRepo != nil {
+		tt.prepareMockProjectRepo(projectRepo)
+	}
+	// 期限を過ぎたかの判定に使うUserは指定しない場合はタイムゾーンのないUserとする
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	if tt.prepareMockUserRepo != nil {
+		tt.prepareMockUserRepo(userRepo)
+	} else {
+		userRepo.EXPECT().FindByID(gomock.Any(), gomock.Any()).Return(entity.NewUser(uuidUA, "username", "", "user@example.com"), nil).AnyTimes()
+	}
+
+	reminderRepo := mock_repository.NewMockReminderRepository(ctrl)
+	if tt.prepareMockReminderRepo != nil {
+		tt.prepareMockReminderRepo(reminderRepo)
+	} else {
+		reminderRepo.EXPECT().FindByTask(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*entity.Reminder{}, nil).AnyTimes()
+	}
+
+	shareRepo := prepareMockShareRepo(ctrl, tt)
+	membershipRepo := prepareMockMembershipRepo(ctrl, tt)
+	assignmentRepo := mock_repository.NewMockAssignmentRepository(ctrl)
+	if tt.prepareMockAssignmentRepo != nil {
+		tt.prepareMockAssignmentRepo(assignmentRepo)
+	}
+
+	activityRepo := prepareMockActivityRepo(ctrl, tt)
 
-	taskController = NewTaskController(taskRepo)
+	transactor := prepareMockTransactor(ctrl, tt)
+
+	taskController = NewTaskController(taskRepo, projectRepo, userRepo, reminderRepo, shareRepo, membershipRepo, assignmentRepo, activityRepo, transactor, logging.Discard())
+	taskController.Interactor.Clock = fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
 	return
 }
 
+// prepareMockTransactor はtt.prepareMockTransactorが未設定の場合はfnをそのまま実行するTransactorを返す
+func prepareMockTransactor(ctrl *gomock.Controller, tt testInfo) *mock_repository.MockTransactor {
+	transactor := mock_repository.NewMockTransactor(ctrl)
+	if tt.prepareMockTransactor != nil {
+		tt.prepareMockTransactor(transactor)
+	} else {
+		transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) }).AnyTimes()
+	}
+	return transactor
+}
+
+// prepareMockActivityRepo はtt.prepareMockActivityRepoが未設定の場合はActivityの記録のみを許可するActivityRepositoryを返す
+func prepareMockActivityRepo(ctrl *gomock.Controller, tt testInfo) *mock_repository.MockActivityRepository {
+	activityRepo := mock_repository.NewMockActivityRepository(ctrl)
+	if tt.prepareMockActivityRepo != nil {
+		tt.prepareMockActivityRepo(activityRepo)
+	} else {
+		activityRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	}
+	return activityRepo
+}
+
+// prepareMockShareRepo はtt.prepareMockShareRepoが未設定の場合は共有がないShareRepositoryを返す
+func prepareMockShareRepo(ctrl *gomock.Controller, tt testInfo) *mock_repository.MockShareRepository {
+	shareRepo := mock_repository.NewMockShareRepository(ctrl)
+	if tt.prepareMockShareRepo != nil {
+		tt.prepareMockShareRepo(shareRepo)
+	} else {
+		shareRepo.EXPECT().FindByUser(gomock.Any(), gomock.Any()).Return([]*entity.Share{}, nil).AnyTimes()
+	}
+	return shareRepo
+}
+
+// prepareMockMembershipRepo はtt.prepareMockMembershipRepoが未設定の場合はどのWorkspaceにも参加していないMembershipRepositoryを返す
+func prepareMockMembershipRepo(ctrl *gomock.Controller, tt testInfo) *mock_repository.MockMembershipRepository {
+	membershipRepo := mock_repository.NewMockMembershipRepository(ctrl)
+	if tt.prepareMockMembershipRepo != nil {
+		tt.prepareMockMembershipRepo(membershipRepo)
+	} else {
+		membershipRepo.EXPECT().FindByUser(gomock.Any(), gomock.Any()).Return([]*entity.Membership{}, nil).AnyTimes()
+	}
+	return membershipRepo
+}
+
+// fakeClock は固定した時刻を返すClockである
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
 func setParams(t *testing.T, tt testInfo, c *gin.Context) {
 	t.Helper()
 
@@ -531,3 +2999,11 @@ func setParams(t *testing.T, tt testInfo, c *gin.Context) {
 
 	c.Params = params
 }
+
+func setHeader(t *testing.T, tt testInfo, c *gin.Context) {
+	t.Helper()
+
+	for k, v := range tt.header {
+		c.Request.Header.Set(k, v)
+	}
+}
@@ -7,6 +7,7 @@ package controllers
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
@@ -16,10 +17,14 @@ import (
 
 type UserController struct {
 	Interactor *usecase.UserInteractor
+	Logger     *slog.Logger
 }
 
-func NewUserController(user repository.UserRepository) *UserController {
-	return &UserController{Interactor: usecase.NewUserInteractor(user)}
+func NewUserController(user repository.UserRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *UserController {
+	return &UserController{
+		Interactor: usecase.NewUserInteractor(user, activity, transactor, logger),
+		Logger:     logger,
+	}
 }
 
 // Get is the Handler for GET /user
@@ -30,14 +35,17 @@ func (controller *UserController) Get(c Context) {
 		return
 	}
 
-	user, err := controller.Interactor.Get(id)
+	user, err := controller.Interactor.Get(c, id)
 
 	if err != nil {
 		if errors.Is(err, entity.ErrRecordNotFound) {
 			errorToJSON(c, http.StatusNotFound, ErrUserNotFound)
 			return
 		}
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	if notModified(c, user.ETag()) {
 		return
 	}
 	c.JSON(http.StatusOK, user)
@@ -51,7 +59,7 @@ func (controller *UserController) Create(c Context) {
 		return
 	}
 
-	err = controller.Interactor.Create(user)
+	err = controller.Interactor.Create(c, user)
 
 	if err != nil {
 		var sqlerr *entity.ErrMySQL
@@ -65,9 +73,10 @@ func (controller *UserController) Create(c Context) {
 			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
 			return
 		}
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
 		return
 	}
+	c.Header("ETag", user.ETag())
 	c.JSON(http.StatusOK, user)
 }
 
@@ -85,7 +94,7 @@ func (controller *UserController) Update(c Context) {
 	}
 	user.SetID(id)
 
-	err = controller.Interactor.Update(user)
+	err = controller.Interactor.Update(c, user, getPreconditionFromHeader(c))
 
 	if err != nil {
 		if errors.Is(err, usecase.ErrInvalidUser) {
@@ -96,6 +105,10 @@ func (controller *UserController) Update(c Context) {
 			errorToJSON(c, http.StatusNotFound, ErrUserNotFound)
 			return
 		}
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
 		var sqlerr *entity.ErrMySQL
 		if errors.As(err, &sqlerr) {
 			if sqlerr.Number == 0x426 {
@@ -103,9 +116,10 @@ func (controller *UserController) Update(c Context) {
 				return
 			}
 		}
-		unexpectedErrorHandling(c, err)
+		unexpectedErrorHandling(c, controller.Logger, err)
 		return
 	}
+	c.Header("ETag", user.ETag())
 	c.JSON(http.StatusOK, user)
 }
 
@@ -117,7 +131,7 @@ func (controller *UserController) Delete(c Context) {
 		return
 	}
 
-	err = controller.Interactor.Delete(id)
+	err = controller.Interactor.Delete(c, id, getPreconditionFromHeader(c))
 
 	if err != nil {
 		// if errors.Is(err, usecase.ErrInvalidUser) {
@@ -128,12 +142,43 @@ func (controller *UserController) Delete(c Context) {
 			errorToJSON(c, http.StatusNotFound, ErrUserNotFound)
 			return
 		}
-		unexpectedErrorHandling(c, err)
+		if errors.Is(err, usecase.ErrPreconditionFailed) {
+			errorToJSON(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
 		return
 	}
 	c.JSON(http.StatusOK, nil)
 }
 
+// Activity is the Handler for GET /user/activity
+// 自分が行った操作を新しい順に取得する．クエリパラメータlimit(デフォルトは20件)とoffsetで取得する範囲を指定する
+func (controller *UserController) Activity(c Context) {
+	id, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	page, err := getPageFromQuery(c)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	activities, err := controller.Interactor.Activities(c, id, page)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPage) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, activities)
+}
+
 func getUserFromBody(c Context) (user *entity.User, err error) {
 	err = c.ShouldBindJSON(&user)
 	return
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
 )
 
 const (
@@ -23,12 +26,35 @@ type testInfo struct {
 	name                string            // test名
 	userid              string            // cookieに入れるuserid
 	params              map[string]string // context.Param
+	query               string            // URLのクエリ文字列
+	header              map[string]string // request header
 	body                string            // request body
 	prepareMockUserRepo func(user *mock_repository.MockUserRepository)
 	prepareMockTaskRepo func(task *mock_repository.MockTaskRepository)
-	wantErr             bool
-	wantCode            int
-	wantData            interface{}
+	prepareMockTagRepo  func(tag *mock_repository.MockTagRepository)
+	// 未設定の場合はProjectRepositoryへの呼び出しを許可しない
+	prepareMockProjectRepo func(project *mock_repository.MockProjectRepository)
+	// ログイン失敗の記録
+	prepareMockLoginAttemptRepo func(attempt *mock_repository.MockLoginAttemptRepository)
+	// TaskControllerでは未設定の場合はReminderがないものとする
+	prepareMockReminderRepo func(reminder *mock_repository.MockReminderRepository)
+	// TaskControllerとProjectControllerでは未設定の場合は共有がないものとする
+	prepareMockShareRepo func(share *mock_repository.MockShareRepository)
+	// 未設定の場合はどのWorkspaceにも参加していないものとする
+	prepareMockMembershipRepo func(membership *mock_repository.MockMembershipRepository)
+	prepareMockWorkspaceRepo  func(workspace *mock_repository.MockWorkspaceRepository)
+	prepareMockAssignmentRepo func(assignment *mock_repository.MockAssignmentRepository)
+	prepareMockCommentRepo    func(comment *mock_repository.MockCommentRepository)
+	prepareMockAttachmentRepo func(attachment *mock_repository.MockAttachmentRepository)
+	// 未設定の場合はActivityの記録のみを許可する
+	prepareMockActivityRepo func(activity *mock_repository.MockActivityRepository)
+	// TaskControllerでは未設定の場合はfnをそのまま実行する
+	prepareMockTransactor func(transactor *mock_repository.MockTransactor)
+	wantErr               bool
+	wantCode              int
+	wantData              interface{}
+	// 設定した場合はレスポンスのヘッダーも比べる
+	wantHeader map[string]string
 }
 
 func TestMain(m *testing.M) {
@@ -43,7 +69,7 @@ func TestUserController_Get(t *testing.T) {
 			name:   "正しくユーザが取得できる",
 			userid: uuidUA,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().FindByID(uuidUA).Return(&entity.User{
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(&entity.User{
 					ID:        entity.NewNullString(uuidUA),
 					Name:      entity.NewNullString("username"),
 					Password:  entity.NewToken("encrypted_password"),
@@ -56,11 +82,23 @@ func TestUserController_Get(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantData: entity.NewUser(uuidUA, "username", "", "example@example.com"),
 		},
+		{
+			name:   "If-None-Matchが現在のETagに一致するならStatusNotModified",
+			userid: uuidUA,
+			header: map[string]string{"If-None-Match": `W/"2", "3"`},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := storedUser()
+				u.Version = 3
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+			},
+			wantCode:   http.StatusNotModified,
+			wantHeader: map[string]string{"ETag": `"3"`},
+		},
 		{
 			name:   "DBにユーザがいないときはErrUserNotFound",
 			userid: uuidUA,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().FindByID(uuidUA).Return(&entity.User{}, entity.ErrRecordNotFound)
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(&entity.User{}, entity.ErrRecordNotFound)
 			},
 			wantErr:  true,
 			wantCode: http.StatusNotFound,
@@ -89,6 +127,7 @@ func TestUserController_Get(t *testing.T) {
 					Value: tt.userid,
 				})
 			}
+			setHeader(t, tt, context)
 
 			// モック,コントローラーの準備
 			ctrl, userController := prepareMockUserCtrl(t, tt)
@@ -112,8 +151,8 @@ func TestUserController_Create(t *testing.T) {
 				"email":"example@example.com"
 			}`,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Create(gomock.Any()).
-					DoAndReturn(func(user *entity.User) error {
+				user.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *entity.User) error {
 						user.SetID("any id")
 						user.CreatedAt = time.Unix(100, 0)
 						user.UpdatedAt = time.Unix(100, 0)
@@ -124,6 +163,76 @@ func TestUserController_Create(t *testing.T) {
 			wantCode: http.StatusOK,
 			wantData: entity.NewUser("any id", "username", "", "example@example.com"),
 		},
+		{
+			name: "Activityの記録に失敗すれば作成を取り消してStatusInternalServerError",
+			body: `{
+				"name":"username",
+				"password":"password",
+				"email":"example@example.com"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *entity.User) error {
+						user.SetID("any id")
+						return nil
+					})
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("activity unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 作成したUserもロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name: "タイムゾーンを指定してユーザを作成できる",
+			body: `{
+				"name":"username",
+				"password":"password",
+				"email":"example@example.com",
+				"time_zone":"Asia/Tokyo"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *entity.User) error {
+						user.SetID("any id")
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.User{
+				ID:       entity.NewNullString("any id"),
+				Name:     entity.NewNullString("username"),
+				Email:    entity.NewNullString("example@example.com"),
+				TimeZone: entity.NewNullString("Asia/Tokyo"),
+			},
+		},
+		{
+			name: "タイムゾーンがIANAのタイムゾーン名でないならStatusBadRequest",
+			body: `{
+				"name":"username",
+				"password":"password",
+				"email":"example@example.com",
+				"time_zone":"JST+9"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
 		{
 			name: "RequestにuserIDが含まれているならStatusBadRequest",
 			body: `{
@@ -204,7 +313,7 @@ func TestUserController_Create(t *testing.T) {
 				"email":"example@example.com"
 			}`,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Create(gomock.Any()).Return(
+				user.EXPECT().Create(gomock.Any(), gomock.Any()).Return(
 					entity.NewErrMySQL(0x426, "Duplicate entry 'example@example.com' for key 'users.email'"))
 			},
 			wantErr:  true,
@@ -246,8 +355,9 @@ func TestUserController_Update(t *testing.T) {
 				"email":"newexample@example.com"
 			}`,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Update(gomock.Any()).
-					DoAndReturn(func(user *entity.User) error {
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(storedUser(), nil)
+				user.EXPECT().Update(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, user *entity.User) error {
 						user.SetID(uuidUA)
 						user.Password = entity.NewToken("encrypted_newpassword")
 						user.CreatedAt = time.Unix(100, 0)
@@ -255,10 +365,43 @@ func TestUserController_Update(t *testing.T) {
 						return nil
 					})
 			},
+			// パスワードは値を記録せずに変わったことのみを記録する
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *entity.Activity) error {
+						want := entity.Changes{
+							{Field: "name", Before: "username", After: "newname"},
+							{Field: "email", Before: "example@example.com", After: "newexample@example.com"},
+							{Field: "password"},
+						}
+						if a.ActorID.String() != uuidUA || a.EntityType != entity.ActivityUser || a.Action != entity.ActionUpdate || cmp.Diff(want, a.Changes) != "" {
+							t.Errorf("Activity got = %v", a)
+						}
+						return nil
+					})
+			},
 			wantErr:  false,
 			wantCode: http.StatusOK,
 			wantData: entity.NewUser(uuidUA, "newname", "", "newexample@example.com"),
 		},
+		{
+			name:   "If-Matchが現在のETagと異なるならStatusPreconditionFailed",
+			userid: uuidUA,
+			header: map[string]string{"If-Match": `"2"`},
+			body: `{
+				"name":"newname",
+				"password":"newpassword",
+				"email":"newexample@example.com"
+			}`,
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := storedUser()
+				u.Version = 3
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusPreconditionFailed,
+			wantData: ErrPreconditionFailed.Error(),
+		},
 		{
 			name:   "nameがないならStatusBadRequest",
 			userid: uuidUA,
@@ -331,7 +474,7 @@ func TestUserController_Update(t *testing.T) {
 				"email":"example@example.com"
 			}`,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Update(gomock.Any()).Return(entity.ErrRecordNotFound)
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(nil, entity.ErrRecordNotFound)
 			},
 			wantErr:  true,
 			wantCode: http.StatusNotFound,
@@ -359,7 +502,8 @@ func TestUserController_Update(t *testing.T) {
 				"email":"example@example.com"
 			}`,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Update(gomock.Any()).Return(
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(storedUser(), nil)
+				user.EXPECT().Update(gomock.Any(), gomock.Any()).Return(
 					entity.NewErrMySQL(0x426, "Duplicate entry 'example@example.com' for key 'users.email'"))
 			},
 			wantErr:  true,
@@ -381,6 +525,7 @@ func TestUserController_Update(t *testing.T) {
 					Value: tt.userid,
 				})
 			}
+			setHeader(t, tt, context)
 
 			// モック,コントローラーの準備
 			ctrl, userController := prepareMockUserCtrl(t, tt)
@@ -400,7 +545,22 @@ func TestUserController_Delete(t *testing.T) {
 			name:   "正しくユーザーを削除できる",
 			userid: uuidUA,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Delete(uuidUA).Return(nil)
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(storedUser(), nil)
+				user.EXPECT().Delete(gomock.Any(), uuidUA, 0).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "If-Matchが現在のETagに一致すればバージョンを指定して削除する",
+			userid: uuidUA,
+			header: map[string]string{"If-Match": `"3"`},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				u := storedUser()
+				u.Version = 3
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(u, nil)
+				user.EXPECT().Delete(gomock.Any(), uuidUA, 3).Return(nil)
 			},
 			wantErr:  false,
 			wantCode: http.StatusOK,
@@ -410,7 +570,7 @@ func TestUserController_Delete(t *testing.T) {
 			name:   "DBにユーザがいないときはErrUserNotFound",
 			userid: uuidUA,
 			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
-				user.EXPECT().Delete(uuidUA).Return(entity.ErrRecordNotFound)
+				user.EXPECT().FindByID(gomock.Any(), uuidUA).Return(nil, entity.ErrRecordNotFound)
 			},
 			wantErr:  true,
 			wantCode: http.StatusNotFound,
@@ -439,6 +599,7 @@ func TestUserController_Delete(t *testing.T) {
 					Value: tt.userid,
 				})
 			}
+			setHeader(t, tt, context)
 
 			// モック,コントローラーの準備
 			ctrl, userController := prepareMockUserCtrl(t, tt)
@@ -451,6 +612,79 @@ func TestUserController_Delete(t *testing.T) {
 	}
 }
 
+func TestUserController_Activity(t *testing.T) {
+
+	activities := []*entity.Activity{{
+		ID:         entity.NewNullString(uuidSA),
+		ActorID:    entity.NewNullString(uuidUA),
+		Actor:      entity.NewUser(uuidUA, "username", "", "example@example.com"),
+		EntityType: entity.ActivityTask,
+		EntityID:   entity.NewNullString(uuidTA),
+		Action:     entity.ActionDelete,
+		Changes:    entity.Changes{{Field: "title", Before: "title", After: nil}},
+	}}
+
+	tests := []testInfo{
+		{
+			name:   "自分が行ったActivityを取得できる",
+			userid: uuidUA,
+			query:  "offset=1",
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().FindByActor(gomock.Any(), uuidUA, entity.Page{Limit: entity.DefaultPageLimit, Offset: 1}).Return(activities, int64(2), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.ActivityPage{Total: 2, Limit: entity.DefaultPageLimit, Offset: 1, Activities: activities},
+		},
+		{
+			name:   "offsetが負ならStatusBadRequest",
+			userid: uuidUA,
+			query:  "offset=-1",
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name: "Cookieが空ならStatusUnauthorized",
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+			},
+			wantErr:  true,
+			wantCode: http.StatusUnauthorized,
+			wantData: ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareUserTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/user/activity?"+tt.query, nil)
+			setCookieAndParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, userController := prepareMockUserCtrl(t, tt)
+			defer ctrl.Finish()
+
+			userController.Activity(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+// storedUser はdatabaseに保存したuuidUAのUserを作る．パスワードはハッシュ化している
+func storedUser() *entity.User {
+	user := entity.NewUser(uuidUA, "username", "password", "example@example.com")
+	user.EncryptPassword()
+	return user
+}
+
 func prepareUserTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
 	t.Helper()
 	t.Parallel()
@@ -468,7 +702,10 @@ func prepareMockUserCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, us
 	userRepo := mock_repository.NewMockUserRepository(ctrl)
 	tt.prepareMockUserRepo(userRepo)
 
-	userController = NewUserController(userRepo)
+	activityRepo := prepareMockActivityRepo(ctrl, tt)
+	transactor := prepareMockTransactor(ctrl, tt)
+
+	userController = NewUserController(userRepo, activityRepo, transactor, logging.Discard())
 	return
 }
 
@@ -478,8 +715,20 @@ func compareResult(t *testing.T, w *httptest.ResponseRecorder, tt testInfo) {
 	if w.Code != tt.wantCode {
 		t.Errorf("Code (-want +got) =\n- %d\n+ %d", tt.wantCode, w.Code)
 	}
+	for k, v := range tt.wantHeader {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("Header %s (-want +got) =\n- %s\n+ %s", k, v, got)
+		}
+	}
+	// 304はbodyを返さない
+	if tt.wantCode == http.StatusNotModified {
+		if w.Body.Len() != 0 {
+			t.Errorf("Body got = %s", w.Body.String())
+		}
+		return
+	}
 
-	var want, got map[string]interface{}
+	var want, got interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &got)
 	if err != nil {
 		t.Fatal(err)
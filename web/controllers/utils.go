@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"context"
 	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
 )
 
 // getUserIDFromCookie はcookieからuseridを取得する
@@ -20,9 +25,62 @@ func getTaskIDFromParam(c Context) (tid string, err error) {
 	return
 }
 
+// getPageFromQuery はクエリパラメータlimitとoffsetから一覧のどの範囲を取得するかを取得する
+func getPageFromQuery(c Context) (page entity.Page, err error) {
+	var limit, offset int
+	if l := c.Query("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			return
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil {
+			return
+		}
+	}
+	page = entity.NewPage(limit, offset)
+	return
+}
+
+// getPreconditionFromHeader はIf-Matchヘッダーから更新や削除を行う条件を取得する
+func getPreconditionFromHeader(c Context) entity.Precondition {
+	return entity.NewPrecondition(c.GetHeader("If-Match"))
+}
+
+// notModified はレスポンスにetagを付け，If-None-Matchに一致する場合は304を返してtrueを返す
+func notModified(c Context, etag string) bool {
+	c.Header("ETag", etag)
+	if entity.NewPrecondition(c.GetHeader("If-None-Match")).MatchWeak(etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// isDuplicateEntry はunique制約に違反したMySQLのエラー(ER_DUP_ENTRY)かどうかを返す
+func isDuplicateEntry(err error) bool {
+	var sqlerr *entity.ErrMySQL
+	return errors.As(err, &sqlerr) && sqlerr.Number == 0x426
+}
+
+// statusClientClosedRequest はクライアントがレスポンスを待たずに切断したことを示す(nginx互換)
+const statusClientClosedRequest = 499
+
 // unexpectedErrorHandling は予期せぬエラーが発生したときのエラーハンドリングを行う
-func unexpectedErrorHandling(c Context, _ error) {
-	// panic(err.Error())
-	errorToJSON(c, http.StatusInternalServerError, ErrInternalServerError)
+// クエリのタイムアウトとクライアントの切断はcontextのエラーとして返ってくる
+func unexpectedErrorHandling(c Context, logger *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.WarnContext(c, "timeout", slog.Any("error", err))
+		errorToJSON(c, http.StatusGatewayTimeout, ErrTimeout)
+	case errors.Is(err, context.Canceled):
+		logger.InfoContext(c, "request canceled", slog.Any("error", err))
+		errorToJSON(c, statusClientClosedRequest, ErrRequestCanceled)
+	default:
+		logger.ErrorContext(c, "unexpected error", slog.Any("error", err))
+		errorToJSON(c, http.StatusInternalServerError, ErrInternalServerError)
+	}
 	return
 }
@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+type WorkspaceController struct {
+	Interactor *usecase.WorkspaceInteractor
+	Logger     *slog.Logger
+}
+
+func NewWorkspaceController(workspace repository.WorkspaceRepository, membership repository.MembershipRepository, user repository.UserRepository, activity repository.ActivityRepository, transactor repository.Transactor, logger *slog.Logger) *WorkspaceController {
+	return &WorkspaceController{
+		Interactor: usecase.NewWorkspaceInteractor(workspace, membership, user, activity, transactor, logger),
+		Logger:     logger,
+	}
+}
+
+type workspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type memberRequest struct {
+	Email string               `json:"email"`
+	Role  entity.WorkspaceRole `json:"role"`
+}
+
+// Create is the Handler for POST /workspace
+func (controller *WorkspaceController) Create(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	var req workspaceRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	workspace := entity.NewWorkspace("", req.Name)
+	err = controller.Interactor.Create(c, workspace, uid)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidWorkspace) {
+			errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+			return
+		}
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+// List is the Handler for GET /workspace
+func (controller *WorkspaceController) List(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	workspaces, err := controller.Interactor.List(c, uid)
+
+	if err != nil {
+		unexpectedErrorHandling(c, controller.Logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// GetByID is the Handler for GET /workspace/:id
+func (controller *WorkspaceController) GetByID(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	workspace, err := controller.Interactor.GetByID(c, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Update is the Handler for PUT /workspace/:id
+func (controller *WorkspaceController) Update(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	var req workspaceRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	workspace := entity.NewWorkspace(id, req.Name)
+	err = controller.Interactor.Update(c, workspace, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Delete is the Handler for DELETE /workspace/:id
+func (controller *WorkspaceController) Delete(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.Delete(c, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+// Members is the Handler for GET /workspace/:id/members
+func (controller *WorkspaceController) Members(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	memberships, err := controller.Interactor.Members(c, id, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberships)
+}
+
+// AddMember is the Handler for POST /workspace/:id/members
+func (controller *WorkspaceController) AddMember(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	if id == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req memberRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	membership := &entity.Membership{
+		WorkspaceID: entity.NewNullString(id),
+		Role:        req.Role,
+	}
+	err = controller.Interactor.AddMember(c, membership, req.Email, uid)
+
+	if err != nil {
+		if errors.Is(err, usecase.ErrInviteeNotFound) {
+			errorToJSON(c, http.StatusNotFound, ErrInviteeNotFound)
+			return
+		}
+		if isDuplicateEntry(err) {
+			errorToJSON(c, http.StatusConflict, ErrDuplicatedMember)
+			return
+		}
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, membership)
+}
+
+// UpdateMember is the Handler for PUT /workspace/:id/members/:memberid
+func (controller *WorkspaceController) UpdateMember(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	memberid := c.Param("memberid")
+	if id == "" || memberid == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+	var req memberRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	membership := &entity.Membership{
+		ID:          entity.NewNullString(memberid),
+		WorkspaceID: entity.NewNullString(id),
+		Role:        req.Role,
+	}
+	err = controller.Interactor.UpdateMember(c, membership, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, membership)
+}
+
+// RemoveMember is the Handler for DELETE /workspace/:id/members/:memberid
+func (controller *WorkspaceController) RemoveMember(c Context) {
+	uid, err := getUserIDFromCookie(c)
+	if err != nil {
+		errorToJSON(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+	id := c.Param("id")
+	memberid := c.Param("memberid")
+	if id == "" || memberid == "" {
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+		return
+	}
+
+	err = controller.Interactor.RemoveMember(c, id, memberid, uid)
+
+	if err != nil {
+		controller.errorHandling(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nil)
+}
+
+// errorHandling はWorkspaceの操作で共通のエラーをレスポンスにする
+// Workspaceのメンバーでない場合はWorkspaceの存在を明かさないため404を返す
+func (controller *WorkspaceController) errorHandling(c Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWorkspace):
+		errorToJSON(c, http.StatusBadRequest, ErrBadRequest)
+	case errors.Is(err, usecase.ErrForbidden):
+		errorToJSON(c, http.StatusForbidden, ErrForbidden)
+	case errors.Is(err, usecase.ErrMemberNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrMemberNotFound)
+	case errors.Is(err, entity.ErrRecordNotFound):
+		errorToJSON(c, http.StatusNotFound, ErrWorkspaceNotFound)
+	default:
+		unexpectedErrorHandling(c, controller.Logger, err)
+	}
+}
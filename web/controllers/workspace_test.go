@@ -0,0 +1,531 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/mock_repository"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+const (
+	uuidWA = "5e1d9c3b-7a2f-4e6d-8c1b-0a9f8e7d6c5b"
+	uuidMA = "9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a"
+	uuidMB = "2a3b4c5d-6e7f-4a8b-9c0d-1e2f3a4b5c6d"
+)
+
+// member はuidのユーザーがuuidWAのWorkspaceにroleの役割で参加しているMembershipである
+func member(id, uid string, role entity.WorkspaceRole) *entity.Membership {
+	return &entity.Membership{
+		ID:          entity.NewNullString(id),
+		WorkspaceID: entity.NewNullString(uuidWA),
+		UserID:      entity.NewNullString(uid),
+		Role:        role,
+	}
+}
+
+func TestWorkspaceController_Create(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "Workspaceを作成すると作成したユーザーがownerになる",
+			userid: uuidUA,
+			body:   `{"name":"team"}`,
+			prepareMockWorkspaceRepo: func(workspace *mock_repository.MockWorkspaceRepository) {
+				workspace.EXPECT().Create(gomock.Any(), gomock.Any(), uuidUA).
+					DoAndReturn(func(_ context.Context, w *entity.Workspace, _ string) error {
+						w.ID = entity.NewNullString(uuidWA)
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Workspace{
+				ID:   entity.NewNullString(uuidWA),
+				Name: entity.NewNullString("team"),
+				Role: entity.WorkspaceOwner,
+			},
+		},
+		{
+			name:     "nameがないならStatusBadRequest",
+			userid:   uuidUA,
+			body:     `{}`,
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/workspace", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.Create(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestWorkspaceController_GetByID(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "参加しているWorkspaceを自分の役割とともに取得できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceGuest), nil)
+			},
+			prepareMockWorkspaceRepo: func(workspace *mock_repository.MockWorkspaceRepository) {
+				workspace.EXPECT().FindByID(gomock.Any(), uuidWA).Return(entity.NewWorkspace(uuidWA, "team"), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Workspace{
+				ID:   entity.NewNullString(uuidWA),
+				Name: entity.NewNullString("team"),
+				Role: entity.WorkspaceGuest,
+			},
+		},
+		{
+			name:   "参加していないWorkspaceはErrWorkspaceNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrWorkspaceNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("GET", "/workspace/"+uuidWA, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.GetByID(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestWorkspaceController_Delete(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "ownerはWorkspaceを削除でき，Workspaceから外したTaskをActivityに記録する",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+			},
+			prepareMockWorkspaceRepo: func(workspace *mock_repository.MockWorkspaceRepository) {
+				task := entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27")
+				task.WorkspaceID = entity.NewNullString(uuidWA)
+				workspace.EXPECT().Delete(gomock.Any(), uuidWA).Return([]*entity.Task{task}, nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				want := entity.Changes{{Field: "workspace_id", Before: uuidWA, After: nil}}
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, a *entity.Activity) error {
+						if a.EntityID.String() != uuidTA || a.ActorID.String() != uuidUA || a.Action != entity.ActionUpdate || cmp.Diff(want, a.Changes) != "" {
+							t.Errorf("Activity got = %v", a)
+						}
+						return nil
+					})
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "Activityの記録に失敗すれば削除を取り消してStatusInternalServerError",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+			},
+			prepareMockWorkspaceRepo: func(workspace *mock_repository.MockWorkspaceRepository) {
+				task := entity.NewTask(uuidTA, "title", "", uuidUB, "2020-12-27")
+				task.WorkspaceID = entity.NewNullString(uuidWA)
+				workspace.EXPECT().Delete(gomock.Any(), uuidWA).Return([]*entity.Task{task}, nil)
+			},
+			prepareMockActivityRepo: func(activity *mock_repository.MockActivityRepository) {
+				activity.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("activity unavailable"))
+			},
+			prepareMockTransactor: func(transactor *mock_repository.MockTransactor) {
+				transactor.EXPECT().Transaction(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+						// 削除もロールバックさせる
+						err := fn(ctx)
+						if err == nil {
+							t.Errorf("Transaction fn got nil")
+						}
+						return err
+					})
+			},
+			wantErr:  true,
+			wantCode: http.StatusInternalServerError,
+			wantData: ErrInternalServerError.Error(),
+		},
+		{
+			name:   "adminはWorkspaceを削除できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceAdmin), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/workspace/"+uuidWA, nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.Delete(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestWorkspaceController_AddMember(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "adminはemailのユーザーをmemberとして追加できる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			body:   `{"email":"guest@example.com","role":"member"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceAdmin), nil)
+				membership.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, m *entity.Membership) error {
+						m.ID = entity.NewNullString(uuidMB)
+						return nil
+					})
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "guest@example.com").Return(entity.NewUser(uuidUB, "guest", "", "guest@example.com"), nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: &entity.Membership{
+				ID:          entity.NewNullString(uuidMB),
+				WorkspaceID: entity.NewNullString(uuidWA),
+				Role:        entity.WorkspaceMember,
+				Email:       "guest@example.com",
+			},
+		},
+		{
+			name:   "adminはadminを追加できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			body:   `{"email":"guest@example.com","role":"admin"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceAdmin), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "memberはメンバーを追加できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			body:   `{"email":"guest@example.com","role":"guest"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceMember), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:     "ownerは追加できないのでStatusBadRequest",
+			userid:   uuidUA,
+			params:   map[string]string{"id": uuidWA},
+			body:     `{"email":"guest@example.com","role":"owner"}`,
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+			wantData: ErrBadRequest.Error(),
+		},
+		{
+			name:   "追加するユーザーが存在しないならErrInviteeNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA},
+			body:   `{"email":"nobody@example.com","role":"guest"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+			},
+			prepareMockUserRepo: func(user *mock_repository.MockUserRepository) {
+				user.EXPECT().FindByEmail(gomock.Any(), "nobody@example.com").Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrInviteeNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("POST", "/workspace/"+uuidWA+"/members", bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.AddMember(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestWorkspaceController_UpdateMember(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "ownerはmemberをadminにできる",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMB},
+			body:   `{"role":"admin"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMB).Return(member(uuidMB, uuidUB, entity.WorkspaceMember), nil)
+				membership.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockUserRepo: findUsers,
+			wantErr:             false,
+			wantCode:            http.StatusOK,
+			wantData: &entity.Membership{
+				ID:          entity.NewNullString(uuidMB),
+				WorkspaceID: entity.NewNullString(uuidWA),
+				Role:        entity.WorkspaceAdmin,
+				Email:       "guest@example.com",
+			},
+		},
+		{
+			name:   "adminは他のadminの役割を変更できないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMB},
+			body:   `{"role":"guest"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceAdmin), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMB).Return(member(uuidMB, uuidUB, entity.WorkspaceAdmin), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "メンバーが存在しないならErrMemberNotFound",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMB},
+			body:   `{"role":"guest"}`,
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMB).Return(nil, entity.ErrRecordNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+			wantData: ErrMemberNotFound.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("PUT", "/workspace/"+uuidWA+"/members/"+uuidMB, bytes.NewBufferString(tt.body))
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.UpdateMember(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func TestWorkspaceController_RemoveMember(t *testing.T) {
+
+	tests := []testInfo{
+		{
+			name:   "メンバーは自分でWorkspaceから抜けられる",
+			userid: uuidUB,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMB},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUB).Return(member(uuidMB, uuidUB, entity.WorkspaceGuest), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMB).Return(member(uuidMB, uuidUB, entity.WorkspaceGuest), nil)
+				membership.EXPECT().Delete(gomock.Any(), uuidWA, uuidMB).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			wantData: nil,
+		},
+		{
+			name:   "ownerは抜けられないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMA},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMA).Return(member(uuidMA, uuidUA, entity.WorkspaceOwner), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+		{
+			name:   "memberは他のメンバーを外せないのでStatusForbidden",
+			userid: uuidUA,
+			params: map[string]string{"id": uuidWA, "memberid": uuidMB},
+			prepareMockMembershipRepo: func(membership *mock_repository.MockMembershipRepository) {
+				membership.EXPECT().Find(gomock.Any(), uuidWA, uuidUA).Return(member(uuidMA, uuidUA, entity.WorkspaceMember), nil)
+				membership.EXPECT().FindByID(gomock.Any(), uuidWA, uuidMB).Return(member(uuidMB, uuidUB, entity.WorkspaceGuest), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+			wantData: ErrForbidden.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			context, w := prepareWorkspaceTT(t)
+
+			// httpRequest
+			context.Request, _ = http.NewRequest("DELETE", "/workspace/"+uuidWA+"/members/"+tt.params["memberid"], nil)
+			if tt.userid != "" {
+				context.Request.AddCookie(&http.Cookie{
+					Name:  "id",
+					Value: tt.userid,
+				})
+			}
+			setParams(t, tt, context)
+
+			// モック,コントローラーの準備
+			ctrl, workspaceController := prepareMockWorkspaceCtrl(t, tt)
+			defer ctrl.Finish()
+
+			workspaceController.RemoveMember(context)
+
+			compareResult(t, w, tt)
+		})
+	}
+}
+
+func prepareWorkspaceTT(t *testing.T) (context *gin.Context, w *httptest.ResponseRecorder) {
+	t.Helper()
+	t.Parallel()
+	w = httptest.NewRecorder()
+	context, _ = gin.CreateTestContext(w)
+
+	return
+}
+
+func prepareMockWorkspaceCtrl(t *testing.T, tt testInfo) (ctrl *gomock.Controller, workspaceController *WorkspaceController) {
+	t.Helper()
+
+	// モックの準備
+	ctrl = gomock.NewController(t)
+	workspaceRepo := mock_repository.NewMockWorkspaceRepository(ctrl)
+	if tt.prepareMockWorkspaceRepo != nil {
+		tt.prepareMockWorkspaceRepo(workspaceRepo)
+	}
+	membershipRepo := mock_repository.NewMockMembershipRepository(ctrl)
+	if tt.prepareMockMembershipRepo != nil {
+		tt.prepareMockMembershipRepo(membershipRepo)
+	}
+	userRepo := mock_repository.NewMockUserRepository(ctrl)
+	if tt.prepareMockUserRepo != nil {
+		tt.prepareMockUserRepo(userRepo)
+	}
+
+	activityRepo := prepareMockActivityRepo(ctrl, tt)
+	transactor := prepareMockTransactor(ctrl, tt)
+
+	workspaceController = NewWorkspaceController(workspaceRepo, membershipRepo, userRepo, activityRepo, transactor, logging.Discard())
+	return
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode("test")
+
+	engine := gin.New()
+	engine.Use(BodyLimit(4))
+	engine.POST("/", func(c *gin.Context) {
+		_, err := io.ReadAll(c.Request.Body)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "上限以下なら読める", body: "1234", wantCode: http.StatusOK},
+		{name: "上限を超えるとMaxBytesError", body: "12345", wantCode: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if w.Code != tt.wantCode {
+				t.Errorf("Code (-want +got) =\n- %d\n+ %d", tt.wantCode, w.Code)
+			}
+		})
+	}
+}
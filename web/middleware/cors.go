@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig はクロスオリジンのリクエストを許可する条件である
+type CORSConfig struct {
+	// AllowedOrigins は許可するオリジン．*はすべてのオリジンを許可する
+	AllowedOrigins []string
+	// AllowCredentials はcookieの送信を許可するか．*と組み合わせた場合はcookieを許可しない
+	AllowCredentials bool
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	// ExposedHeaders はブラウザのJavaScriptから読めるようにするレスポンスヘッダー
+	ExposedHeaders []string
+	// MaxAge はプリフライトリクエストの結果をキャッシュしてよい期間
+	MaxAge time.Duration
+}
+
+// CORS はCORSConfigで許可されたオリジンからのリクエストにCORSのヘッダーを付与する
+// プリフライトリクエストには204を返し，後続のハンドラーは呼ばない
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	origins := map[string]bool{}
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			allowAll = true
+		}
+		origins[o] = true
+	}
+	credentials := cfg.AllowCredentials && !allowAll
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		// オリジンによってレスポンスが変わるのでキャッシュで混ざらないようにする
+		c.Writer.Header().Add("Vary", "Origin")
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowAll && !origins[origin] {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// 同一オリジンのリクエストにもOriginが付くことがあるので，ヘッダーを付けずにそのまま処理する
+			c.Next()
+			return
+		}
+
+		if allowAll {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if credentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				c.Header("Access-Control-Allow-Headers", headers)
+			}
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode("test")
+
+	tests := []struct {
+		name            string
+		origins         []string
+		method          string
+		header          map[string]string
+		wantCode        int
+		wantAllowOrigin string
+		wantCredentials string
+		wantMaxAge      string
+	}{
+		{
+			name:            "許可されたオリジンのプリフライトには204を返す",
+			origins:         []string{"https://app.example.com"},
+			method:          http.MethodOptions,
+			header:          map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PUT"},
+			wantCode:        http.StatusNoContent,
+			wantAllowOrigin: "https://app.example.com",
+			wantCredentials: "true",
+			wantMaxAge:      "600",
+		},
+		{
+			name:     "許可されていないオリジンのプリフライトには403を返す",
+			origins:  []string{"https://app.example.com"},
+			method:   http.MethodOptions,
+			header:   map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": "PUT"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:            "許可されたオリジンのリクエストにはヘッダーを付与する",
+			origins:         []string{"https://app.example.com"},
+			method:          http.MethodGet,
+			header:          map[string]string{"Origin": "https://app.example.com"},
+			wantCode:        http.StatusOK,
+			wantAllowOrigin: "https://app.example.com",
+			wantCredentials: "true",
+		},
+		{
+			name:     "許可されていないオリジンのリクエストにはヘッダーを付与しない",
+			origins:  []string{"https://app.example.com"},
+			method:   http.MethodGet,
+			header:   map[string]string{"Origin": "https://evil.example.com"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:            "*の場合はcookieを許可しない",
+			origins:         []string{"*"},
+			method:          http.MethodGet,
+			header:          map[string]string{"Origin": "https://any.example.com"},
+			wantCode:        http.StatusOK,
+			wantAllowOrigin: "*",
+		},
+		{
+			name:     "Originがなければ何もしない",
+			origins:  []string{"https://app.example.com"},
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(CORS(CORSConfig{
+				AllowedOrigins:   tt.origins,
+				AllowCredentials: true,
+				AllowedMethods:   []string{"GET", "PUT"},
+				AllowedHeaders:   []string{"Content-Type"},
+				MaxAge:           10 * time.Minute,
+			}))
+			engine.GET("/user", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/user", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Code (-want +got) =\n- %d\n+ %d", tt.wantCode, w.Code)
+			}
+			got := map[string]string{
+				"Access-Control-Allow-Origin":      tt.wantAllowOrigin,
+				"Access-Control-Allow-Credentials": tt.wantCredentials,
+				"Access-Control-Max-Age":           tt.wantMaxAge,
+			}
+			for k, want := range got {
+				if w.Header().Get(k) != want {
+					t.Errorf("%s (-want +got) =\n- %s\n+ %s", k, want, w.Header().Get(k))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFConfig はdouble-submit cookie方式のCSRF対策の設定である
+type CSRFConfig struct {
+	// TokenCookie はトークンを保持するcookieの名前
+	TokenCookie string
+	// TokenHeader はクライアントがトークンを送るヘッダーの名前
+	TokenHeader string
+	// SessionCookie は認証に使うcookieの名前．このcookieがないリクエストは検証しない
+	SessionCookie string
+}
+
+// CSRF はcookieで認証された状態を変更するリクエストについて，
+// TokenHeaderの値がTokenCookieの値と一致しなければ403を返す
+// 認証はcookieのみで行うので，他のヘッダーの有無にかかわらずcookieがあれば検証する
+func CSRF(cfg CSRFConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if session, err := c.Cookie(cfg.SessionCookie); err != nil || session == "" {
+			c.Next()
+			return
+		}
+
+		token, err := c.Cookie(cfg.TokenCookie)
+		header := c.GetHeader(cfg.TokenHeader)
+		if err != nil || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(header)) != 1 {
+			abortWithError(c, http.StatusForbidden, "invalid csrf token")
+			return
+		}
+		c.Next()
+	}
+}
+
+// isSafeMethod は状態を変更しないメソッドかどうかを返す
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRF(t *testing.T) {
+	gin.SetMode("test")
+
+	tests := []struct {
+		name     string
+		method   string
+		cookies  map[string]string
+		header   map[string]string
+		wantCode int
+	}{
+		{
+			name:     "トークンが一致すれば通す",
+			method:   http.MethodPut,
+			cookies:  map[string]string{"id": "user", "csrf_token": "token"},
+			header:   map[string]string{"X-CSRF-Token": "token"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "トークンが一致しなければ403",
+			method:   http.MethodPut,
+			cookies:  map[string]string{"id": "user", "csrf_token": "token"},
+			header:   map[string]string{"X-CSRF-Token": "other"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "ヘッダーがなければ403",
+			method:   http.MethodDelete,
+			cookies:  map[string]string{"id": "user", "csrf_token": "token"},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "トークンのcookieがなければ403",
+			method:   http.MethodPost,
+			cookies:  map[string]string{"id": "user"},
+			header:   map[string]string{"X-CSRF-Token": ""},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "GETは検証しない",
+			method:   http.MethodGet,
+			cookies:  map[string]string{"id": "user"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "cookieで認証していなければ検証しない",
+			method:   http.MethodPost,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "Authorizationヘッダーを付けてもcookieで認証していれば検証する",
+			method:   http.MethodPost,
+			cookies:  map[string]string{"id": "user"},
+			header:   map[string]string{"Authorization": "Bearer abc"},
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(CSRF(CSRFConfig{
+				TokenCookie:   "csrf_token",
+				TokenHeader:   "X-CSRF-Token",
+				SessionCookie: "id",
+			}))
+			engine.Any("/user", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/user", nil)
+			for k, v := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: k, Value: v})
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Code (-want +got) =\n- %d\n+ %d", tt.wantCode, w.Code)
+			}
+		})
+	}
+}
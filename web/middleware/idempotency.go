@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/domain/repository"
+	"github.com/hiroyaonoe/todoapp-server/usecase"
+)
+
+const (
+	// IdempotencyHeader はリクエストを一度だけ処理させるためにクライアントが指定するヘッダーである
+	IdempotencyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader は保存したレスポンスを返したことを示すヘッダーである
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// statusClientClosedRequest はcontrollersがリクエストの中断を表すのに使うステータスコードである
+	statusClientClosedRequest = 499
+)
+
+// Idempotency はIdempotency-Keyを指定したリクエストのレスポンスをttlの間保存し，同じキーで再送されたリクエストには処理せずに保存したレスポンスを返す
+// 同じキーで異なるリクエストが送られた場合は422を，最初のリクエストを処理中の場合は409を返す
+// サーバーのエラーや中断したリクエストのレスポンスは保存せず，同じキーで再び処理できるようにする
+// 処理中のままleaseを過ぎたキーはサーバーが停止したものとして，同じキーのリクエストが引き継いで処理する
+func Idempotency(idempotency repository.IdempotencyRepository, ttl, lease time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	interactor := usecase.NewIdempotencyInteractor(idempotency, ttl, lease, logger)
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		// ログインしていないリクエストは別のクライアントと同じキーで衝突しないようにIPアドレスごとに区別する
+		scope, _ := c.Cookie("id")
+		if scope == "" {
+			scope = entity.AnonymousIdempotencyScope + c.ClientIP()
+		}
+		fingerprint := entity.NewFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+		record, replay, err := interactor.Begin(ctx, scope, key, fingerprint)
+		switch {
+		case err == nil:
+		case errors.Is(err, usecase.ErrInvalidIdempotencyKey):
+			abortWithError(c, http.StatusBadRequest, "invalid idempotency key")
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyMismatch):
+			abortWithError(c, http.StatusUnprocessableEntity, "idempotency key is already used for another request")
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyInUse):
+			c.Header("Retry-After", "1")
+			abortWithError(c, http.StatusConflict, "request with the same idempotency key is in progress")
+			return
+		default:
+			logger.ErrorContext(ctx, "idempotency store error", slog.Any("error", err))
+			abortWithError(c, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if replay {
+			c.Header(IdempotentReplayedHeader, "true")
+			if record.ETag != "" {
+				c.Header("ETag", record.ETag)
+			}
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		w := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		// ハンドラがpanicしてもキーが処理中のまま残らないように必ず保存か削除をする
+		defer func() {
+			// クライアントが切断していてもレスポンスは保存する
+			ctx := context.WithoutCancel(ctx)
+			status := w.Status()
+			r := recover()
+			var err error
+			if r != nil || status >= http.StatusInternalServerError || status == statusClientClosedRequest {
+				err = interactor.Release(ctx, record)
+			} else {
+				err = interactor.Complete(ctx, record, status, w.Header().Get("Content-Type"), w.Header().Get("ETag"), w.body.Bytes())
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to save idempotent response", slog.Int("status", status), slog.Any("error", err))
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// responseRecorder は保存するためにレスポンスのbodyを複製する
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,301 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+// memoryIdempotency はテスト用にメモリ上に記録を保持するIdempotencyRepositoryである
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[string]entity.IdempotencyKey
+	err     error
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{records: map[string]entity.IdempotencyKey{}}
+}
+
+func (m *memoryIdempotency) Reserve(_ context.Context, k *entity.IdempotencyKey, now time.Time) (*entity.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	if r, ok := m.records[k.UserID+"/"+k.Key]; ok && r.ExpiresAt.After(now) && (r.IsCompleted() || r.LockedUntil.After(now)) {
+		return &r, nil
+	}
+	m.records[k.UserID+"/"+k.Key] = *k
+	return nil, nil
+}
+
+func (m *memoryIdempotency) Complete(_ context.Context, k *entity.IdempotencyKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.records[k.UserID+"/"+k.Key]; !ok || r.IsCompleted() || !r.LockedUntil.Equal(k.LockedUntil) {
+		return entity.ErrRecordNotFound
+	}
+	m.records[k.UserID+"/"+k.Key] = *k
+	return nil
+}
+
+func (m *memoryIdempotency) Release(_ context.Context, k *entity.IdempotencyKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.records[k.UserID+"/"+k.Key]; ok && !r.IsCompleted() && r.LockedUntil.Equal(k.LockedUntil) {
+		delete(m.records, k.UserID+"/"+k.Key)
+	}
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode("test")
+
+	type request struct {
+		key    string
+		cookie string
+		ip     string
+		body   string
+	}
+	tests := []struct {
+		name         string
+		requests     []request
+		status       int
+		storeErr     error
+		wantCodes    []int
+		wantCalls    int
+		wantReplayed string
+	}{
+		{
+			name:         "同じキーで再送すると保存したレスポンスを返す",
+			requests:     []request{{key: "a", body: `{"title":"x"}`}, {key: "a", body: `{"title":"x"}`}},
+			status:       http.StatusOK,
+			wantCodes:    []int{http.StatusOK, http.StatusOK},
+			wantCalls:    1,
+			wantReplayed: "true",
+		},
+		{
+			name:      "同じキーで異なるbodyを送ると422を返す",
+			requests:  []request{{key: "a", body: `{"title":"x"}`}, {key: "a", body: `{"title":"y"}`}},
+			status:    http.StatusOK,
+			wantCodes: []int{http.StatusOK, http.StatusUnprocessableEntity},
+			wantCalls: 1,
+		},
+		{
+			name:      "ユーザーが異なれば同じキーでも別のリクエストとして処理する",
+			requests:  []request{{key: "a", cookie: "userA", body: `{}`}, {key: "a", cookie: "userB", body: `{}`}},
+			status:    http.StatusOK,
+			wantCodes: []int{http.StatusOK, http.StatusOK},
+			wantCalls: 2,
+		},
+		{
+			name:      "ログインしていなければIPアドレスが異なると同じキーでも別のリクエストとして処理する",
+			requests:  []request{{key: "a", ip: "192.0.2.1", body: `{}`}, {key: "a", ip: "192.0.2.2", body: `{}`}},
+			status:    http.StatusOK,
+			wantCodes: []int{http.StatusOK, http.StatusOK},
+			wantCalls: 2,
+		},
+		{
+			name:         "ログインしていなくても同じIPアドレスから同じキーで再送すると保存したレスポンスを返す",
+			requests:     []request{{key: "a", ip: "192.0.2.1", body: `{}`}, {key: "a", ip: "192.0.2.1", body: `{}`}},
+			status:       http.StatusOK,
+			wantCodes:    []int{http.StatusOK, http.StatusOK},
+			wantCalls:    1,
+			wantReplayed: "true",
+		},
+		{
+			name:      "キーがなければ毎回処理する",
+			requests:  []request{{body: `{}`}, {body: `{}`}},
+			status:    http.StatusOK,
+			wantCodes: []int{http.StatusOK, http.StatusOK},
+			wantCalls: 2,
+		},
+		{
+			name:      "サーバーのエラーは保存せずに再び処理する",
+			requests:  []request{{key: "a", body: `{}`}, {key: "a", body: `{}`}},
+			status:    http.StatusInternalServerError,
+			wantCodes: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantCalls: 2,
+		},
+		{
+			name:         "クライアントのエラーは保存して再送にも同じレスポンスを返す",
+			requests:     []request{{key: "a", body: `{}`}, {key: "a", body: `{}`}},
+			status:       http.StatusBadRequest,
+			wantCodes:    []int{http.StatusBadRequest, http.StatusBadRequest},
+			wantCalls:    1,
+			wantReplayed: "true",
+		},
+		{
+			name:      "長すぎるキーは400を返す",
+			requests:  []request{{key: strings.Repeat("a", entity.MaxIdempotencyKeyLength+1), body: `{}`}},
+			status:    http.StatusOK,
+			wantCodes: []int{http.StatusBadRequest},
+			wantCalls: 0,
+		},
+		{
+			name:      "storeのエラーでは処理せずに500を返す",
+			requests:  []request{{key: "a", body: `{}`}},
+			status:    http.StatusOK,
+			storeErr:  errors.New("store unavailable"),
+			wantCodes: []int{http.StatusInternalServerError},
+			wantCalls: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryIdempotency()
+			store.err = tt.storeErr
+			calls := 0
+			engine := gin.New()
+			engine.POST("/task", Idempotency(store, time.Hour, time.Minute, logging.Discard()), func(c *gin.Context) {
+				calls++
+				c.Header("ETag", `"1"`)
+				c.JSON(tt.status, gin.H{"calls": calls})
+			})
+
+			var w *httptest.ResponseRecorder
+			var first string
+			for i, r := range tt.requests {
+				w = httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(r.body))
+				if r.key != "" {
+					req.Header.Set(IdempotencyHeader, r.key)
+				}
+				if r.cookie != "" {
+					req.AddCookie(&http.Cookie{Name: "id", Value: r.cookie})
+				}
+				if r.ip != "" {
+					req.RemoteAddr = r.ip + ":1234"
+				}
+				engine.ServeHTTP(w, req)
+				if w.Code != tt.wantCodes[i] {
+					t.Errorf("Code #%d (-want +got) =\n- %d\n+ %d", i, tt.wantCodes[i], w.Code)
+				}
+				if i == 0 {
+					first = w.Body.String()
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Calls (-want +got) =\n- %d\n+ %d", tt.wantCalls, calls)
+			}
+			if got := w.Header().Get(IdempotentReplayedHeader); got != tt.wantReplayed {
+				t.Errorf("%s (-want +got) =\n- %s\n+ %s", IdempotentReplayedHeader, tt.wantReplayed, got)
+			}
+			if tt.wantReplayed != "" {
+				if got := w.Body.String(); got != first {
+					t.Errorf("Body (-want +got) =\n- %s\n+ %s", first, got)
+				}
+				if got := w.Header().Get("ETag"); got != `"1"` {
+					t.Errorf("ETag (-want +got) =\n- %s\n+ %s", `"1"`, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	gin.SetMode("test")
+
+	store := newMemoryIdempotency()
+	engine := gin.New()
+	engine.POST("/task", Idempotency(store, time.Hour, time.Minute, logging.Discard()), func(c *gin.Context) {
+		// 最初のリクエストの処理中に同じキーのリクエストが届いた場合
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyHeader, "a")
+		engine.ServeHTTP(w, req)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Code (-want +got) =\n- %d\n+ %d", http.StatusConflict, w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("Retry-After (-want +got) =\n- %s\n+ %s", "1", got)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`))
+	req.Header.Set(IdempotencyHeader, "a")
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Code (-want +got) =\n- %d\n+ %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestIdempotency_LockExpired(t *testing.T) {
+	gin.SetMode("test")
+
+	// 処理中のままサーバーが停止して残った記録
+	store := newMemoryIdempotency()
+	past := time.Now().Add(-time.Second)
+	fingerprint := entity.NewFingerprint(http.MethodPost, "/task", []byte(`{}`))
+	scope := entity.AnonymousIdempotencyScope + "192.0.2.1"
+	store.records[scope+"/a"] = *entity.NewIdempotencyKey(scope, "a", fingerprint, past, time.Now().Add(time.Hour))
+
+	calls := 0
+	engine := gin.New()
+	engine.POST("/task", Idempotency(store, time.Hour, time.Minute, logging.Discard()), func(c *gin.Context) {
+		calls++
+		c.Status(http.StatusCreated)
+	})
+
+	// ロックの期限が切れた記録は引き継いで処理し，再送には保存したレスポンスを返す
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyHeader, "a")
+		engine.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Errorf("Code #%d (-want +got) =\n- %d\n+ %d", i, http.StatusCreated, w.Code)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Calls (-want +got) =\n- %d\n+ %d", 1, calls)
+	}
+}
+
+func TestIdempotency_Panic(t *testing.T) {
+	gin.SetMode("test")
+
+	store := newMemoryIdempotency()
+	calls := 0
+	engine := gin.New()
+	engine.Use(Recovery(logging.Discard()))
+	engine.POST("/task", Idempotency(store, time.Hour, time.Minute, logging.Discard()), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("unexpected")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// panicしたリクエストのキーは削除され，同じキーで再び処理できる
+	wantCodes := []int{http.StatusInternalServerError, http.StatusOK}
+	for i, want := range wantCodes {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/task", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyHeader, "a")
+		engine.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Code #%d (-want +got) =\n- %d\n+ %d", i, want, w.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Calls (-want +got) =\n- %d\n+ %d", 2, calls)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+// RequestIDHeader はリクエストIDを受け渡すヘッダー
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength はクライアントから受け付けるリクエストIDの最大長
+const maxRequestIDLength = 128
+
+// RequestID はリクエストごとにIDを割り当て，contextとレスポンスヘッダーに設定する
+// クライアントがX-Request-IDを指定した場合はそれを引き継ぐ
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.New().String()
+		}
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// isValidRequestID はログに書き込んでも問題ない文字列かどうか判定する
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog はリクエストごとにアクセスログを出力する
+// クエリ文字列やボディは出力しない
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// Recovery はpanicをログに出力して500を返す
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err interface{}) {
+		logger.ErrorContext(c.Request.Context(), "panic recovered", slog.Any("error", err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+)
+
+// Rate はトークンバケットの設定である
+// 1秒あたりPerSecond個のトークンが補充され，最大Burst個まで貯められる
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// RateLimitStore はキーごとのトークンバケットを保持する
+// 複数のサーバーで制限を共有する場合はRedisなどで実装する
+type RateLimitStore interface {
+	// Take はkeyのバケットからトークンをひとつ取り出す
+	// 取り出せなかった場合はallowed=falseと次にトークンが補充されるまでの時間を返す
+	Take(ctx context.Context, key string, rate Rate) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// KeyFunc はリクエストから制限の単位となるキーを返す．空文字列の場合は制限しない
+type KeyFunc func(c *gin.Context) string
+
+// ByIP はクライアントのIPアドレスごとに制限する
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// UserFinder はByAccountがcookieのuseridのユーザーを確かめるのに使う．repository.UserRepositoryが満たす
+type UserFinder interface {
+	FindByID(ctx context.Context, id string) (user *entity.User, err error)
+}
+
+// ByAccount は存在するユーザーのuseridがcookieにある場合はそのユーザーごとに，そうでない場合はByIPと同じくIPアドレスごとに制限する
+// cookieは検証されないので，存在しないuseridに変えて新しいバケットを得られないようにする
+func ByAccount(users UserFinder) KeyFunc {
+	return func(c *gin.Context) string {
+		id, err := c.Cookie("id")
+		if err != nil || id == "" {
+			return ByIP(c)
+		}
+		if _, err := users.FindByID(c.Request.Context(), id); err != nil {
+			return ByIP(c)
+		}
+		return "account:" + id
+	}
+}
+
+// ByEmail はJSONのbodyのemailごとに制限する．IPアドレスを変えながら同じアカウントのパスワードを試させない
+// emailのないbodyは制限しない
+func ByEmail(c *gin.Context) string {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var req struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &req) != nil || req.Email == "" {
+		return ""
+	}
+	return "email:" + strings.ToLower(req.Email)
+}
+
+// RateLimit はkeyごとにトークンバケットでリクエストを制限する
+// 制限を超えた場合は429とRetry-Afterヘッダーを返す
+// prefixは同じstoreを複数の制限で共有するときにキーを区別するために使う
+func RateLimit(store RateLimitStore, rate Rate, prefix string, key KeyFunc, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		allowed, retryAfter, err := store.Take(c.Request.Context(), prefix+":"+k, rate)
+		if err != nil {
+			// storeの障害でサービス全体を止めないように制限せずに通す
+			logger.ErrorContext(c.Request.Context(), "rate limit store error", slog.Any("error", err))
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			abortWithError(c, http.StatusTooManyRequests, "too many requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortWithError はcontrollersのエラーレスポンスと同じ形式のJSONを返して処理を中断する
+func abortWithError(c *gin.Context, code int, msg string) {
+	c.AbortWithStatusJSON(code, gin.H{"code": code, "error": msg})
+}
+
+// MemoryStore はメモリ上にトークンバケットを保持するRateLimitStoreである
+// サーバーごとに独立した制限になる
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// Now はテストで時刻を固定するために差し替える
+	Now func() time.Time
+	// idleTTL の間使われなかったバケットは削除する
+	idleTTL   time.Duration
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryStore is the constructor of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		Now:     time.Now,
+		idleTTL: 10 * time.Minute,
+	}
+}
+
+// Take はkeyのバケットからトークンをひとつ取り出す
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	// 前回からの経過時間に応じてトークンを補充する
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(rate.Burst), b.tokens+elapsed*rate.PerSecond)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	if rate.PerSecond <= 0 {
+		return false, time.Duration(math.MaxInt64), nil
+	}
+	wait := (1 - b.tokens) / rate.PerSecond
+	return false, time.Duration(wait * float64(time.Second)), nil
+}
+
+// sweep は一定時間使われていないバケットを削除してメモリの増加を防ぐ
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idleTTL {
+		return
+	}
+	for k, b := range s.buckets {
+		if now.Sub(b.last) >= s.idleTTL {
+			delete(s.buckets, k)
+		}
+	}
+	s.lastSweep = now
+}
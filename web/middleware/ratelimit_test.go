@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/domain/entity"
+	"github.com/hiroyaonoe/todoapp-server/logging"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Unix(100, 0)
+	store := NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	rate := Rate{PerSecond: 1, Burst: 2}
+
+	// Burst個までは連続して取り出せる
+	for i := 0; i < 2; i++ {
+		allowed, _, err := store.Take(context.Background(), "a", rate)
+		if err != nil || !allowed {
+			t.Fatalf("Take #%d = %v, %v, want allowed", i, allowed, err)
+		}
+	}
+	allowed, retryAfter, _ := store.Take(context.Background(), "a", rate)
+	if allowed {
+		t.Fatal("Take after burst is allowed")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("RetryAfter (-want +got) =\n- %s\n+ %s", time.Second, retryAfter)
+	}
+
+	// 別のキーは独立している
+	allowed, _, _ = store.Take(context.Background(), "b", rate)
+	if !allowed {
+		t.Error("Take with another key is not allowed")
+	}
+
+	// 時間が経つとトークンが補充される
+	now = now.Add(time.Second)
+	allowed, _, _ = store.Take(context.Background(), "a", rate)
+	if !allowed {
+		t.Error("Take after refill is not allowed")
+	}
+}
+
+type errorStore struct{}
+
+func (errorStore) Take(context.Context, string, Rate) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode("test")
+
+	tests := []struct {
+		name           string
+		store          RateLimitStore
+		wantCodes      []int
+		wantRetryAfter string
+	}{
+		{
+			name:           "制限を超えたら429とRetry-Afterを返す",
+			store:          NewMemoryStore(),
+			wantCodes:      []int{http.StatusOK, http.StatusTooManyRequests},
+			wantRetryAfter: "2",
+		},
+		{
+			name:      "storeのエラーでは制限しない",
+			store:     errorStore{},
+			wantCodes: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(RateLimit(tt.store, Rate{PerSecond: 0.5, Burst: 1}, "test", ByIP, logging.Discard()))
+			engine.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			var w *httptest.ResponseRecorder
+			for i, want := range tt.wantCodes {
+				w = httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				engine.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Errorf("Code #%d (-want +got) =\n- %d\n+ %d", i, want, w.Code)
+				}
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After (-want +got) =\n- %s\n+ %s", tt.wantRetryAfter, got)
+			}
+		})
+	}
+}
+
+// users はテスト用にidsのユーザーのみが存在するUserFinderである
+type users []string
+
+func (u users) FindByID(_ context.Context, id string) (*entity.User, error) {
+	for _, uid := range u {
+		if uid == id {
+			return entity.NewUser(id, "username", "", "user@example.com"), nil
+		}
+	}
+	return nil, entity.ErrRecordNotFound
+}
+
+func TestByAccount(t *testing.T) {
+	gin.SetMode("test")
+
+	key := ByAccount(users{"abc"})
+	tests := []struct {
+		name   string
+		cookie string
+		want   string
+	}{
+		{
+			name: "cookieがなければIPアドレスごとに制限する",
+			want: "ip:192.0.2.1",
+		},
+		{
+			name:   "存在するユーザーならユーザーごとに制限する",
+			cookie: "abc",
+			want:   "account:abc",
+		},
+		{
+			name:   "存在しないユーザーのcookieならIPアドレスごとに制限する",
+			cookie: "forged",
+			want:   "ip:192.0.2.1",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.cookie != "" {
+				c.Request.AddCookie(&http.Cookie{Name: "id", Value: tt.cookie})
+			}
+			if got := key(c); got != tt.want {
+				t.Errorf("ByAccount (-want +got) =\n- %s\n+ %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestByEmail(t *testing.T) {
+	gin.SetMode("test")
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "emailごとに大文字と小文字を区別せずに制限する",
+			body: `{"email":"User@Example.com","password":"pass"}`,
+			want: "email:user@example.com",
+		},
+		{
+			name: "emailがなければ制限しない",
+			body: `{"password":"pass"}`,
+			want: "",
+		},
+		{
+			name: "不正なJSONなら制限しない",
+			body: `{`,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+			if got := ByEmail(c); got != tt.want {
+				t.Errorf("ByEmail (-want +got) =\n- %s\n+ %s", tt.want, got)
+			}
+			// ハンドラがbodyを読めるように戻す
+			body, _ := io.ReadAll(c.Request.Body)
+			if string(body) != tt.body {
+				t.Errorf("Body (-want +got) =\n- %s\n+ %s", tt.body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode("test")
+
+	engine := gin.New()
+	engine.Use(SecurityHeaders(SecurityHeadersConfig{HSTSMaxAge: time.Hour}))
+	engine.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := map[string]string{
+		"Strict-Transport-Security": "max-age=3600; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+		"X-Frame-Options":           "DENY",
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s (-want +got) =\n- %s\n+ %s", k, v, got)
+		}
+	}
+}
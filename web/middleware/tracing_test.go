@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/telemetry"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID = "00f067aa0ba902b7"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode("test")
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := telemetry.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(telemetry.NewPropagator())
+
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	engine.Use(Tracing())
+	engine.GET("/task/:id", func(c *gin.Context) {
+		// controllerからusecaseへgin.Contextを渡したときに子スパンになることを確認する
+		_, span := otel.Tracer("test").Start(c, "child")
+		span.End()
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/task/abc", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	engine.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("len(spans) = %d, want 2", len(spans))
+	}
+	child, server := spans[0], spans[1]
+
+	if server.Name != "GET /task/:id" {
+		t.Errorf("server span name = %s, want GET /task/:id", server.Name)
+	}
+	if server.SpanKind != trace.SpanKindServer {
+		t.Errorf("server span kind = %s, want server", server.SpanKind)
+	}
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("traceID = %s, want %s", got, traceID)
+	}
+	if got := server.Parent.SpanID().String(); got != parentSpanID {
+		t.Errorf("parent spanID = %s, want %s", got, parentSpanID)
+	}
+	if child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("child span is not a child of server span")
+	}
+	if w.Header().Get("traceparent") == "" {
+		t.Errorf("traceparent header is not set on response")
+	}
+}
@@ -5,56 +5,225 @@ Package web is Frameworks & Drivers.
 package web
 
 import (
+	"log/slog"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hiroyaonoe/todoapp-server/blobstore"
 	"github.com/hiroyaonoe/todoapp-server/config"
 	"github.com/hiroyaonoe/todoapp-server/database"
 	"github.com/hiroyaonoe/todoapp-server/web/controllers"
+	"github.com/hiroyaonoe/todoapp-server/web/middleware"
 )
 
 type Routing struct {
-	User *database.UserRepository
-	Task *database.TaskRepository
-	Gin  *gin.Engine
-	Port string
+	User         *database.UserRepository
+	Task         *database.TaskRepository
+	Tag          *database.TagRepository
+	Project      *database.ProjectRepository
+	Reminder     *database.ReminderRepository
+	Share        *database.ShareRepository
+	Workspace    *database.WorkspaceRepository
+	Membership   *database.MembershipRepository
+	Assignment   *database.AssignmentRepository
+	Comment      *database.CommentRepository
+	Attachment   *database.AttachmentRepository
+	Blob         blobstore.Store
+	Activity     *database.ActivityRepository
+	Transactor   *database.Transactor
+	LoginAttempt *database.LoginAttemptRepository
+	Idempotency  *database.IdempotencyRepository
+	Logger       *slog.Logger
+	Gin          *gin.Engine
+	Port         string
 }
 
-func NewRouting(user *database.UserRepository, task *database.TaskRepository) *Routing {
+func NewRouting(user *database.UserRepository, task *database.TaskRepository, tag *database.TagRepository, project *database.ProjectRepository, reminder *database.ReminderRepository, share *database.ShareRepository, workspace *database.WorkspaceRepository, membership *database.MembershipRepository, assignment *database.AssignmentRepository, comment *database.CommentRepository, attachment *database.AttachmentRepository, blob blobstore.Store, activity *database.ActivityRepository, transactor *database.Transactor, attempt *database.LoginAttemptRepository, idempotency *database.IdempotencyRepository, logger *slog.Logger) *Routing {
 	r := &Routing{
-		User: user,
-		Task: task,
-		Gin:  gin.Default(),
-		Port: config.Port(),
+		User:         user,
+		Task:         task,
+		Tag:          tag,
+		Project:      project,
+		Reminder:     reminder,
+		Share:        share,
+		Workspace:    workspace,
+		Membership:   membership,
+		Assignment:   assignment,
+		Comment:      comment,
+		Attachment:   attachment,
+		Blob:         blob,
+		Activity:     activity,
+		Transactor:   transactor,
+		LoginAttempt: attempt,
+		Idempotency:  idempotency,
+		Logger:       logger,
+		Gin:          gin.New(),
+		Port:         config.Port(),
+	}
+	// gin.ContextをそのままcontextとしてusecaseにわたすためRequest.Context()を参照させる
+	r.Gin.ContextWithFallback = true
+	// X-Forwarded-Forの詐称でIPアドレスごとの制限を回避されないようにする
+	if err := r.Gin.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		logger.Error("invalid TRUSTED_PROXIES", slog.Any("error", err))
 	}
 	r.setRouting()
 	return r
 }
 
 func (r *Routing) setRouting() {
-	taskController := controllers.NewTaskController(r.Task)
-	userController := controllers.NewUserController(r.User)
-	// authController := controllers.NewAuthController(r.DB)
+	taskController := controllers.NewTaskController(r.Task, r.Project, r.User, r.Reminder, r.Share, r.Membership, r.Assignment, r.Activity, r.Transactor, r.Logger)
+	reminderController := controllers.NewReminderController(r.Reminder, r.Task, r.User, r.Logger)
+	userController := controllers.NewUserController(r.User, r.Activity, r.Transactor, r.Logger)
+	tagController := controllers.NewTagController(r.Tag, r.Logger)
+	projectController := controllers.NewProjectController(r.Project, r.Task, r.Share, r.Membership, r.Activity, r.Transactor, r.Logger)
+	shareController := controllers.NewShareController(r.Share, r.Task, r.Project, r.User, r.Membership, r.Logger)
+	workspaceController := controllers.NewWorkspaceController(r.Workspace, r.Membership, r.User, r.Activity, r.Transactor, r.Logger)
+	commentController := controllers.NewCommentController(r.Comment, r.Task, r.Project, r.Share, r.Membership, r.Logger)
+	maxSize := config.AttachmentMaxSize()
+	attachmentController := controllers.NewAttachmentController(r.Attachment, r.Blob, r.Task, r.Project, r.Share, r.Membership, maxSize, config.AttachmentQuota(), r.Logger)
+	cookie := controllers.CookieConfig{
+		Path:     "/",
+		Domain:   config.CookieDomain(),
+		Secure:   config.CookieSecure(),
+		HttpOnly: config.CookieHTTPOnly(),
+		SameSite: config.CookieSameSite(),
+	}
+	if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+		r.Logger.Warn("cookie with SameSite=None is rejected by browsers unless COOKIE_SECURE=true")
+	}
+	authController := controllers.NewAuthController(r.User, r.LoginAttempt, cookie, r.Logger)
+	csrfController := controllers.NewCSRFController(cookie, r.Logger)
 
 	engine := r.Gin
 
 	// middleware
+	engine.Use(middleware.RequestID())
+	engine.Use(middleware.Tracing())
+	engine.Use(middleware.AccessLog(r.Logger))
+	engine.Use(middleware.Recovery(r.Logger))
+	engine.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		HSTSMaxAge:     config.HSTSMaxAge(),
+		FrameAncestors: config.FrameAncestors(),
+	}))
+	// プリフライトリクエストはレート制限の対象にしない
+	engine.Use(middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   config.CORSAllowedOrigins(),
+		AllowCredentials: config.CORSAllowCredentials(),
+		AllowedMethods:   config.CORSAllowedMethods(),
+		AllowedHeaders:   []string{"Content-Type", "Authorization", middleware.RequestIDHeader, controllers.CSRFTokenHeader, "If-Match", "If-None-Match", middleware.IdempotencyHeader},
+		ExposedHeaders:   []string{middleware.RequestIDHeader, "Retry-After", "ETag", middleware.IdempotentReplayedHeader},
+		MaxAge:           config.CORSMaxAge(),
+	}))
+
+	limiter := middleware.NewMemoryStore()
+	perSecond, burst := config.RateLimit()
+	rate := middleware.Rate{PerSecond: perSecond, Burst: burst}
+	perSecond, burst = config.LoginRateLimit()
+	loginRate := middleware.Rate{PerSecond: perSecond, Burst: burst}
+	engine.Use(middleware.RateLimit(limiter, rate, "all", middleware.ByIP, r.Logger))
+	// ユーザーが存在しないcookieのリクエストはIPアドレスごとに制限する．全体の制限とバケットを分ける
+	accountLimit := middleware.RateLimit(limiter, rate, "account", middleware.ByAccount(r.User), r.Logger)
+
+	engine.Use(middleware.CSRF(middleware.CSRFConfig{
+		TokenCookie:   controllers.CSRFTokenCookie,
+		TokenHeader:   controllers.CSRFTokenHeader,
+		SessionCookie: "id",
+	}))
 
-	// engine.POST("/login", func(c *gin.Context) { authController.Login(c) })
+	// 再送で重複して作成しないように，Idempotency-Keyを指定した作成のリクエストは一度だけ処理する
+	idempotency := middleware.Idempotency(r.Idempotency, config.IdempotencyTTL(), config.IdempotencyLockTimeout(), r.Logger)
 
 	v1 := engine.Group("/api/v1")
+	v1.GET("/csrf", func(c *gin.Context) { csrfController.Get(c) })
+	v1.POST("/login",
+		middleware.RateLimit(limiter, loginRate, "login", middleware.ByIP, r.Logger),
+		middleware.RateLimit(limiter, loginRate, "login", middleware.ByEmail, r.Logger),
+		func(c *gin.Context) { authController.Login(c) },
+	)
+
 	task := v1.Group("/task")
-	task.POST("", func(c *gin.Context) { taskController.Create(c) })
+	task.Use(accountLimit)
+	task.GET("", func(c *gin.Context) { taskController.List(c) })
+	task.POST("", idempotency, func(c *gin.Context) { taskController.Create(c) })
+	task.POST("/batch", idempotency, func(c *gin.Context) { taskController.Batch(c) })
+	task.GET("/search", func(c *gin.Context) { taskController.Search(c) })
+	task.GET("/overdue", func(c *gin.Context) { taskController.Overdue(c) })
+	task.GET("/today", func(c *gin.Context) { taskController.Today(c) })
+	task.GET("/upcoming", func(c *gin.Context) { taskController.Upcoming(c) })
+	task.GET("/assigned-to-me", func(c *gin.Context) { taskController.AssignedToMe(c) })
 	task.GET("/:id", func(c *gin.Context) { taskController.GetByID(c) })
 	task.PUT("/:id", func(c *gin.Context) { taskController.Update(c) })
 	task.DELETE("/:id", func(c *gin.Context) { taskController.Delete(c) })
-	// task.PUT("/:id/comp", func(c *gin.Context) { taskController.Switch(c) })
+	task.PUT("/:id/position", func(c *gin.Context) { taskController.Reorder(c) })
+	task.PUT("/:id/comp", func(c *gin.Context) { taskController.Complete(c) })
+	task.PUT("/:id/parent", func(c *gin.Context) { taskController.Move(c) })
+	task.PUT("/:id/assignee", func(c *gin.Context) { taskController.Assign(c) })
+	task.GET("/:id/history", func(c *gin.Context) { taskController.History(c) })
+	task.GET("/:id/subtask", func(c *gin.Context) { taskController.ListSubtasks(c) })
+	task.POST("/:id/subtask", func(c *gin.Context) { taskController.CreateSubtask(c) })
+	task.PUT("/:id/tag/:tagid", func(c *gin.Context) { tagController.Attach(c) })
+	task.DELETE("/:id/tag/:tagid", func(c *gin.Context) { tagController.Detach(c) })
+	task.GET("/:id/reminder", func(c *gin.Context) { reminderController.List(c) })
+	task.POST("/:id/reminder", func(c *gin.Context) { reminderController.Create(c) })
+	task.DELETE("/:id/reminder/:reminderid", func(c *gin.Context) { reminderController.Delete(c) })
+	task.GET("/:id/comments", func(c *gin.Context) { commentController.List(c) })
+	task.POST("/:id/comments", func(c *gin.Context) { commentController.Create(c) })
+	task.PUT("/:id/comments/:commentid", func(c *gin.Context) { commentController.Update(c) })
+	task.DELETE("/:id/comments/:commentid", func(c *gin.Context) { commentController.Delete(c) })
+	task.GET("/:id/attachments", func(c *gin.Context) { attachmentController.List(c) })
+	// multipartの境界やヘッダーの分だけ上限に余裕を持たせる
+	task.POST("/:id/attachments",
+		middleware.BodyLimit(maxSize+1<<20),
+		func(c *gin.Context) { attachmentController.Upload(c) },
+	)
+	task.GET("/:id/attachments/:attachmentid", func(c *gin.Context) { attachmentController.Download(c) })
+	task.DELETE("/:id/attachments/:attachmentid", func(c *gin.Context) { attachmentController.Delete(c) })
+	task.POST("/:id/share", func(c *gin.Context) { shareController.InviteTask(c) })
 	// task.GET("/date/:date", func(c *gin.Context) { taskController.GetbyDate(c) })
 	// task.GET("/date/from/:start/to/:end", func(c *gin.Context) { taskController.GetbyPeriod(c) })
 
+	tag := v1.Group("/tag")
+	tag.Use(accountLimit)
+	tag.GET("", func(c *gin.Context) { tagController.List(c) })
+	tag.POST("", func(c *gin.Context) { tagController.Create(c) })
+	tag.GET("/:id", func(c *gin.Context) { tagController.GetByID(c) })
+	tag.PUT("/:id", func(c *gin.Context) { tagController.Update(c) })
+	tag.DELETE("/:id", func(c *gin.Context) { tagController.Delete(c) })
+
+	project := v1.Group("/project")
+	project.Use(accountLimit)
+	project.GET("", func(c *gin.Context) { projectController.List(c) })
+	project.POST("", func(c *gin.Context) { projectController.Create(c) })
+	project.GET("/:id", func(c *gin.Context) { projectController.GetByID(c) })
+	project.PUT("/:id", func(c *gin.Context) { projectController.Update(c) })
+	project.DELETE("/:id", func(c *gin.Context) { projectController.Delete(c) })
+	project.POST("/:id/share", func(c *gin.Context) { shareController.InviteProject(c) })
+
+	share := v1.Group("/share")
+	share.Use(accountLimit)
+	share.GET("", func(c *gin.Context) { shareController.List(c) })
+	share.POST("/:id/accept", func(c *gin.Context) { shareController.Accept(c) })
+	share.DELETE("/:id", func(c *gin.Context) { shareController.Delete(c) })
+
+	workspace := v1.Group("/workspace")
+	workspace.Use(accountLimit)
+	workspace.GET("", func(c *gin.Context) { workspaceController.List(c) })
+	workspace.POST("", func(c *gin.Context) { workspaceController.Create(c) })
+	workspace.GET("/:id", func(c *gin.Context) { workspaceController.GetByID(c) })
+	workspace.PUT("/:id", func(c *gin.Context) { workspaceController.Update(c) })
+	workspace.DELETE("/:id", func(c *gin.Context) { workspaceController.Delete(c) })
+	workspace.GET("/:id/members", func(c *gin.Context) { workspaceController.Members(c) })
+	workspace.POST("/:id/members", func(c *gin.Context) { workspaceController.AddMember(c) })
+	workspace.PUT("/:id/members/:memberid", func(c *gin.Context) { workspaceController.UpdateMember(c) })
+	workspace.DELETE("/:id/members/:memberid", func(c *gin.Context) { workspaceController.RemoveMember(c) })
+
 	user := v1.Group("/user")
+	user.Use(accountLimit)
 	user.GET("", func(c *gin.Context) { userController.Get(c) })
-	user.POST("", func(c *gin.Context) { userController.Create(c) })
+	user.POST("", idempotency, func(c *gin.Context) { userController.Create(c) })
 	user.PUT("", func(c *gin.Context) { userController.Update(c) })
 	user.DELETE("", func(c *gin.Context) { userController.Delete(c) })
+	user.GET("/activity", func(c *gin.Context) { userController.Activity(c) })
 
 }
 